@@ -0,0 +1,264 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package awslookup implements an envconfig.Lookuper backed by AWS Systems
+// Manager Parameter Store and Secrets Manager, so a specification can be
+// filled from parameters named /{prefix}/{key}.
+//
+// The package does not depend on the AWS SDK. A ParameterStore and a
+// SecretStore are a few lines over the clients of aws-sdk-go-v2; see the
+// examples in the README. With parameters /myapp/prod/DB_HOST and
+// /myapp/prod/DB_PORT:
+//
+//	l := &awslookup.Lookuper{
+//		Prefix:     "myapp",
+//		Path:       "/myapp/prod",
+//		Parameters: params,
+//		Secrets:    secrets,
+//		SecretKeys: map[string]string{"MYAPP_DB_PASSWORD": "prod/db#password"},
+//	}
+//	err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Lookuper: l})
+package awslookup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long fetched values are cached when Lookuper.TTL is zero.
+const DefaultTTL = 5 * time.Minute
+
+// ParameterStore reads the parameters under a path of Parameter Store,
+// recursively and with SecureString values decrypted, by full name.
+type ParameterStore interface {
+	GetParametersByPath(ctx context.Context, path string) (map[string]string, error)
+}
+
+// SecretStore reads the current value of a secret of Secrets Manager by
+// name or ARN.
+type SecretStore interface {
+	GetSecretValue(ctx context.Context, id string) (string, error)
+}
+
+// ParameterStoreFunc adapts an ordinary function to a ParameterStore.
+type ParameterStoreFunc func(ctx context.Context, path string) (map[string]string, error)
+
+// GetParametersByPath implements ParameterStore.
+func (f ParameterStoreFunc) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+	return f(ctx, path)
+}
+
+// SecretStoreFunc adapts an ordinary function to a SecretStore.
+type SecretStoreFunc func(ctx context.Context, id string) (string, error)
+
+// GetSecretValue implements SecretStore.
+func (f SecretStoreFunc) GetSecretValue(ctx context.Context, id string) (string, error) {
+	return f(ctx, id)
+}
+
+// Lookuper answers variables from Parameter Store and Secrets Manager. All
+// the parameters under Path are fetched with one call on the first lookup
+// and cached for TTL, as are secrets. It implements envconfig.ErrorLookuper,
+// so processing fails when a value cannot be fetched rather than fall back
+// to the default of the variable; Err returns the last failure.
+type Lookuper struct {
+	// Prefix is the prefix of the specification, e.g. "myapp". It is
+	// stripped from keys to name parameters: MYAPP_DB_HOST is read from
+	// {Path}/DB_HOST.
+	Prefix string
+	// Path is the parameter path, e.g. "/myapp/prod"; "/" plus the
+	// lowercase prefix is used when empty.
+	Path string
+	// Name, when not nil, replaces the mapping of keys to parameter names
+	// under Path, e.g. to lowercase them.
+	Name func(key string) string
+
+	// Parameters, when not nil, is the Parameter Store to read.
+	Parameters ParameterStore
+
+	// Secrets, when not nil, is the Secrets Manager to read the variables
+	// of SecretKeys from. SecretKeys maps keys to secret ids; an id
+	// followed by #field reads a field of a secret that holds a JSON
+	// object, e.g. "prod/db#password".
+	Secrets    SecretStore
+	SecretKeys map[string]string
+
+	// TTL is how long fetched values are cached; DefaultTTL is used when
+	// zero.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	params  map[string]string
+	fetched time.Time
+	secrets map[string]cachedSecret
+	err     error
+}
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+}
+
+// Lookup implements envconfig.Lookuper.
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	return l.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements envconfig.ContextLookuper. A value that cannot be
+// fetched is reported as unset.
+func (l *Lookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	value, ok, _ := l.LookupErr(ctx, key)
+	return value, ok
+}
+
+// LookupErr implements envconfig.ErrorLookuper.
+func (l *Lookuper) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if id, ok := l.SecretKeys[key]; ok && l.Secrets != nil {
+		return l.secret(ctx, id)
+	}
+	if l.Parameters == nil {
+		return "", false, nil
+	}
+	if err := l.load(ctx); err != nil {
+		return "", false, err
+	}
+	value, ok := l.params[l.path()+"/"+l.name(key)]
+	return value, ok, nil
+}
+
+// Keys implements envconfig.KeyLister, listing the keys of the parameters
+// under Path and of SecretKeys. Parameters are only listed when Name is nil.
+func (l *Lookuper) Keys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var keys []string
+	for key := range l.SecretKeys {
+		keys = append(keys, key)
+	}
+	if l.Parameters == nil || l.Name != nil || l.load(context.Background()) != nil {
+		return keys
+	}
+	prefix := l.path() + "/"
+	for name := range l.params {
+		if rest := strings.TrimPrefix(name, prefix); rest != name && !strings.Contains(rest, "/") {
+			keys = append(keys, l.keyPrefix()+rest)
+		}
+	}
+	return keys
+}
+
+// Err returns the error of the last fetch that failed, or nil when the last
+// fetch of each value succeeded.
+func (l *Lookuper) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Invalidate drops the cached values, so the next lookup fetches them again.
+func (l *Lookuper) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.params, l.secrets = nil, nil
+}
+
+// String names the Lookuper in envconfig.Options.OnLookup reports.
+func (l *Lookuper) String() string {
+	return "aws:" + l.path()
+}
+
+func (l *Lookuper) ttl() time.Duration {
+	if l.TTL > 0 {
+		return l.TTL
+	}
+	return DefaultTTL
+}
+
+func (l *Lookuper) path() string {
+	if l.Path == "" {
+		return "/" + strings.ToLower(l.Prefix)
+	}
+	return strings.TrimSuffix(l.Path, "/")
+}
+
+func (l *Lookuper) keyPrefix() string {
+	if l.Prefix == "" {
+		return ""
+	}
+	return strings.ToUpper(l.Prefix) + "_"
+}
+
+func (l *Lookuper) name(key string) string {
+	if l.Name != nil {
+		return l.Name(key)
+	}
+	if p := l.keyPrefix(); len(key) > len(p) && strings.EqualFold(key[:len(p)], p) {
+		return key[len(p):]
+	}
+	return key
+}
+
+// load fetches the parameters under the path unless they are cached. l.mu
+// must be held.
+func (l *Lookuper) load(ctx context.Context) error {
+	if l.params != nil && time.Since(l.fetched) < l.ttl() {
+		return nil
+	}
+	params, err := l.Parameters.GetParametersByPath(ctx, l.path())
+	if err != nil {
+		l.err = fmt.Errorf("awslookup: reading parameters under %s: %v", l.path(), err)
+		return l.err
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	l.params, l.fetched, l.err = params, time.Now(), nil
+	return nil
+}
+
+// secret returns the value of the secret id, or of a field of it, fetching
+// the secret unless it is cached. l.mu must be held.
+func (l *Lookuper) secret(ctx context.Context, id string) (string, bool, error) {
+	field := ""
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		id, field = id[:i], id[i+1:]
+	}
+	c, ok := l.secrets[id]
+	if !ok || time.Since(c.fetched) >= l.ttl() {
+		value, err := l.Secrets.GetSecretValue(ctx, id)
+		if err != nil {
+			l.err = fmt.Errorf("awslookup: reading secret %s: %v", id, err)
+			return "", false, l.err
+		}
+		c = cachedSecret{value: value, fetched: time.Now()}
+		if l.secrets == nil {
+			l.secrets = make(map[string]cachedSecret)
+		}
+		l.secrets[id], l.err = c, nil
+	}
+	if field == "" {
+		return c.value, true, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(c.value), &fields); err != nil {
+		l.err = fmt.Errorf("awslookup: secret %s is not a JSON object", id)
+		return "", false, l.err
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", false, nil
+	}
+	if s, isString := v.(string); isString {
+		return s, true, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), true, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package awslookup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestLookuper(t *testing.T) {
+	var paramCalls, secretCalls int
+	params := ParameterStoreFunc(func(ctx context.Context, path string) (map[string]string, error) {
+		paramCalls++
+		if path != "/myapp/prod" {
+			t.Errorf("unexpected path %s", path)
+		}
+		return map[string]string{
+			"/myapp/prod/DB_HOST":       "db.internal",
+			"/myapp/prod/DB_PORT":       "5432",
+			"/myapp/prod/BACKENDS_MAIN": "http://main",
+			"/myapp/prod/nested/OTHER":  "x",
+		}, nil
+	})
+	secrets := SecretStoreFunc(func(ctx context.Context, id string) (string, error) {
+		secretCalls++
+		if id != "prod/db" {
+			return "", errors.New("ResourceNotFoundException")
+		}
+		return `{"password":"hunter2","port":5432}`, nil
+	})
+	l := &Lookuper{
+		Prefix:     "myapp",
+		Path:       "/myapp/prod/",
+		Parameters: params,
+		Secrets:    secrets,
+		SecretKeys: map[string]string{"MYAPP_DB_PASSWORD": "prod/db#password", "MYAPP_API_TOKEN": "prod/api"},
+	}
+
+	var s struct {
+		DB struct {
+			Host     string
+			Port     int
+			Password string
+		}
+		Backends map[string]string
+	}
+	for i := 0; i < 2; i++ {
+		if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Lookuper: l}); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if s.DB.Host != "db.internal" || s.DB.Port != 5432 || s.DB.Password != "hunter2" {
+		t.Errorf("unexpected values %+v", s)
+	}
+	if paramCalls != 1 || secretCalls != 1 {
+		t.Errorf("expected values to be cached, got %d parameter and %d secret reads", paramCalls, secretCalls)
+	}
+
+	keys := l.Keys()
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "MYAPP_API_TOKEN,MYAPP_BACKENDS_MAIN,MYAPP_DB_HOST,MYAPP_DB_PASSWORD,MYAPP_DB_PORT" {
+		t.Errorf("unexpected keys %s", got)
+	}
+
+	if _, ok := l.Lookup("MYAPP_API_TOKEN"); ok || l.Err() == nil || !strings.Contains(l.Err().Error(), "prod/api") {
+		t.Errorf("expected a failed secret read to be unset and reported, got %v", l.Err())
+	}
+	var withDefault struct {
+		APIToken string `envconfig:"API_TOKEN" default:"dev"`
+	}
+	err := envconfig.ProcessWithOptions("myapp", &withDefault, envconfig.Options{Lookuper: l})
+	if err == nil || !strings.Contains(err.Error(), "prod/api") || withDefault.APIToken != "" {
+		t.Errorf("expected the failed secret read to fail processing, got %v and %q", err, withDefault.APIToken)
+	}
+
+	l.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	l.Lookup("MYAPP_DB_HOST")
+	if paramCalls != 2 {
+		t.Errorf("expected expired parameters to be read again, got %d reads", paramCalls)
+	}
+}
+
+func TestName(t *testing.T) {
+	var paths []string
+	l := &Lookuper{
+		Prefix: "myapp",
+		Name:   strings.ToLower,
+		Parameters: ParameterStoreFunc(func(ctx context.Context, path string) (map[string]string, error) {
+			paths = append(paths, path)
+			return map[string]string{"/myapp/myapp_host": "h"}, nil
+		}),
+	}
+	if v, ok := l.Lookup("MYAPP_HOST"); !ok || v != "h" {
+		t.Errorf("expected h, got %q", v)
+	}
+	if len(paths) != 1 || paths[0] != "/myapp" {
+		t.Errorf("expected the default path, got %v", paths)
+	}
+}
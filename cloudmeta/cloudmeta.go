@@ -0,0 +1,290 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package cloudmeta implements an envconfig.Lookuper backed by the instance
+// metadata service of EC2 (IMDSv2), Google Compute Engine or Azure.
+//
+// Only the keys listed in Lookuper.Keys are answered from metadata, so it is
+// usually combined with the environment:
+//
+//	meta := &cloudmeta.Lookuper{
+//		Provider: cloudmeta.EC2,
+//		Keys: map[string]cloudmeta.Attribute{
+//			"MYAPP_REGION": cloudmeta.Region,
+//			"MYAPP_ZONE":   cloudmeta.Zone,
+//		},
+//	}
+//	err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{
+//		Lookuper: envconfig.MultiLookuper(envconfig.OSLookuper(), meta),
+//	})
+package cloudmeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider selects the metadata service to query.
+type Provider string
+
+// Supported metadata services.
+const (
+	EC2   Provider = "ec2"
+	GCE   Provider = "gce"
+	Azure Provider = "azure"
+)
+
+// Attribute is a piece of instance metadata.
+type Attribute string
+
+// Attributes available from every provider.
+const (
+	Region       Attribute = "region"
+	Zone         Attribute = "zone"
+	InstanceID   Attribute = "instance-id"
+	InstanceType Attribute = "instance-type"
+	Hostname     Attribute = "hostname"
+	PrivateIP    Attribute = "private-ip"
+)
+
+// DefaultTimeout bounds each request to the metadata service.
+const DefaultTimeout = 2 * time.Second
+
+var defaultEndpoints = map[Provider]string{
+	EC2:   "http://169.254.169.254",
+	GCE:   "http://metadata.google.internal",
+	Azure: "http://169.254.169.254",
+}
+
+var paths = map[Provider]map[Attribute]string{
+	EC2: {
+		Region:       "/latest/meta-data/placement/region",
+		Zone:         "/latest/meta-data/placement/availability-zone",
+		InstanceID:   "/latest/meta-data/instance-id",
+		InstanceType: "/latest/meta-data/instance-type",
+		Hostname:     "/latest/meta-data/local-hostname",
+		PrivateIP:    "/latest/meta-data/local-ipv4",
+	},
+	GCE: {
+		Region:       "/computeMetadata/v1/instance/zone",
+		Zone:         "/computeMetadata/v1/instance/zone",
+		InstanceID:   "/computeMetadata/v1/instance/id",
+		InstanceType: "/computeMetadata/v1/instance/machine-type",
+		Hostname:     "/computeMetadata/v1/instance/hostname",
+		PrivateIP:    "/computeMetadata/v1/instance/network-interfaces/0/ip",
+	},
+	Azure: {
+		Region:       "/metadata/instance/compute/location",
+		Zone:         "/metadata/instance/compute/zone",
+		InstanceID:   "/metadata/instance/compute/vmId",
+		InstanceType: "/metadata/instance/compute/vmSize",
+		Hostname:     "/metadata/instance/compute/name",
+		PrivateIP:    "/metadata/instance/network/interface/0/ipv4/ipAddress/0/privateIpAddress",
+	},
+}
+
+// Lookuper answers lookups of the keys in Keys from instance metadata.
+// Values are cached for the life of the Lookuper, so each attribute is
+// normally fetched once; failures are not cached, so a lookup after one
+// queries the service again. Lookups of different keys run concurrently.
+type Lookuper struct {
+	// Provider is the metadata service to query.
+	Provider Provider
+	// Keys maps variable names to the attribute that provides their value.
+	Keys map[string]Attribute
+	// Endpoint overrides the base URL of the metadata service.
+	Endpoint string
+	// Timeout bounds each request; DefaultTimeout is used when zero.
+	Timeout time.Duration
+	// Client is used for requests. When nil, a client that ignores
+	// HTTP_PROXY and the like is used, as the metadata service is only
+	// reachable directly.
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[Attribute]string
+	token string
+	// tokenExpires is when the IMDSv2 token stops being used.
+	tokenExpires time.Time
+}
+
+// errNotFound is returned by do for 404 Not Found.
+var errNotFound = errors.New("cloudmeta: not found")
+
+// metadataClient sends requests to the metadata service when Lookuper.Client
+// is nil.
+var metadataClient = &http.Client{Transport: &http.Transport{Proxy: nil}}
+
+// tokenTTL is the lifetime requested for IMDSv2 tokens; a token is replaced
+// tokenMargin before it expires.
+const (
+	tokenTTL    = 6 * time.Hour
+	tokenMargin = time.Minute
+)
+
+// Lookup implements envconfig.Lookuper.
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	return l.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements envconfig.ContextLookuper. Failed lookups are
+// reported as unset; LookupErr returns their errors.
+func (l *Lookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	value, ok, _ := l.LookupErr(ctx, key)
+	return value, ok
+}
+
+// LookupErr implements envconfig.ErrorLookuper, so a key whose attribute
+// cannot be fetched fails processing instead of falling back to its default.
+// Keys not in Keys, and attributes the service answers with 404 Not Found,
+// are unset.
+func (l *Lookuper) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	attr, ok := l.Keys[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	l.mu.Lock()
+	value, ok := l.cache[attr]
+	l.mu.Unlock()
+	if ok {
+		return value, true, nil
+	}
+	value, err := l.fetch(ctx, attr)
+	if errors.Is(err, errNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cache == nil {
+		l.cache = make(map[Attribute]string)
+	}
+	l.cache[attr] = value
+	return value, true, nil
+}
+
+// String names the Lookuper in envconfig.Options.OnLookup reports.
+func (l *Lookuper) String() string {
+	return "cloudmeta:" + string(l.Provider)
+}
+
+func (l *Lookuper) fetch(ctx context.Context, attr Attribute) (string, error) {
+	path, ok := paths[l.Provider][attr]
+	if !ok {
+		return "", fmt.Errorf("cloudmeta: %s has no attribute %s", l.Provider, attr)
+	}
+	endpoint := l.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoints[l.Provider]
+	}
+
+	header := http.Header{}
+	switch l.Provider {
+	case EC2:
+		token, err := l.ec2Token(ctx, endpoint)
+		if err != nil {
+			return "", err
+		}
+		header.Set("X-Aws-Ec2-Metadata-Token", token)
+	case GCE:
+		header.Set("Metadata-Flavor", "Google")
+	case Azure:
+		header.Set("Metadata", "true")
+		path += "?api-version=2021-02-01&format=text"
+	}
+
+	value, err := l.do(ctx, http.MethodGet, endpoint+path, header)
+	if err != nil {
+		return "", err
+	}
+	if l.Provider == GCE {
+		value = gceValue(attr, value)
+	}
+	return value, nil
+}
+
+// ec2Token returns the current IMDSv2 token, requesting a new one when there
+// is none or it is about to expire. l.mu is not held during the request.
+func (l *Lookuper) ec2Token(ctx context.Context, endpoint string) (string, error) {
+	l.mu.Lock()
+	token, expires := l.token, l.tokenExpires
+	l.mu.Unlock()
+	if token != "" && time.Now().Before(expires) {
+		return token, nil
+	}
+
+	requested := time.Now()
+	token, err := l.do(ctx, http.MethodPut, endpoint+"/latest/api/token", http.Header{
+		"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {strconv.Itoa(int(tokenTTL / time.Second))},
+	})
+	if err != nil {
+		return "", err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.token, l.tokenExpires = token, requested.Add(tokenTTL-tokenMargin)
+	return token, nil
+}
+
+// gceValue trims the resource paths GCE returns, e.g. projects/1/zones/us-east1-b.
+func gceValue(attr Attribute, value string) string {
+	switch attr {
+	case Zone, InstanceType:
+		return value[strings.LastIndex(value, "/")+1:]
+	case Region:
+		zone := value[strings.LastIndex(value, "/")+1:]
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			return zone[:i]
+		}
+		return zone
+	}
+	return value
+}
+
+func (l *Lookuper) do(ctx context.Context, method, url string, header http.Header) (string, error) {
+	timeout := l.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+
+	client := l.Client
+	if client == nil {
+		client = metadataClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("cloudmeta: " + method + " " + url + ": " + resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
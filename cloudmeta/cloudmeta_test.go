@@ -0,0 +1,263 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestEC2(t *testing.T) {
+	var tokens, reads int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			tokens++
+			w.Write([]byte("tok"))
+		case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "tok":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/placement/region":
+			reads++
+			w.Write([]byte("eu-west-1\n"))
+		case r.URL.Path == "/latest/meta-data/placement/availability-zone":
+			w.Write([]byte("eu-west-1a"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var s struct {
+		Region string
+		Zone   string
+		ID     string
+		Debug  bool
+	}
+	os.Clearenv()
+	os.Setenv("APP_DEBUG", "true")
+	meta := &Lookuper{
+		Provider: EC2,
+		Endpoint: srv.URL,
+		Keys: map[string]Attribute{
+			"APP_REGION": Region,
+			"APP_ZONE":   Zone,
+			"APP_ID":     InstanceID,
+		},
+	}
+	options := envconfig.Options{Lookuper: envconfig.MultiLookuper(envconfig.OSLookuper(), meta)}
+	for i := 0; i < 2; i++ {
+		if err := envconfig.ProcessWithOptions("app", &s, options); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if s.Region != "eu-west-1" || s.Zone != "eu-west-1a" || s.ID != "" || !s.Debug {
+		t.Errorf("unexpected values %+v", s)
+	}
+	if tokens != 1 || reads != 1 {
+		t.Errorf("expected one token and one region request, got %d and %d", tokens, reads)
+	}
+}
+
+func TestEC2TokenRefresh(t *testing.T) {
+	var tokens int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			tokens++
+			if r.Header.Get("X-Aws-Ec2-Metadata-Token-Ttl-Seconds") != "21600" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			w.Write([]byte("tok"))
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: EC2, Endpoint: srv.URL, Keys: map[string]Attribute{"REGION": Region, "ZONE": Zone}}
+	if _, ok := meta.Lookup("REGION"); !ok {
+		t.Fatal("expected the region to be found")
+	}
+	if d := time.Until(meta.tokenExpires); d <= 5*time.Hour || d >= 6*time.Hour {
+		t.Errorf("expected the token to be replaced before it expires, got %v", d)
+	}
+
+	// an expired token is replaced
+	meta.tokenExpires = time.Now().Add(-time.Second)
+	if _, ok := meta.Lookup("ZONE"); !ok {
+		t.Fatal("expected the zone to be found")
+	}
+	if tokens != 2 {
+		t.Errorf("expected two token requests, got %d", tokens)
+	}
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	var fail int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("projects/1/zones/us-east1-b"))
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: GCE, Endpoint: srv.URL, Keys: map[string]Attribute{"ZONE": Zone}}
+	if _, ok := meta.Lookup("ZONE"); ok {
+		t.Error("expected the failed lookup to be missing")
+	}
+	atomic.StoreInt32(&fail, 0)
+	if zone, ok := meta.Lookup("ZONE"); !ok || zone != "us-east1-b" {
+		t.Errorf("expected us-east1-b after the service recovered, got %q", zone)
+	}
+}
+
+func TestGCE(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/zone":
+			w.Write([]byte("projects/123/zones/us-central1-a"))
+		case "/computeMetadata/v1/instance/machine-type":
+			w.Write([]byte("projects/123/machineTypes/e2-small"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{
+		Provider: GCE,
+		Endpoint: srv.URL,
+		Keys:     map[string]Attribute{"REGION": Region, "ZONE": Zone, "TYPE": InstanceType},
+	}
+	for key, want := range map[string]string{"REGION": "us-central1", "ZONE": "us-central1-a", "TYPE": "e2-small"} {
+		if got, ok := meta.Lookup(key); !ok || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", key, want, got, ok)
+		}
+	}
+	if _, ok := meta.Lookup("OTHER"); ok {
+		t.Error("expected unmapped key to be missing")
+	}
+}
+
+func TestAzure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("format") != "text" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/metadata/instance/compute/location" {
+			w.Write([]byte("westeurope"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: Azure, Endpoint: srv.URL, Keys: map[string]Attribute{"REGION": Region}}
+	if got, ok := meta.Lookup("REGION"); !ok || got != "westeurope" {
+		t.Errorf("expected %q, got %q (%v)", "westeurope", got, ok)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: GCE, Endpoint: srv.URL, Timeout: 10 * time.Millisecond, Keys: map[string]Attribute{"ZONE": Zone}}
+	if _, ok := meta.Lookup("ZONE"); ok {
+		t.Error("expected lookup to time out")
+	}
+}
+
+func TestLookupContext(t *testing.T) {
+	var slow int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("projects/1/zones/us-east1-b"))
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: GCE, Endpoint: srv.URL, Keys: map[string]Attribute{"ZONE": Zone}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok := meta.LookupContext(ctx, "ZONE"); ok {
+		t.Error("expected lookup to stop at the deadline")
+	}
+
+	// the abandoned lookup is not cached
+	atomic.StoreInt32(&slow, 0)
+	if zone, ok := meta.Lookup("ZONE"); !ok || zone != "us-east1-b" {
+		t.Errorf("expected us-east1-b, got %q", zone)
+	}
+}
+
+func TestLookupErr(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var s struct {
+		Zone string `default:"local"`
+	}
+	meta := &Lookuper{Provider: GCE, Endpoint: srv.URL, Keys: map[string]Attribute{"APP_ZONE": Zone, "APP_MISSING": "missing"}}
+	if _, _, err := meta.LookupErr(context.Background(), "APP_MISSING"); err == nil {
+		t.Error("expected error for an attribute the provider does not have")
+	}
+	if _, ok, err := meta.LookupErr(context.Background(), "APP_OTHER"); ok || err != nil {
+		t.Errorf("expected an unmapped key to be unset, got %v, %v", ok, err)
+	}
+	err := envconfig.ProcessWithOptions("app", &s, envconfig.Options{Lookuper: meta})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the outage to fail processing, got %v (zone %q)", err, s.Zone)
+	}
+}
+
+func TestConcurrentLookups(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("projects/1/zones/us-east1-b"))
+	}))
+	defer srv.Close()
+
+	meta := &Lookuper{Provider: GCE, Endpoint: srv.URL, Keys: map[string]Attribute{"ZONE": Zone, "TYPE": InstanceType}}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, key := range []string{"ZONE", "TYPE"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			meta.Lookup(key)
+		}(key)
+	}
+	wg.Wait()
+	if d := time.Since(start); d >= 190*time.Millisecond {
+		t.Errorf("expected lookups of different keys not to wait for each other, took %v", d)
+	}
+}
+
+func TestDefaultClientIgnoresProxy(t *testing.T) {
+	if metadataClient.Transport.(*http.Transport).Proxy != nil {
+		t.Error("expected the metadata client not to use a proxy")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package compat exposes exactly the API of upstream
+// github.com/kelseyhightower/envconfig on top of this fork's engine, so that
+// large codebases can switch import paths one package at a time:
+//
+//	import envconfig "github.com/kelseyhightower/envconfig/compat"
+//
+// The functions process specifications with the zero Options, which match
+// upstream behaviour, and return the same errors and error strings. Types are
+// aliases, so values and errors can be shared with code that already uses the
+// full package.
+package compat
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ErrInvalidSpecification indicates that a specification is of the wrong type.
+var ErrInvalidSpecification = envconfig.ErrInvalidSpecification
+
+// A ParseError occurs when an environment variable cannot be converted to
+// the type required by a struct field during assignment.
+type ParseError = envconfig.ParseError
+
+// Decoder has the same semantics as Setter, but takes higher precedence.
+// It is provided for historical compatibility.
+type Decoder = envconfig.Decoder
+
+// Setter is implemented by types can self-deserialize values.
+// Any type that implements flag.Value also implements Setter.
+type Setter = envconfig.Setter
+
+const (
+	// DefaultListFormat constant to use to display usage in a list format
+	DefaultListFormat = envconfig.DefaultListFormat
+	// DefaultTableFormat constant to use to display usage in a tabular format
+	DefaultTableFormat = envconfig.DefaultTableFormat
+)
+
+// CheckDisallowed checks that no environment variables with the prefix are set
+// that we don't know how or want to parse. This is likely only meaningful with
+// a non-empty prefix.
+func CheckDisallowed(prefix string, spec interface{}) error {
+	return envconfig.CheckDisallowed(prefix, spec)
+}
+
+// Process populates the specified struct based on environment variables
+func Process(prefix string, spec interface{}) error {
+	return upstreamError(envconfig.Process(prefix, spec))
+}
+
+// MustProcess is the same as Process but panics if an error occurs
+func MustProcess(prefix string, spec interface{}) {
+	if err := Process(prefix, spec); err != nil {
+		panic(err)
+	}
+}
+
+// upstreamError drops the `desc` tag that this package adds to the messages
+// of errors, which upstream does not.
+func upstreamError(err error) error {
+	switch e := err.(type) {
+	case *envconfig.ParseError:
+		e.Description = ""
+	case *envconfig.RequiredError:
+		e.Description = ""
+	}
+	return err
+}
+
+// Usage writes usage information to stdout using the default header and table format
+func Usage(prefix string, spec interface{}) error {
+	return envconfig.Usage(prefix, spec)
+}
+
+// Usagef writes usage information to the specified io.Writer using the specified template specification
+func Usagef(prefix string, spec interface{}, out io.Writer, format string) error {
+	return envconfig.Usagef(prefix, spec, out, format)
+}
+
+// Usaget writes usage information to the specified io.Writer using the specified template
+func Usaget(prefix string, spec interface{}, out io.Writer, tmpl *template.Template) error {
+	return envconfig.Usaget(prefix, spec, out, tmpl)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package compat
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type specification struct {
+	Debug      bool
+	Port       int
+	Timeout    time.Duration
+	Users      []string
+	Required   string `required:"true"`
+	MultiWord  string `split_words:"true" default:"x"`
+	Alternate  string `envconfig:"OTHER_NAME"`
+	Percentage float32
+}
+
+func TestProcess(t *testing.T) {
+	var s specification
+	os.Clearenv()
+	os.Setenv("APP_DEBUG", "true")
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_TIMEOUT", "2m")
+	os.Setenv("APP_USERS", "rob,ken")
+	os.Setenv("APP_REQUIRED", "yes")
+	os.Setenv("APP_OTHER_NAME", "alt")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !s.Debug || s.Port != 8080 || s.Timeout != 2*time.Minute || len(s.Users) != 2 {
+		t.Errorf("unexpected specification %+v", s)
+	}
+	if s.MultiWord != "x" {
+		t.Errorf("expected %q, got %q", "x", s.MultiWord)
+	}
+	if s.Alternate != "alt" {
+		t.Errorf("expected %q, got %q", "alt", s.Alternate)
+	}
+
+	// defaults are used as written, as upstream
+	os.Setenv("HOME", "/home/gopher")
+	var d struct {
+		DataDir string `default:"${HOME}/data"`
+	}
+	if err := Process("app", &d); err != nil {
+		t.Fatal(err.Error())
+	}
+	if d.DataDir != "${HOME}/data" {
+		t.Errorf("expected %q, got %q", "${HOME}/data", d.DataDir)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var s specification
+	os.Clearenv()
+	err := Process("app", &s)
+	if err == nil || err.Error() != "required key APP_REQUIRED missing value" {
+		t.Errorf("expected required key error, got %v", err)
+	}
+
+	os.Setenv("APP_REQUIRED", "yes")
+	os.Setenv("APP_PORT", "eighty")
+	err = Process("app", &s)
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	if _, ok := err.(*envconfig.ParseError); !ok {
+		t.Errorf("expected compat.ParseError to be envconfig.ParseError")
+	}
+	if !strings.HasPrefix(perr.Error(), "envconfig.Process: assigning APP_PORT to Port: converting 'eighty' to type int.") {
+		t.Errorf("unexpected error string %q", perr.Error())
+	}
+
+	if err := Process("app", s); err != ErrInvalidSpecification {
+		t.Errorf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+
+	// descriptions are left out, as upstream
+	var described struct {
+		Key  string `required:"true" desc:"the key"`
+		Port int    `desc:"the port"`
+	}
+	os.Setenv("APP_PORT", "eighty")
+	if err := Process("app", &described); err == nil || err.Error() != "required key APP_KEY missing value" {
+		t.Errorf("expected required key error without the description, got %v", err)
+	}
+	os.Setenv("APP_KEY", "k")
+	if err := Process("app", &described); err == nil || !strings.HasPrefix(err.Error(), "envconfig.Process: assigning APP_PORT to Port: converting") {
+		t.Errorf("expected parse error without the description, got %v", err)
+	}
+
+	os.Unsetenv("APP_KEY")
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_UNKNOWN", "1")
+	err = CheckDisallowed("app", &s)
+	if err == nil || err.Error() != "unknown environment variable APP_UNKNOWN" {
+		t.Errorf("expected unknown variable error, got %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var s specification
+	os.Clearenv()
+	got, want := new(bytes.Buffer), new(bytes.Buffer)
+	if err := Usagef("app", &s, got, DefaultListFormat); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := envconfig.Usagef("app", &s, want, envconfig.DefaultListFormat); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got.String() != want.String() {
+		t.Errorf("expected %q, got %q", want.String(), got.String())
+	}
+}
+
+type requestScoped struct {
+	Name string
+}
+
+// Validate fails as a method meant for request handling would outside one.
+func (s *requestScoped) Validate() error {
+	return errors.New("needs request context")
+}
+
+func TestProcessSkipsValidate(t *testing.T) {
+	var s requestScoped
+	os.Clearenv()
+	if err := Process("app", &s); err != nil {
+		t.Errorf("expected Validate not to be called, got %v", err)
+	}
+}
+
+func TestProcessBracedDefault(t *testing.T) {
+	var s struct {
+		Greeting string `default:"hello {{name}}"`
+	}
+	os.Clearenv()
+	if err := Process("app", &s); err != nil || s.Greeting != "hello {{name}}" {
+		t.Errorf("expected the literal default, got %q (%v)", s.Greeting, err)
+	}
+}
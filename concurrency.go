@@ -0,0 +1,49 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Concurrency is a count, such as of workers or queue slots, that may be
+// given relative to the CPUs of the host. It decodes a plain number ("8"), a
+// multiple of numcpu ("2x", "0.5x") or an expression as allowed in numeric
+// defaults ("numcpu-1", "max(2, gomaxprocs/2)"). Fractions are rounded down
+// and negative results are rejected.
+type Concurrency int
+
+// Decode implements Decoder.
+func (c *Concurrency) Decode(value string) error {
+	s := strings.TrimSpace(value)
+	var (
+		v   float64
+		err error
+	)
+	if m := strings.TrimSuffix(s, "x"); m != s {
+		v, err = strconv.ParseFloat(strings.TrimSpace(m), 64)
+		v *= float64(numCPU())
+	} else if n, perr := strconv.ParseInt(s, 10, 0); perr == nil {
+		v = float64(n)
+	} else {
+		v, err = (&exprParser{src: s}).parse()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid concurrency %q", value)
+	}
+	if v < 0 || v > math.MaxInt32 {
+		return fmt.Errorf("concurrency %q out of range", value)
+	}
+	*c = Concurrency(math.Floor(v))
+	return nil
+}
+
+// Int returns the count as an int.
+func (c Concurrency) Int() int {
+	return int(c)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "testing"
+
+func TestConcurrency(t *testing.T) {
+	saved := numCPU
+	numCPU = func() int { return 8 }
+	defer func() { numCPU = saved }()
+
+	tests := map[string]int{
+		"4":                4,
+		"2x":               16,
+		"0.5x":             4,
+		" 1.5 x":           12,
+		"numcpu-1":         7,
+		"max(2, numcpu/3)": 2,
+		"0":                0,
+	}
+	for value, want := range tests {
+		var c Concurrency
+		if err := c.Decode(value); err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+			continue
+		}
+		if c.Int() != want {
+			t.Errorf("%q: expected %d, got %d", value, want, c.Int())
+		}
+	}
+	for _, value := range []string{"", "x", "twox", "-1", "numcpu-9", "1e12"} {
+		var c Concurrency
+		if err := c.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %d", value, c)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// oneOf returns the values allowed by the field's `oneof` tag, or nil.
+func (info varInfo) oneOf() []string {
+	tag := info.Tags.Get("oneof")
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// checkConstraints checks the decoded field against its `min` and `max` tags,
+// which bound numbers and durations, and value against its `oneof` tag, which
+// lists the values allowed for a string or each item of a slice.
+func (info varInfo) checkConstraints(value string) error {
+	if allowed := info.oneOf(); allowed != nil {
+		items := []string{value}
+		if k := info.Field.Kind(); k == reflect.Slice || k == reflect.Array {
+			items = strings.Split(value, info.Delimiter)
+		}
+		for _, item := range items {
+			if !containsString(allowed, item) {
+				return fmt.Errorf("%q is not one of %s", item, strings.Join(allowed, ", "))
+			}
+		}
+	}
+
+	for _, bound := range []string{"min", "max"} {
+		tag := info.Tags.Get(bound)
+		if tag == "" {
+			continue
+		}
+		field := info.Field
+		for field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		limit := reflect.New(field.Type()).Elem()
+		if err := processField(tag, limit); err != nil {
+			return fmt.Errorf("invalid %s %q: %v", bound, tag, err)
+		}
+		c, ok := compareNumbers(field, limit)
+		if !ok {
+			return fmt.Errorf("%s applies only to numbers, not %s", bound, field.Type())
+		}
+		if bound == "min" && c < 0 {
+			return fmt.Errorf("%s is less than the minimum %s", value, tag)
+		}
+		if bound == "max" && c > 0 {
+			return fmt.Errorf("%s is greater than the maximum %s", value, tag)
+		}
+	}
+	return nil
+}
+
+// compareNumbers returns -1, 0 or 1 as a is less than, equal to or greater
+// than b, which have the same numeric type.
+func compareNumbers(a, b reflect.Value) (int, bool) {
+	var less, greater bool
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = a.Int() < b.Int(), a.Int() > b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less, greater = a.Uint() < b.Uint(), a.Uint() > b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = a.Float() < b.Float(), a.Float() > b.Float()
+	default:
+		return 0, false
+	}
+	switch {
+	case less:
+		return -1, true
+	case greater:
+		return 1, true
+	}
+	return 0, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
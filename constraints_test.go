@@ -0,0 +1,32 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "testing"
+
+func TestConstraints(t *testing.T) {
+	var s struct {
+		Port  int      `min:"1" max:"65535"`
+		Level string   `oneof:"debug,info"`
+		Modes []string `oneof:"r,w"`
+		Name  string   `min:"1"`
+	}
+	for _, tc := range []struct {
+		env   map[string]string
+		valid bool
+	}{
+		{map[string]string{"APP_PORT": "443", "APP_LEVEL": "info", "APP_MODES": "r,w"}, true},
+		{map[string]string{"APP_PORT": "0"}, false},
+		{map[string]string{"APP_PORT": "65536"}, false},
+		{map[string]string{"APP_LEVEL": "trace"}, false},
+		{map[string]string{"APP_MODES": "r,x"}, false},
+		{map[string]string{"APP_NAME": "x"}, false},
+	} {
+		err := ProcessWithOptions("app", &s, Options{Lookuper: MapLookuper(tc.env)})
+		if (err == nil) != tc.valid {
+			t.Errorf("%v: expected valid %v, got %v", tc.env, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextLookuper is implemented by Lookupers, typically remote ones, that
+// can give up a lookup when the context of ProcessContext is done.
+type ContextLookuper interface {
+	Lookuper
+	LookupContext(ctx context.Context, key string) (string, bool)
+}
+
+// ProcessContext is like ProcessWithOptions() but stops when ctx is done and
+// returns an error that wraps the context's error, so a deadline bounds the
+// time spent on remote Lookupers. No further variables are processed once
+// ctx is done, and lookups in progress are abandoned when the Lookuper
+// implements ContextLookuper; others run to completion, since processing
+// waits for every goroutine it starts before it returns. Fields processed
+// before ctx was done keep their values.
+func ProcessContext(ctx context.Context, prefix string, spec interface{}, options Options) error {
+	options.ctx = ctx
+	if err := options.canceled(); err != nil {
+		return err
+	}
+	return ProcessWithOptions(prefix, spec, options)
+}
+
+// canceled returns an error once the context of ProcessContext is done.
+func (options Options) canceled() error {
+	if options.ctx == nil {
+		return nil
+	}
+	if err := options.ctx.Err(); err != nil {
+		return fmt.Errorf("envconfig.Process: %w", err)
+	}
+	return nil
+}
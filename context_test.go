@@ -0,0 +1,115 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowLookuper blocks each lookup of a key other than fast until its context
+// is done.
+type slowLookuper struct {
+	fast    string
+	pending int32
+}
+
+func (l *slowLookuper) Lookup(key string) (string, bool) {
+	return l.LookupContext(context.Background(), key)
+}
+
+func (l *slowLookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	if key == l.fast {
+		return "fast", true
+	}
+	atomic.AddInt32(&l.pending, 1)
+	defer atomic.AddInt32(&l.pending, -1)
+	<-ctx.Done()
+	return "", false
+}
+
+func TestProcessContext(t *testing.T) {
+	var s struct {
+		Fast string
+		A    string `required:"true"`
+		B    string
+		C    string
+	}
+	for _, parallel := range []bool{false, true} {
+		l := &slowLookuper{fast: "MYAPP_FAST"}
+		options := Options{Lookuper: MultiLookuper(MapLookuper{}, l), ParallelExcecution: parallel, CollectErrors: true}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		err := ProcessContext(ctx, "myapp", &s, options)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("parallel %t: expected context.DeadlineExceeded, got %v", parallel, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("parallel %t: expected processing to stop at the deadline, took %v", parallel, d)
+		}
+		if n := atomic.LoadInt32(&l.pending); n != 0 {
+			t.Errorf("parallel %t: expected no lookups left running, got %d", parallel, n)
+		}
+		if s.Fast != "fast" {
+			t.Errorf("parallel %t: expected the field processed first to be set, got %q", parallel, s.Fast)
+		}
+	}
+
+	// a lookup of the last variable abandoned at the deadline
+	var last struct {
+		Fast string
+		Slow string
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	err := ProcessContext(ctx, "myapp", &last, Options{Lookuper: &slowLookuper{fast: "MYAPP_FAST"}})
+	cancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := ProcessContext(ctx, "myapp", &s, Options{Lookuper: MapLookuper{}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if err := ProcessContext(context.Background(), "myapp", &s, Options{Lookuper: MapLookuper{"MYAPP_A": "a"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// failingLookuper fails every lookup of key.
+type failingLookuper struct {
+	key string
+}
+
+func (l failingLookuper) Lookup(key string) (string, bool) {
+	value, ok, _ := l.LookupErr(context.Background(), key)
+	return value, ok
+}
+
+func (l failingLookuper) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	if key == l.key {
+		return "", false, errors.New("backend unavailable")
+	}
+	return "", false, nil
+}
+
+func TestErrorLookuper(t *testing.T) {
+	var s struct {
+		Port int `default:"80"`
+	}
+	l := MultiLookuper(failingLookuper{key: "MYAPP_PORT"}, MapLookuper{"MYAPP_PORT": "8080"})
+	err := ProcessWithOptions("myapp", &s, Options{Lookuper: l})
+	if err == nil || err.Error() != "envconfig.Process: looking up MYAPP_PORT for Port: backend unavailable" {
+		t.Errorf("expected the failed lookup to be returned, got %v", err)
+	}
+	if s.Port != 0 {
+		t.Errorf("expected neither the default nor a later source to be used, got %d", s.Port)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A DecodeFunc decodes a value into a value of the type it is registered
+// for, or a pointer to one.
+type DecodeFunc func(value string) (interface{}, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]DecodeFunc)
+)
+
+// RegisterDecoder makes fields of type typ, and pointers to it, decode with
+// fn, so types from other packages that have no decoding method need no
+// wrapper type:
+//
+//	envconfig.RegisterDecoder(reflect.TypeOf(decimal.Decimal{}), func(v string) (interface{}, error) {
+//		return decimal.NewFromString(v)
+//	})
+//
+// A registered decoder takes precedence over the methods of the type. A nil
+// fn removes the decoder for typ.
+func RegisterDecoder(typ reflect.Type, fn DecodeFunc) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if fn == nil {
+		delete(decoders, typ)
+		return
+	}
+	decoders[typ] = fn
+}
+
+func registeredDecoder(typ reflect.Type) DecodeFunc {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	return decoders[typ]
+}
+
+// decodeRegistered decodes value into field with the decoder registered for
+// its type, reporting whether there is one.
+func decodeRegistered(value string, field reflect.Value) (bool, error) {
+	fn := registeredDecoder(field.Type())
+	if fn == nil {
+		return false, nil
+	}
+	v, err := fn(value)
+	if err != nil {
+		return true, err
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.IsValid() && rv.Type() == field.Type():
+		field.Set(rv)
+	case rv.IsValid() && rv.Kind() == reflect.Ptr && rv.Type().Elem() == field.Type() && !rv.IsNil():
+		field.Set(rv.Elem())
+	default:
+		return true, fmt.Errorf("decoder for %s returned %T", field.Type(), v)
+	}
+	return true, nil
+}
+
+// isDecodable reports whether field is decoded as a whole rather than walked
+// as a struct of variables.
+func isDecodable(field reflect.Value) bool {
+	return decoderFrom(field) != nil || setterFrom(field) != nil ||
+		textUnmarshaler(field) != nil || binaryUnmarshaler(field) != nil ||
+		isStdlibType(field.Type()) || registeredDecoder(field.Type()) != nil
+}
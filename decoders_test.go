@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// money stands in for a type from another package, such as decimal.Decimal:
+// a struct with no decoding method.
+type money struct {
+	Cents    int64
+	Currency string
+}
+
+func (m money) String() string {
+	return fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency)
+}
+
+func parseMoney(value string) (interface{}, error) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return nil, errors.New("expected an amount and a currency")
+	}
+	f, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	return &money{Cents: int64(f*100 + 0.5), Currency: parts[1]}, nil
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder(reflect.TypeOf(money{}), parseMoney)
+	defer RegisterDecoder(reflect.TypeOf(money{}), nil)
+
+	var s struct {
+		Price  money
+		Limit  *money
+		Tiers  []money `delimiter:";"`
+		Budget money   `default:"10 EUR"`
+	}
+	os.Clearenv()
+	os.Setenv("APP_PRICE", "4.99 USD")
+	os.Setenv("APP_LIMIT", "100 USD")
+	os.Setenv("APP_TIERS", "1 USD;2.50 USD")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Price != (money{499, "USD"}) || s.Limit == nil || s.Limit.Cents != 10000 || s.Budget.Cents != 1000 {
+		t.Errorf("unexpected values %+v", s)
+	}
+	if len(s.Tiers) != 2 || s.Tiers[1].Cents != 250 {
+		t.Errorf("unexpected tiers %v", s.Tiers)
+	}
+	if err := VerifyRoundTrip(&s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	vars, err := Describe("app", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vars) != 4 || vars[0].TypeDescription != "money" {
+		t.Errorf("unexpected variables %+v", vars)
+	}
+
+	os.Setenv("APP_PRICE", "free")
+	var perr *ParseError
+	if err := Process("app", &s); !errors.As(err, &perr) || perr.KeyName != "APP_PRICE" {
+		t.Errorf("expected a ParseError for APP_PRICE, got %v", err)
+	}
+
+	RegisterDecoder(reflect.TypeOf(money{}), func(string) (interface{}, error) { return 1, nil })
+	os.Setenv("APP_PRICE", "1 USD")
+	if err := Process("app", &s); err == nil || !strings.Contains(err.Error(), "returned int") {
+		t.Errorf("expected error for a decoder returning the wrong type, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// deriveFields sets every field tagged `derive` from the expression in the
+// tag, once the rest of spec has been processed. Fields are derived in
+// declaration order, so an expression may refer to fields derived before it.
+//
+// An expression is a field name (or dotted path) of the struct holding the
+// derived field, a quoted string, or a call of join, format, lower, upper or
+// coalesce:
+//
+//	Addr string `derive:"join(Host,':',Port)"`
+//	DSN  string `derive:"format('postgres://%s@%s/%s',User,Host,Name)"`
+func deriveFields(spec interface{}) error {
+	return deriveStruct(reflect.ValueOf(spec).Elem(), "")
+}
+
+func deriveStruct(s reflect.Value, path string) error {
+	typ := s.Type()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		ftype := typ.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		name := path + ftype.Name
+
+		if expr := ftype.Tag.Get("derive"); expr != "" {
+			p := &deriveParser{src: expr, scope: s}
+			v, err := p.parse()
+			if err == nil {
+				err = setDerived(f, v)
+			}
+			if err != nil {
+				return fmt.Errorf("envconfig.Process: deriving %s: %v", name, err)
+			}
+			continue
+		}
+
+		for f.Kind() == reflect.Ptr && !f.IsNil() {
+			f = f.Elem()
+		}
+		if f.Kind() == reflect.Struct && !isDecodable(f) {
+			if err := deriveStruct(f, name+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDerived stores v in field, decoding it like a variable unless the field
+// can hold v directly. A nil v, as from an unset interface or pointer field,
+// leaves the field zero.
+func setDerived(field reflect.Value, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return nil
+	}
+	return processField(fmt.Sprint(v), field)
+}
+
+// deriveParser evaluates derive expressions:
+//
+//	expr = call | string | path
+//	call = ident "(" [ expr { "," expr } ] ")"
+//	path = ident { "." ident }
+type deriveParser struct {
+	src   string
+	pos   int
+	scope reflect.Value
+}
+
+func (p *deriveParser) parse() (interface{}, error) {
+	v, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos != len(p.src) {
+		return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+	}
+	return v, nil
+}
+
+func (p *deriveParser) expr() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if c := p.src[p.pos]; c == '\'' || c == '"' {
+		return p.str(c)
+	}
+
+	name := p.ident()
+	if name == "" {
+		return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+	}
+	if p.skipSpace(); p.pos < len(p.src) && p.src[p.pos] == '(' {
+		p.pos++
+		args, err := p.args()
+		if err != nil {
+			return nil, err
+		}
+		return callDerive(name, args)
+	}
+	for p.pos < len(p.src) && p.src[p.pos] == '.' {
+		p.pos++
+		name += "." + p.ident()
+	}
+	return p.field(name)
+}
+
+func (p *deriveParser) args() ([]interface{}, error) {
+	var args []interface{}
+	if p.skipSpace(); p.pos < len(p.src) && p.src[p.pos] == ')' {
+		p.pos++
+		return args, nil
+	}
+	for {
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("missing )")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return args, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+		}
+	}
+}
+
+func (p *deriveParser) str(quote byte) (interface{}, error) {
+	end := strings.IndexByte(p.src[p.pos+1:], quote)
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	s := p.src[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+func (p *deriveParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := rune(p.src[p.pos])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// field returns the value of the dotted field path, relative to the scope.
+func (p *deriveParser) field(path string) (interface{}, error) {
+	v := p.scope
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%s is not a struct field", path)
+		}
+		sf, ok := v.Type().FieldByName(name)
+		if !ok || sf.PkgPath != "" {
+			return nil, fmt.Errorf("unknown field %s", path)
+		}
+		v = v.FieldByIndex(sf.Index)
+	}
+	return v.Interface(), nil
+}
+
+func (p *deriveParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// callDerive applies the named function to args.
+func callDerive(name string, args []interface{}) (interface{}, error) {
+	switch name {
+	case "join":
+		var b strings.Builder
+		for _, a := range args {
+			fmt.Fprint(&b, a)
+		}
+		return b.String(), nil
+	case "format":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("format requires a format string")
+		}
+		format, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("format string must be a string")
+		}
+		return fmt.Sprintf(format, args[1:]...), nil
+	case "lower", "upper":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes one argument", name)
+		}
+		if name == "lower" {
+			return strings.ToLower(fmt.Sprint(args[0])), nil
+		}
+		return strings.ToUpper(fmt.Sprint(args[0])), nil
+	case "coalesce":
+		for _, a := range args {
+			if rv := reflect.ValueOf(a); rv.IsValid() && !rv.IsZero() {
+				return a, nil
+			}
+		}
+		if len(args) > 0 {
+			return args[len(args)-1], nil
+		}
+		return "", nil
+	}
+	return nil, fmt.Errorf("unknown function %q", name)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type deriveSpecification struct {
+	Host   string `default:"localhost"`
+	Port   int    `default:"8080"`
+	Addr   string `derive:"join(Host,':',Port)"`
+	Public string
+	URL    string `derive:"format('http://%s/', coalesce(Public, Addr))"`
+	DB     struct {
+		User string `default:"app"`
+		Name string `default:"prod"`
+		DSN  string `derive:"format(\"postgres://%s@db/%s\", User, lower(Name))"`
+	}
+	Replicas int `derive:"Port"`
+}
+
+func TestDerive(t *testing.T) {
+	var s deriveSpecification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DB_NAME", "Orders")
+	os.Setenv("ENV_CONFIG_ADDR", "ignored")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Addr != "localhost:8080" {
+		t.Errorf("expected %q, got %q", "localhost:8080", s.Addr)
+	}
+	if s.URL != "http://localhost:8080/" {
+		t.Errorf("expected %q, got %q", "http://localhost:8080/", s.URL)
+	}
+	if s.DB.DSN != "postgres://app@db/orders" {
+		t.Errorf("expected %q, got %q", "postgres://app@db/orders", s.DB.DSN)
+	}
+	if s.Replicas != 8080 {
+		t.Errorf("expected %d, got %d", 8080, s.Replicas)
+	}
+
+	os.Setenv("ENV_CONFIG_PUBLIC", "example.com")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.URL != "http://example.com/" {
+		t.Errorf("expected %q, got %q", "http://example.com/", s.URL)
+	}
+}
+
+func TestDeriveNestedScope(t *testing.T) {
+	var s struct {
+		DB struct {
+			Host string `default:"db"`
+			Port int    `default:"5432"`
+			Addr string `derive:"join(Host,':',Port)"`
+		}
+	}
+	os.Clearenv()
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DB.Addr != "db:5432" {
+		t.Errorf("expected %q, got %q", "db:5432", s.DB.Addr)
+	}
+}
+
+func TestDeriveNil(t *testing.T) {
+	var s struct {
+		Extra   interface{} `ignored:"true"`
+		Proxy   *string
+		Name    string  `derive:"coalesce(Extra,Proxy,'fallback')"`
+		Copy    string  `derive:"Extra"`
+		Pointer *string `derive:"Proxy"`
+	}
+	os.Clearenv()
+	s.Copy = "stale"
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Name != "fallback" || s.Copy != "" || s.Pointer != nil {
+		t.Errorf("expected nil values to be skipped or left zero, got %+v", s)
+	}
+}
+
+func TestDeriveErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"unknown field": &struct {
+			Addr string `derive:"join(Host)"`
+		}{},
+		"unknown function": &struct {
+			Host string
+			Addr string `derive:"concat(Host)"`
+		}{},
+		"syntax": &struct {
+			Host string
+			Addr string `derive:"join(Host"`
+		}{},
+	}
+	for name, spec := range tests {
+		os.Clearenv()
+		err := Process("env_config", spec)
+		if err == nil || !strings.HasPrefix(err.Error(), "envconfig.Process: deriving Addr: ") {
+			t.Errorf("%s: expected derive error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+// VarSpec describes one variable of a specification, for tools that catalog
+// configuration or generate templates such as Kubernetes ConfigMaps.
+type VarSpec struct {
+	// Key is the variable looked up first, e.g. MYAPP_PORT.
+	Key string `json:"key"`
+	// Alt is the alternate name of the `envconfig` tag, and Aliases the
+	// fallback names of the `alt` tag.
+	Alt     string   `json:"alt,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Field is the dotted path of the struct field.
+	Field string `json:"field"`
+	// Type is the Go type of the field, and TypeDescription the description
+	// shown by Usage, e.g. "Comma-separated list of Integer".
+	Type            string `json:"type"`
+	TypeDescription string `json:"typeDescription,omitempty"`
+	// Default is the default under the options' profile and the current
+	// operating system. It is "<sensitive>" for sensitive fields.
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Sensitive   bool   `json:"sensitive"`
+	Description string `json:"description,omitempty"`
+}
+
+// Describe returns a description of every variable of the specification,
+// without consulting the environment.
+func Describe(prefix string, spec interface{}) ([]VarSpec, error) {
+	return DescribeWithOptions(prefix, spec, Options{})
+}
+
+// DescribeWithOptions is like Describe() but with specified options.
+func DescribeWithOptions(prefix string, spec interface{}, options Options) ([]VarSpec, error) {
+	infos, err := gatherOrdered(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]VarSpec, len(infos))
+	for i, info := range infos {
+		req := info.Tags.Get("required")
+		v := VarSpec{
+			Key:             info.Key,
+			Alt:             info.Alt,
+			Aliases:         info.aliases(),
+			Field:           info.Path,
+			Type:            info.Field.Type().String(),
+			TypeDescription: toTypeDescription(info.Field.Type(), info.Delimiter, info.MapDelimiter),
+			Required:        isTrue(req) || (options.Required && !isFalse(req)),
+			Sensitive:       info.isSensitive(),
+			Description:     info.Tags.Get("desc"),
+		}
+		if def := info.defaultValue(); def != "" {
+			v.Default = info.shownValue(def)
+		}
+		vars[i] = v
+	}
+	return vars, nil
+}
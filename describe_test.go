@@ -0,0 +1,46 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	var s struct {
+		Port     int    `default:"8080" desc:"listen port"`
+		Password string `required:"true" sensitive:"true" default:"hunter2"`
+		Hosts    []string
+		Service  struct {
+			URL string `envconfig:"SERVICE_URL" alt:"OLD_URL"`
+		}
+	}
+	os.Clearenv()
+	os.Setenv("APP_PORT", "9090")
+	vars, err := Describe("app", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := []VarSpec{
+		{Key: "APP_PORT", Field: "Port", Type: "int", TypeDescription: "Integer", Default: "8080", Description: "listen port"},
+		{Key: "APP_PASSWORD", Field: "Password", Type: "string", TypeDescription: "String", Default: "<sensitive>", Required: true, Sensitive: true},
+		{Key: "APP_HOSTS", Field: "Hosts", Type: "[]string", TypeDescription: "Comma-separated list of String"},
+		{Key: "APP_SERVICE_SERVICE_URL", Alt: "SERVICE_URL", Aliases: []string{"OLD_URL"}, Field: "Service.URL", Type: "string", TypeDescription: "String"},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("expected\n%+v\ngot\n%+v", want, vars)
+	}
+
+	data, err := json.Marshal(vars[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(data) != `{"key":"APP_PORT","field":"Port","type":"int","typeDescription":"Integer","default":"8080","required":false,"sensitive":false,"description":"listen port"}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProcessFile is like Process() but also reads values from the dotenv files
+// at paths. Variables in the environment take precedence over the files, and
+// earlier files over later ones. A file that cannot be read is an error.
+func ProcessFile(prefix string, spec interface{}, paths ...string) error {
+	return ProcessFileWithOptions(prefix, spec, Options{}, paths...)
+}
+
+// ProcessFileWithOptions is like ProcessFile() but with specified options.
+// When Options.Lookuper is set it is consulted in place of the environment.
+func ProcessFileWithOptions(prefix string, spec interface{}, options Options, paths ...string) error {
+	lookupers := []Lookuper{options.Lookuper}
+	if options.Lookuper == nil {
+		lookupers[0] = OSLookuper()
+	}
+	for _, path := range paths {
+		l, err := readDotenvFile(path)
+		if err != nil {
+			return err
+		}
+		lookupers = append(lookupers, l)
+	}
+	options.Lookuper = MultiLookuper(lookupers...)
+	return ProcessWithOptions(prefix, spec, options)
+}
+
+func readDotenvFile(path string) (Lookuper, error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: %w", err)
+	}
+	pairs, err := parseDotenv(string(doc))
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: %s: %w", path, err)
+	}
+	return MapLookuper(pairs), nil
+}
+
+// isDotenv reports whether the variable is a nested struct configured by a
+// single dotenv document, as requested by the `format:"dotenv"` tag.
+func (info varInfo) isDotenv() bool {
+	return info.Tags.Get("format") == "dotenv"
+}
+
+// assignDotenv processes the nested struct of the variable from the KEY=VALUE
+// pairs in doc. The keys are those the struct has when processed without a
+// prefix, and an empty doc leaves only the defaults.
+func (info varInfo) assignDotenv(doc string, options Options) error {
+	pairs, err := parseDotenv(doc)
+	if err != nil {
+		return fmt.Errorf("envconfig.Process: %s: %w", info.Key, err)
+	}
+
+	inner := options
+	inner.Lookuper = MapLookuper(pairs)
+	inner.OnLookup = nil
+	inner.Registry = nil
+	inner.Result = nil
+	// the outer pass validates the struct and checks policies once
+	inner.Validate = false
+	inner.Policies = nil
+	if err := ProcessWithOptions("", info.Field.Addr().Interface(), inner); err != nil {
+		return fmt.Errorf("envconfig.Process: %s: %w", info.Key, err)
+	}
+	return nil
+}
+
+// parseDotenv parses newline separated KEY=VALUE pairs. Blank lines and lines
+// starting with # are skipped, a leading "export " is ignored, and values may
+// be single quoted (taken literally) or double quoted (with \n, \t, \" and \\
+// escapes). A quoted value continues over the following lines until its
+// closing quote, keeping the newlines. Unquoted values end at " #".
+func parseDotenv(doc string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	lines := strings.Split(doc, "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(strings.TrimSuffix(lines[n], "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: missing '='", n+1)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", n+1)
+		}
+		start := n
+		raw := strings.TrimSpace(line[eq+1:])
+		value, err := dotenvValue(raw)
+		for err == errUnterminated && n+1 < len(lines) {
+			n++
+			raw += "\n" + strings.TrimSuffix(lines[n], "\r")
+			value, err = dotenvValue(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start+1, err)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+var errUnterminated = errors.New("unterminated quote")
+
+func dotenvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errUnterminated
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errUnterminated
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	return raw, nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type dotenvDatabase struct {
+	Host     string `required:"true"`
+	Port     int    `default:"5432"`
+	User     string `split_words:"true"`
+	Password string
+}
+
+type dotenvSpecification struct {
+	Name string
+	DB   dotenvDatabase  `format:"dotenv"`
+	Opt  *dotenvDatabase `format:"dotenv" envconfig:"OPTIONAL"`
+}
+
+func TestDotenvFormat(t *testing.T) {
+	var s dotenvSpecification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DB", strings.Join([]string{
+		"# primary database",
+		"export HOST=db.local",
+		"USER=app # inline comment",
+		`PASSWORD="p#ss\nword"`,
+		"",
+	}, "\n"))
+	os.Setenv("ENV_CONFIG_OPTIONAL", "HOST='db #1'")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DB.Host != "db.local" {
+		t.Errorf("expected %q, got %q", "db.local", s.DB.Host)
+	}
+	if s.DB.Port != 5432 {
+		t.Errorf("expected %d, got %d", 5432, s.DB.Port)
+	}
+	if s.DB.User != "app" {
+		t.Errorf("expected %q, got %q", "app", s.DB.User)
+	}
+	if s.DB.Password != "p#ss\nword" {
+		t.Errorf("expected %q, got %q", "p#ss\nword", s.DB.Password)
+	}
+	if s.Opt == nil || s.Opt.Host != "db #1" {
+		t.Errorf("expected Opt.Host %q, got %+v", "db #1", s.Opt)
+	}
+}
+
+func TestDotenvFormatUnset(t *testing.T) {
+	var s struct {
+		DB struct {
+			Port int `default:"5432"`
+		} `format:"dotenv"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DB_PORT", "1")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DB.Port != 5432 {
+		t.Errorf("expected %d, got %d", 5432, s.DB.Port)
+	}
+}
+
+func TestDotenvFormatErrors(t *testing.T) {
+	var s dotenvSpecification
+	os.Clearenv()
+	err := Process("env_config", &s)
+	if err == nil || err.Error() != "envconfig.Process: ENV_CONFIG_DB: required key HOST missing value" {
+		t.Errorf("expected required key error, got %v", err)
+	}
+
+	os.Setenv("ENV_CONFIG_DB", "HOST=db\nPORT=eighty")
+	err = Process("env_config", &s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.KeyName != "PORT" {
+		t.Errorf("expected ParseError for PORT, got %v", err)
+	}
+
+	os.Setenv("ENV_CONFIG_DB", "HOST=db\nPORT")
+	err = Process("env_config", &s)
+	if err == nil || !strings.Contains(err.Error(), "line 2: missing '='") {
+		t.Errorf("expected syntax error, got %v", err)
+	}
+}
+
+type countedDatabase struct {
+	Host string
+}
+
+var countedValidations int
+
+func (d *countedDatabase) Validate() error {
+	countedValidations++
+	return nil
+}
+
+func TestDotenvFormatPolicies(t *testing.T) {
+	var s struct {
+		Port int
+		DB   countedDatabase `format:"dotenv"`
+	}
+	var inputs []PolicyInput
+	portSet := PolicyFunc(func(in PolicyInput) []PolicyViolation {
+		inputs = append(inputs, in)
+		if in.Vars["APP_PORT"] == "" {
+			return []PolicyViolation{{Policy: "port-set", Message: "APP_PORT is missing"}}
+		}
+		return nil
+	})
+	env := map[string]string{"APP_PORT": "80", "APP_DB": "HOST=db"}
+	countedValidations = 0
+	if err := ProcessMap("app", &s, env, Options{Validate: true, Policies: []Policy{portSet}}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(inputs) != 1 || countedValidations != 1 {
+		t.Errorf("expected one policy check and one validation, got %d and %d", len(inputs), countedValidations)
+	}
+}
+
+func TestProcessFile(t *testing.T) {
+	dir := t.TempDir()
+	local := dir + "/.env.local"
+	shared := dir + "/.env"
+	os.WriteFile(local, []byte("ENV_CONFIG_HOST=local\n"), 0600)
+	os.WriteFile(shared, []byte("# shared\nexport ENV_CONFIG_HOST=shared\nENV_CONFIG_PORT='6543'\nENV_CONFIG_USER=\"app user\" \n"), 0600)
+
+	var s dotenvDatabase
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_USER", "from-env")
+	if err := ProcessFile("env_config", &s, local, shared); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != "local" || s.Port != 6543 || s.User != "from-env" {
+		t.Errorf("unexpected values %+v", s)
+	}
+
+	os.WriteFile(shared, []byte("ENV_CONFIG_HOST=db\nENV_CONFIG_PASSWORD=\"-----BEGIN KEY-----\r\n  abc\n-----END KEY-----\"\nENV_CONFIG_USER='one\ntwo'\n"), 0600)
+	os.Clearenv()
+	s = dotenvDatabase{}
+	if err := ProcessFile("env_config", &s, shared); err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := "-----BEGIN KEY-----\n  abc\n-----END KEY-----"; s.Password != want || s.User != "one\ntwo" {
+		t.Errorf("expected multiline values, got %q and %q", s.Password, s.User)
+	}
+	os.WriteFile(shared, []byte("ENV_CONFIG_HOST=db\nENV_CONFIG_PASSWORD=\"open\nmore\n"), 0600)
+	if err := ProcessFile("env_config", &s, shared); err == nil || !strings.Contains(err.Error(), "line 2: unterminated quote") {
+		t.Errorf("expected an unterminated quote on line 2, got %v", err)
+	}
+
+	if err := ProcessFile("env_config", &s, dir+"/missing.env"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+	os.WriteFile(shared, []byte("ENV_CONFIG_HOST"), 0600)
+	if err := ProcessFile("env_config", &s, shared); err == nil || !strings.Contains(err.Error(), shared) {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+}
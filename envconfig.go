@@ -5,12 +5,15 @@
 package envconfig
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
-	"os"
+	"log"
 	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,6 +33,124 @@ type Options struct {
 	SplitWords         bool
 	Required           bool
 	ParallelExcecution bool
+
+	// SquashPrefixes drops words at the start of a nested key that repeat the
+	// end of its prefix, so a RedisHost field in a Redis struct maps to
+	// APP_REDIS_HOST rather than APP_REDIS_REDIS_HOST. Words are separated by
+	// underscores, so the field needs `split_words` or an `envconfig` tag
+	// like `redis_host`; REDISHOST is one word and is kept. The `squash` tag
+	// on a struct field enables or disables this for the fields inside it.
+	SquashPrefixes bool
+
+	// ConflictPolicy decides what happens when both the derived key and the
+	// `envconfig` alternate name are set to different values.
+	ConflictPolicy ConflictPolicy
+
+	// Lookuper, when not nil, replaces the process environment as the source
+	// of values. Use MultiLookuper to consult several sources in order.
+	Lookuper Lookuper
+
+	// OnLookup, when not nil, is called for every environment variable that
+	// is looked up, including misses on alternate names. Values are never
+	// passed to it. It may be called concurrently with ParallelExcecution.
+	OnLookup func(key string, found bool, source string)
+
+	// Registry, when not nil, records the keys of the specification and
+	// fails with a KeyCollisionError when another specification type has
+	// claimed one of them with a different type. See DefaultKeyRegistry.
+	Registry *KeyRegistry
+
+	// Result, when not nil, receives a summary of the variables processed.
+	Result *Result
+
+	// Prompt asks for missing required values on the terminal when standard
+	// input is interactive. Fields tagged `sensitive:"true"` are read with
+	// echo disabled.
+	Prompt bool
+
+	// TagNames, when set, reads tags under alternate keys, e.g. CaarlosTagNames.
+	TagNames TagNames
+
+	// Order selects the order of generated output. Variables are listed in
+	// declaration order by default.
+	Order Order
+
+	// MaxValueLen, when positive, is the longest value in bytes accepted for
+	// any variable. A field's `maxbytes` tag overrides it.
+	MaxValueLen int
+
+	// UTF8 selects the handling of values that are not valid UTF-8.
+	UTF8 UTF8Policy
+
+	// Normalize, when set, is applied to every value other than those of
+	// []byte fields, after UTF8. Pass norm.NFC.String from
+	// golang.org/x/text/unicode/norm to NFC-normalize values.
+	Normalize func(string) string
+
+	// AllowFileIndirection reads a variable that is not set from the file
+	// named by the variable with a _FILE suffix, as with Docker and
+	// Kubernetes secrets: DB_PASSWORD_FILE=/run/secrets/db. The `file` tag
+	// enables or disables this for a field.
+	AllowFileIndirection bool
+
+	// CollectErrors processes every variable even after one fails, and
+	// returns all the failures as a MultiError in declaration order.
+	CollectErrors bool
+
+	// Delimiter separates the items of slice, set and map values, and
+	// MapDelimiter separates map keys from their values. They default to ","
+	// and ":" and are overridden for a field by the `delimiter` and
+	// `map_delimiter` tags, e.g. `map_delimiter:"="` for map values that are
+	// URLs with ports.
+	Delimiter    string
+	MapDelimiter string
+
+	// Validate calls the Validate methods of the specification and its
+	// nested structs once it is populated, and returns the first failure as
+	// a ValidationError.
+	Validate bool
+
+	// ExpandEnv substitutes references to other variables, $NAME or ${NAME},
+	// in values before they are decoded:
+	// DATABASE_URL=postgres://$DB_HOST:$DB_PORT/app. Referenced values are
+	// expanded in turn, $$ stands for a literal $ and a variable that refers
+	// back to itself is an error. The `expand` tag enables or disables this
+	// for a field, and its default is expanded the same way.
+	ExpandEnv bool
+
+	// ExpandDefaults substitutes ${NAME} references in defaults:
+	// `default:"${HOME}/data"`. A variable that is not set resolves to the
+	// default of the field with that key, and to "" without one; $${ stands
+	// for a literal ${.
+	ExpandDefaults bool
+
+	// Profile selects defaults for a deployment environment: a field tagged
+	// `default:"info" default_prod:"warn"` defaults to "warn" when Profile is
+	// "prod".
+	Profile string
+
+	// Policies are checked against the resolved configuration once the
+	// Validate methods have passed, and their violations returned as a
+	// PolicyError.
+	Policies []Policy
+
+	// fields holds the variables of the specification being processed by
+	// key, to resolve references to them in defaults.
+	fields map[string]varInfo
+
+	// fromValues gathers the entries of slices and maps of structs from the
+	// values they hold rather than from the variables that are set, to
+	// write a specification out.
+	fromValues bool
+
+	// placeholders gathers the variables of one element of each slice and
+	// map of structs under a placeholder, APP_ENDPOINTS_{N}_HOST or
+	// APP_UPSTREAMS_{NAME}_URL, to describe the variables an element has.
+	placeholders bool
+
+	// ctx is the context of ProcessContext, checked before each variable is
+	// processed and passed to ContextLookupers.
+	ctx context.Context
 }
 
 // A ParseError occurs when an environment variable cannot be converted to
@@ -40,6 +161,88 @@ type ParseError struct {
 	TypeName  string
 	Value     string
 	Err       error
+
+	// Description is the `desc` tag of the field.
+	Description string
+}
+
+// A RequiredError occurs when a required variable is not set.
+type RequiredError struct {
+	KeyName   string
+	FieldName string
+
+	// Description is the `desc` tag of the field.
+	Description string
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("required key %s missing value", describeKey(e.KeyName, e.Description))
+}
+
+// A MultiError lists every variable that failed to process when
+// Options.CollectErrors or Options.ParallelExcecution is set.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors:\n\t%s", len(e), strings.Join(msgs, "\n\t"))
+}
+
+// Unwrap returns the errors, so errors.Is and errors.As look at each of them
+// from Go 1.20.
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any of the errors matches target, for errors.Is before
+// Go 1.20, which does not call Unwrap() []error.
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target and sets target to
+// it, for errors.As before Go 1.20.
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConflictPolicy controls the handling of a variable that is set under both
+// its derived key and its alternate name with different values.
+type ConflictPolicy int
+
+const (
+	// ConflictPreferKey silently uses the value of the derived key.
+	ConflictPreferKey ConflictPolicy = iota
+	// ConflictWarn uses the value of the derived key and logs a ConflictError.
+	ConflictWarn
+	// ConflictFail fails processing with a ConflictError.
+	ConflictFail
+)
+
+// A ConflictError occurs when a variable is set under both its derived key
+// and its alternate name with different values. The values are left out of the
+// message as either may be sensitive.
+type ConflictError struct {
+	Key       string
+	Alt       string
+	FieldName string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("envconfig.Process: %s and %s are both set for %s with different values", e.Key, e.Alt, e.FieldName)
 }
 
 // Decoder has the same semantics as Setter, but takes higher precedence.
@@ -55,16 +258,86 @@ type Setter interface {
 }
 
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", e.KeyName, e.FieldName, e.Value, e.TypeName, e.Err)
+	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", describeKey(e.KeyName, e.Description), e.FieldName, e.Value, e.TypeName, e.Err)
+}
+
+// describeKey appends the `desc` tag, if any, to the key in error messages.
+func describeKey(key, desc string) string {
+	if desc == "" {
+		return key
+	}
+	return fmt.Sprintf("%s (%s)", key, desc)
 }
 
 // varInfo maintains information about the configuration variable
 type varInfo struct {
 	Name  string
+	Path  string
 	Alt   string
 	Key   string
 	Field reflect.Value
 	Tags  reflect.StructTag
+
+	// Profile is Options.Profile, which selects `default_<profile>` tags.
+	Profile string
+
+	// Delimiter and MapDelimiter separate the items of slices, sets and maps,
+	// and the keys of maps from their values.
+	Delimiter, MapDelimiter string
+
+	// commit, when set, stores the map entry holding the field into its map
+	// once processing is done.
+	commit func()
+}
+
+// aliases returns the fallback names of the `alt` tag, e.g.
+// `alt:"OLD_NAME,LEGACY_NAME"`, in the order they are consulted. They are
+// used as they are, without the prefix.
+func (info varInfo) aliases() []string {
+	var names []string
+	for _, name := range strings.Split(info.Tags.Get("alt"), ",") {
+		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// goos selects the `default_<os>` tag consulted by defaultValue.
+var goos = runtime.GOOS
+
+// defaultValue returns the default for the variable, preferring a tag
+// qualified with the profile (e.g. `default_prod`), then one qualified with
+// the current operating system (e.g. `default_linux`), over the plain
+// `default` tag.
+func (info varInfo) defaultValue() string {
+	if info.Profile != "" {
+		if def := info.Tags.Get("default_" + info.Profile); def != "" {
+			return def
+		}
+	}
+	if def := info.Tags.Get("default_" + goos); def != "" {
+		return def
+	}
+	return info.Tags.Get("default")
+}
+
+var sensitiveValueType = reflect.TypeOf((*sensitiveValue)(nil)).Elem()
+
+// isSensitive reports whether the value of the variable must not be shown,
+// because the field is tagged `sensitive:"true"` or its type is always
+// sensitive, like PEMPrivateKey.
+func (info varInfo) isSensitive() bool {
+	t := info.Field.Type()
+	return isTrue(info.Tags.Get("sensitive")) || t.Implements(sensitiveValueType) || reflect.PtrTo(t).Implements(sensitiveValueType)
+}
+
+// shownValue returns value, or a placeholder when the variable is sensitive.
+func (info varInfo) shownValue(value string) string {
+	if info.isSensitive() {
+		return "<sensitive>"
+	}
+	return value
 }
 
 // GatherInfo gathers information about the specified struct
@@ -85,7 +358,8 @@ func gatherInfo(prefix string, spec interface{}, options Options) ([]varInfo, er
 	for i := 0; i < s.NumField(); i++ {
 		f := s.Field(i)
 		ftype := typeOfSpec.Field(i)
-		if !f.CanSet() || isTrue(ftype.Tag.Get("ignored")) {
+		tag := options.TagNames.rewrite(ftype.Tag)
+		if !f.CanSet() || isTrue(tag.Get("ignored")) || tag.Get("derive") != "" {
 			continue
 		}
 
@@ -103,55 +377,78 @@ func gatherInfo(prefix string, spec interface{}, options Options) ([]varInfo, er
 
 		// Capture information about the config variable
 		info := varInfo{
-			Name:  ftype.Name,
-			Field: f,
-			Tags:  ftype.Tag,
-			Alt:   strings.ToUpper(ftype.Tag.Get("envconfig")),
+			Name:    ftype.Name,
+			Path:    ftype.Name,
+			Field:   f,
+			Tags:    tag,
+			Profile: options.Profile,
+			Alt:     strings.ToUpper(tag.Get("envconfig")),
 		}
 
+		info.Delimiter = firstNonEmpty(tag.Get("delimiter"), options.Delimiter, ",")
+		info.MapDelimiter = firstNonEmpty(tag.Get("map_delimiter"), options.MapDelimiter, ":")
+
 		// Default to the field name as the env var name (will be upcased)
 		info.Key = info.Name
 
-		tagSplitWords := ftype.Tag.Get("split_words")
+		tagSplitWords := tag.Get("split_words")
 
 		// Best effort to un-pick camel casing as separate words
 		if isTrue(tagSplitWords) || options.SplitWords && !isFalse(tagSplitWords) {
-			words := gatherRegexp.FindAllStringSubmatch(ftype.Name, -1)
-			if len(words) > 0 {
-				var name []string
-				for _, words := range words {
-					if m := acronymRegexp.FindStringSubmatch(words[0]); len(m) == 3 {
-						name = append(name, m[1], m[2])
-					} else {
-						name = append(name, words[0])
-					}
-				}
-
-				info.Key = strings.Join(name, "_")
-			}
+			info.Key = splitWords(ftype.Name)
 		}
 		if info.Alt != "" {
 			info.Key = info.Alt
 		}
-		if prefix != "" {
-			info.Key = fmt.Sprintf("%s_%s", prefix, info.Key)
+		if prefix != "" && !isTrue(tag.Get("absolute")) {
+			if options.SquashPrefixes {
+				info.Key = fmt.Sprintf("%s_%s", prefix, squashKey(prefix, info.Key))
+			} else {
+				info.Key = fmt.Sprintf("%s_%s", prefix, info.Key)
+			}
 		}
 		info.Key = strings.ToUpper(info.Key)
 		infos = append(infos, info)
 
-		if f.Kind() == reflect.Struct {
+		if info.isJSON() {
+			continue
+		}
+		if f.Kind() == reflect.Map && isStructMap(f.Type()) {
+			elemInfos, err := gatherStructMap(info, options)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos[:len(infos)-1], elemInfos...)
+			continue
+		}
+		if f.Kind() == reflect.Slice && isStructSlice(f.Type()) {
+			elemInfos, err := gatherStructSlice(info, options)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos[:len(infos)-1], elemInfos...)
+			continue
+		}
+		if f.Kind() == reflect.Struct && !info.isDotenv() {
 			// honor Decode if present
-			if decoderFrom(f) == nil && setterFrom(f) == nil && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil {
+			if !isDecodable(f) {
 				innerPrefix := prefix
 				if !ftype.Anonymous {
 					innerPrefix = info.Key
 				}
 
+				innerOptions := options
+				tagSquash := tag.Get("squash")
+				innerOptions.SquashPrefixes = isTrue(tagSquash) || options.SquashPrefixes && !isFalse(tagSquash)
+
 				embeddedPtr := f.Addr().Interface()
-				embeddedInfos, err := gatherInfo(innerPrefix, embeddedPtr, options)
+				embeddedInfos, err := gatherInfo(innerPrefix, embeddedPtr, innerOptions)
 				if err != nil {
 					return nil, err
 				}
+				for j := range embeddedInfos {
+					embeddedInfos[j].Path = ftype.Name + "." + embeddedInfos[j].Path
+				}
 				infos = append(infos[:len(infos)-1], embeddedInfos...)
 
 				continue
@@ -161,6 +458,39 @@ func gatherInfo(prefix string, spec interface{}, options Options) ([]varInfo, er
 	return infos, nil
 }
 
+// splitWords joins the camel cased words of name with underscores.
+func splitWords(name string) string {
+	words := gatherRegexp.FindAllStringSubmatch(name, -1)
+	if len(words) == 0 {
+		return name
+	}
+	var parts []string
+	for _, words := range words {
+		if m := acronymRegexp.FindStringSubmatch(words[0]); len(m) == 3 {
+			parts = append(parts, m[1], m[2])
+		} else {
+			parts = append(parts, words[0])
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// squashKey removes the longest run of words at the start of key that repeats
+// the words at the end of prefix. At least one word of key is always kept.
+func squashKey(prefix, key string) string {
+	prefixWords := strings.Split(strings.ToUpper(prefix), "_")
+	keyWords := strings.Split(key, "_")
+	for n := len(keyWords) - 1; n > 0; n-- {
+		if n > len(prefixWords) {
+			continue
+		}
+		if strings.ToUpper(strings.Join(keyWords[:n], "_")) == strings.Join(prefixWords[len(prefixWords)-n:], "_") {
+			return strings.Join(keyWords[n:], "_")
+		}
+	}
+	return key
+}
+
 // CheckDisallowed checks that no environment variables with the prefix are set
 // that we don't know how or want to parse. This is likely only meaningful with
 // a non-empty prefix.
@@ -168,7 +498,9 @@ func CheckDisallowed(prefix string, spec interface{}) error {
 	return CheckDisallowedWithOptions(prefix, spec, Options{})
 }
 
-// CheckDisallowedWithOptions is like CheckDisallowed() but with specified options.
+// CheckDisallowedWithOptions is like CheckDisallowed() but with specified
+// options. The variables checked are those Options.Lookuper lists, so a
+// Lookuper that does not implement KeyLister has none to check.
 func CheckDisallowedWithOptions(prefix string, spec interface{}, options Options) error {
 	infos, err := gatherInfo(prefix, spec, options)
 	if err != nil {
@@ -178,17 +510,27 @@ func CheckDisallowedWithOptions(prefix string, spec interface{}, options Options
 	vars := make(map[string]struct{})
 	for _, info := range infos {
 		vars[info.Key] = struct{}{}
+		if info.Alt != "" {
+			vars[info.Alt] = struct{}{}
+		}
+		for _, alias := range info.aliases() {
+			vars[alias] = struct{}{}
+		}
+		if info.fileIndirection(options) {
+			for _, key := range info.fileKeys() {
+				vars[key] = struct{}{}
+			}
+		}
 	}
 
-	if prefix != "" {
-		prefix = strings.ToUpper(prefix) + "_"
-	}
+	prefix = envPrefix(prefix)
 
-	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, prefix) {
+	keys := append([]string(nil), options.keys()...)
+	sort.Strings(keys)
+	for _, v := range keys {
+		if !strings.HasPrefix(v, prefix) {
 			continue
 		}
-		v := strings.SplitN(env, "=", 2)[0]
 		if _, found := vars[v]; !found {
 			return fmt.Errorf("unknown environment variable %s", v)
 		}
@@ -204,90 +546,398 @@ func Process(prefix string, spec interface{}) error {
 
 // ProcessWithOptions is like Process() but with specified options.
 func ProcessWithOptions(prefix string, spec interface{}, options Options) error {
+	if options.Result != nil {
+		*options.Result = Result{}
+		defer func(start time.Time) { options.Result.Duration = time.Since(start) }(time.Now())
+	}
+
 	infos, err := gatherInfo(prefix, spec, options)
 	if err != nil {
 		return err
 	}
+	options = options.withFields(infos)
+
+	if options.Registry != nil {
+		if err := options.Registry.claim(spec, infos); err != nil {
+			return err
+		}
+	}
 
 	if options.ParallelExcecution {
 		var wg sync.WaitGroup
-		errCh := make(chan error, len(infos))
+		errs := make([]error, len(infos))
 
-		for _, info := range infos {
+		for i, info := range infos {
 			wg.Add(1)
 
-			go func(info varInfo) {
+			go func(i int, info varInfo) {
 				defer wg.Done()
-				errCh <- processInfo(info, options)
-			}(info)
+				if errs[i] = options.canceled(); errs[i] == nil {
+					errs[i] = processInfo(info, options)
+				}
+			}(i, info)
 		}
 
 		wg.Wait()
-		close(errCh)
+		if err := options.canceled(); err != nil {
+			return err
+		}
 
 		var allErrs []error
-		for e := range errCh {
+		for _, e := range errs {
 			if e != nil {
 				allErrs = append(allErrs, e)
 			}
 		}
 
 		if len(allErrs) > 0 {
-			return fmt.Errorf("multiple errors: %v", allErrs)
+			return MultiError(allErrs)
 		}
-
-		return nil
 	} else {
+		var allErrs []error
 		for _, info := range infos {
-			err := processInfo(info, options)
-			if err != nil {
+			if err := options.canceled(); err != nil {
 				return err
 			}
+			if err := processInfo(info, options); err != nil {
+				if cerr := options.canceled(); cerr != nil {
+					return cerr
+				}
+				if !options.CollectErrors {
+					return err
+				}
+				allErrs = append(allErrs, err)
+			}
+		}
+		if err := options.canceled(); err != nil {
+			return err
+		}
+		if len(allErrs) > 0 {
+			return MultiError(allErrs)
+		}
+	}
+
+	commitInfos(infos)
+	if err := checkSamplingGroups(infos); err != nil {
+		return err
+	}
+	if err := deriveFields(spec); err != nil {
+		return err
+	}
+	if options.Validate {
+		if err := validateSpec(spec, options.TagNames); err != nil {
+			return err
 		}
-		return nil
 	}
+	return checkPolicies(prefix, spec, options)
+}
+
+// keys lists the variables of the source, or returns nil if the Lookuper
+// cannot list them.
+func (options Options) keys() []string {
+	if options.Lookuper == nil {
+		return environKeys()
+	}
+	if kl, ok := options.Lookuper.(KeyLister); ok {
+		return kl.Keys()
+	}
+	return nil
+}
+
+// lookup reads key from Options.Lookuper, or the environment when it is nil,
+// and reports the attempt to OnLookup.
+func (options Options) lookup(key string) (string, bool) {
+	value, ok, _ := options.lookupErr(key)
+	return value, ok
+}
+
+// lookupErr is like lookup but also returns the error of an ErrorLookuper.
+func (options Options) lookupErr(key string) (string, bool, error) {
+	if options.Lookuper == nil {
+		value, ok := lookupEnv(key)
+		if options.OnLookup != nil {
+			options.OnLookup(key, ok, "env")
+		}
+		return value, ok, nil
+	}
+
+	value, ok, err := lookupWith(options.ctx, options.Lookuper, key)
+	if options.OnLookup != nil {
+		options.OnLookup(key, ok, sourceName(options.Lookuper))
+	}
+	return value, ok, err
+}
+
+// lookupError reports the failed lookup of key for the field of info.
+func lookupError(info varInfo, key string, err error) error {
+	return fmt.Errorf("envconfig.Process: looking up %s for %s: %w", key, info.Name, err)
 }
 
 func processInfo(info varInfo, options Options) error {
-	// `os.Getenv` cannot differentiate between an explicitly set empty value
-	// and an unset value. `os.LookupEnv` is preferred to `syscall.Getenv`,
-	// but it is only available in go1.5 or newer. We're using Go build tags
-	// here to use os.LookupEnv for >=go1.5
+	source := sourceEnv
+	value, ok, err := options.lookupErr(info.Key)
+	if err != nil {
+		return lookupError(info, info.Key, err)
+	}
+	if info.Alt != "" && info.Alt != info.Key {
+		altValue, altOk, err := options.lookupErr(info.Alt)
+		if err != nil {
+			return lookupError(info, info.Alt, err)
+		}
+		if !ok {
+			value, ok = altValue, altOk
+			source = sourceAlt
+		} else if altOk && altValue != value && options.ConflictPolicy != ConflictPreferKey {
+			err := &ConflictError{Key: info.Key, Alt: info.Alt, FieldName: info.Name}
+			if options.ConflictPolicy == ConflictFail {
+				return err
+			}
+			log.Print(err)
+		}
+	}
+	for _, alias := range info.aliases() {
+		if ok {
+			break
+		}
+		if value, ok, err = options.lookupErr(alias); err != nil {
+			return lookupError(info, alias, err)
+		}
+		source = sourceAlt
+	}
 
-	value, ok := lookupEnv(info.Key)
-	if !ok && info.Alt != "" {
-		value, ok = lookupEnv(info.Alt)
+	if info.fileIndirection(options) {
+		fileValue, fileKey, fileOk, err := info.lookupFile(options)
+		if err != nil {
+			return err
+		}
+		if fileOk && ok {
+			return fmt.Errorf("envconfig.Process: %s and %s are both set for %s", info.Key, fileKey, info.Name)
+		}
+		if fileOk {
+			value, ok, source = fileValue, true, sourceSecretStore
+		}
 	}
 
-	def := info.Tags.Get("default")
+	def := info.defaultValue()
 	if def != "" && !ok {
-		value = def
+		source = sourceDefault
+		if value, err = info.resolveDefault(options); err != nil {
+			return err
+		}
 	}
 
 	req := info.Tags.Get("required")
 	if !ok && def == "" {
-		if isTrue(req) || (options.Required && !isFalse(req)) {
+		if !isTrue(req) && !(options.Required && !isFalse(req)) {
+			if info.isDotenv() {
+				if err := info.assignDotenv("", options); err != nil {
+					return err
+				}
+			}
+			options.Result.record(sourceSkipped)
+			return nil
+		}
+		if options.Prompt {
+			value, ok = promptFor(info)
+			source = sourceEnv
+		}
+		if !ok {
 			key := info.Key
 			if info.Alt != "" {
 				key = info.Alt
 			}
-			return fmt.Errorf("required key %s missing value", key)
+			return &RequiredError{KeyName: key, FieldName: info.Name, Description: info.Tags.Get("desc")}
 		}
-		return nil
 	}
 
-	if err := processField(value, info.Field); err != nil {
+	if source != sourceDefault && info.expansion(options) {
+		if value, err = info.expandValue(value, options); err != nil {
+			return err
+		}
+	}
+	if err := info.checkLength(value, options); err != nil {
+		return err
+	}
+	if value, err = info.cleanText(value, options); err != nil {
+		return err
+	}
+	if info.isDotenv() {
+		err = info.assignDotenv(value, options)
+	} else {
+		err = info.assign(value, options)
+	}
+	if err != nil {
+		return err
+	}
+	options.Result.record(source)
+	return nil
+}
+
+// resolveDefault returns the default for the variable with references to
+// other variables and providers expanded and, for numeric fields,
+// expressions evaluated.
+func (info varInfo) resolveDefault(options Options) (string, error) {
+	return info.resolveDefaultRefs(options, []string{info.Key})
+}
+
+// computedDefault returns what the default of the variable resolves to, and
+// whether that differs from the default as written because it has
+// references, providers or an expression. A default that does not resolve
+// counts as computed too.
+func (info varInfo) computedDefault(options Options) (string, bool) {
+	def := info.defaultValue()
+	value, err := info.resolveDefault(options)
+	if err != nil {
+		return "", true
+	}
+	return value, value != def
+}
+
+func (info varInfo) resolveDefaultRefs(options Options, stack []string) (string, error) {
+	def := info.defaultValue()
+	value := def
+	var err error
+	if full := info.expansion(options); full || options.ExpandDefaults {
+		value, err = expandRefs(def, options, full, stack)
+	}
+	if err == nil {
+		value, err = expandProviders(value)
+	}
+	if err == nil && isNumericField(info.Field) {
+		// a grouped number such as 1,000 is not an expression
+		n := lenientNumber(value, info.Field)
+		if _, perr := strconv.ParseFloat(n, 64); perr == nil && isTrue(info.Tags.Get("lenient_numbers")) {
+			value = n
+		} else {
+			value, err = evalDefault(value, info.Field)
+		}
+	}
+	if err != nil {
+		return "", &ParseError{
+			KeyName:     info.Key,
+			FieldName:   info.Name,
+			TypeName:    info.Field.Type().String(),
+			Value:       info.shownValue(def),
+			Err:         err,
+			Description: info.Tags.Get("desc"),
+		}
+	}
+	return value, nil
+}
+
+// assign decodes value into the field, applying the tags that adjust the
+// value before and after decoding.
+func (info varInfo) assign(value string, options Options) error {
+	var err error
+	if names := info.Tags.Get("transform"); names != "" {
+		value, err = info.transform(value, names, options)
+	}
+
+	if err == nil && isTrue(info.Tags.Get("lenient_numbers")) {
+		value = lenientNumber(value, info.Field)
+	}
+
+	if unit := info.Tags.Get("unit"); err == nil && unit != "" {
+		value, err = applyUnit(value, unit, info.Field)
+	}
+	if err == nil && info.isJSON() {
+		err = decodeJSON(value, info.Field)
+	} else if err == nil {
+		err = processFieldSep(value, info.Field, info.Delimiter, info.MapDelimiter)
+	}
+	if err == nil {
+		err = info.checkConstraints(value)
+	}
+	if err == nil && isTrue(info.Tags.Get("exists")) {
+		err = checkExists(info.Field)
+	}
+	if err == nil {
+		unique, sorted := isTrue(info.Tags.Get("unique")), isTrue(info.Tags.Get("sorted"))
+		if unique || sorted {
+			err = normalizeSlice(info.Field, unique, sorted)
+		}
+	}
+	if err != nil {
 		return &ParseError{
-			KeyName:   info.Key,
-			FieldName: info.Name,
-			TypeName:  info.Field.Type().String(),
-			Value:     value,
-			Err:       err,
+			KeyName:     info.Key,
+			FieldName:   info.Name,
+			TypeName:    info.Field.Type().String(),
+			Value:       info.shownValue(value),
+			Err:         err,
+			Description: info.Tags.Get("desc"),
 		}
 	}
 	return nil
 }
 
+// ApplyDefaults populates the fields of spec that have a `default` tag with
+// that default, without consulting the environment. Fields without a default
+// are left unchanged and required fields are not checked. With
+// Options.ExpandDefaults, a reference in a default, such as ${HOME}, resolves
+// only to the default of another field.
+func ApplyDefaults(spec interface{}) error {
+	return ApplyDefaultsWithOptions(spec, Options{})
+}
+
+// ApplyDefaultsWithOptions is like ApplyDefaults() but with specified options.
+func ApplyDefaultsWithOptions(spec interface{}, options Options) error {
+	infos, err := gatherInfo("", spec, options)
+	if err != nil {
+		return err
+	}
+	options = options.withFields(infos)
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	for _, info := range infos {
+		if info.defaultValue() == "" {
+			continue
+		}
+		value, err := info.resolveDefault(options)
+		if err != nil {
+			return err
+		}
+		if err := info.assign(value, options); err != nil {
+			return err
+		}
+	}
+	commitInfos(infos)
+	return nil
+}
+
+// Reset sets every field of spec that envconfig manages back to its zero
+// value and then applies the `default` tags. Values behind non-nil pointers are
+// zeroed in place. Ignored fields are untouched.
+func Reset(spec interface{}) error {
+	return ResetWithOptions(spec, Options{})
+}
+
+// ResetWithOptions is like Reset() but with specified options.
+func ResetWithOptions(spec interface{}, options Options) error {
+	infos, err := gatherInfo("", spec, options)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		info.Field.Set(reflect.Zero(info.Field.Type()))
+	}
+	commitInfos(infos)
+	return ApplyDefaultsWithOptions(spec, options)
+}
+
+// ProcessAs processes a new T, so the specification need not be declared
+// first: cfg, err := envconfig.ProcessAs[Config]("myapp").
+func ProcessAs[T any](prefix string) (*T, error) {
+	return ProcessAsWithOptions[T](prefix, Options{})
+}
+
+// ProcessAsWithOptions is like ProcessAs() but with specified options.
+func ProcessAsWithOptions[T any](prefix string, options Options) (*T, error) {
+	spec := new(T)
+	if err := ProcessWithOptions(prefix, spec, options); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
 // MustProcess is the same as Process but panics if an error occurs
 func MustProcess(prefix string, spec interface{}) {
 	MustProcessWithOptions(prefix, spec, Options{})
@@ -301,12 +951,42 @@ func MustProcessWithOptions(prefix string, spec interface{}, options Options) {
 }
 
 func processField(value string, field reflect.Value) error {
+	return processFieldSep(value, field, ",", ":")
+}
+
+// processFieldSep is processField with sep between the items of slices, sets
+// and maps and kvSep between map keys and values.
+func processFieldSep(value string, field reflect.Value, sep, kvSep string) error {
 	typ := field.Type()
 
+	// allocate nil pointers up front so custom decoders get a usable receiver
+	if typ.Kind() == reflect.Ptr && field.IsNil() {
+		field.Set(reflect.New(typ.Elem()))
+	}
+
+	if ok, err := decodeRegistered(value, field); ok {
+		return err
+	}
+	if typ.Kind() == reflect.Ptr {
+		if ok, err := decodeRegistered(value, field.Elem()); ok {
+			return err
+		}
+	}
+
+	if d := sepDecoderFrom(field); d != nil {
+		return d.decodeSep(value, sep, kvSep)
+	}
 	decoder := decoderFrom(field)
 	if decoder != nil {
 		return decoder.Decode(value)
 	}
+
+	if typ.Kind() == reflect.Ptr && isStdlibType(typ.Elem()) {
+		return decodeStdlib(value, field.Elem())
+	}
+	if isStdlibType(typ) {
+		return decodeStdlib(value, field)
+	}
 	// look for Set method if Decode not defined
 	setter := setterFrom(field)
 	if setter != nil {
@@ -372,7 +1052,7 @@ func processField(value string, field reflect.Value) error {
 		if typ.Elem().Kind() == reflect.Uint8 {
 			sl = reflect.ValueOf([]byte(value))
 		} else if len(strings.TrimSpace(value)) != 0 {
-			vals := strings.Split(value, ",")
+			vals := strings.Split(value, sep)
 			sl = reflect.MakeSlice(typ, len(vals), len(vals))
 			for i, val := range vals {
 				err := processField(val, sl.Index(i))
@@ -384,10 +1064,21 @@ func processField(value string, field reflect.Value) error {
 		field.Set(sl)
 	case reflect.Map:
 		mp := reflect.MakeMap(typ)
-		if len(strings.TrimSpace(value)) != 0 {
-			pairs := strings.Split(value, ",")
+		if isSetType(typ) {
+			if len(strings.TrimSpace(value)) != 0 {
+				member := reflect.New(typ.Elem()).Elem()
+				for _, val := range strings.Split(value, sep) {
+					k := reflect.New(typ.Key()).Elem()
+					if err := processField(val, k); err != nil {
+						return err
+					}
+					mp.SetMapIndex(k, member)
+				}
+			}
+		} else if len(strings.TrimSpace(value)) != 0 {
+			pairs := strings.Split(value, sep)
 			for _, pair := range pairs {
-				kvpair := strings.Split(pair, ":")
+				kvpair := strings.Split(pair, kvSep)
 				if len(kvpair) != 2 {
 					return fmt.Errorf("invalid map item: %q", pair)
 				}
@@ -427,6 +1118,17 @@ func decoderFrom(field reflect.Value) (d Decoder) {
 	return d
 }
 
+// sepDecoder is implemented by the package's container types, such as
+// OrderedMap, that split values with the delimiters of the variable.
+type sepDecoder interface {
+	decodeSep(value, sep, kvSep string) error
+}
+
+func sepDecoderFrom(field reflect.Value) (d sepDecoder) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { d, *ok = v.(sepDecoder) })
+	return d
+}
+
 func setterFrom(field reflect.Value) (s Setter) {
 	interfaceFrom(field, func(v interface{}, ok *bool) { s, *ok = v.(Setter) })
 	return s
@@ -455,3 +1157,12 @@ func isFalse(s string) bool {
 
 	return !b
 }
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
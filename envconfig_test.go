@@ -5,11 +5,16 @@
 package envconfig
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -436,6 +441,27 @@ func TestParseErrorBool(t *testing.T) {
 	}
 }
 
+func TestErrorDescriptions(t *testing.T) {
+	var s struct {
+		APIKey  string `split_words:"true" required:"true" desc:"API key for the payments provider"`
+		Retries int    `desc:"attempts per request"`
+	}
+	os.Clearenv()
+	err := Process("payments", &s)
+	if err == nil || err.Error() != "required key PAYMENTS_API_KEY (API key for the payments provider) missing value" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	os.Setenv("PAYMENTS_API_KEY", "key")
+	os.Setenv("PAYMENTS_RETRIES", "many")
+	err = Process("payments", &s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Description != "attempts per request" ||
+		!strings.Contains(err.Error(), "assigning PAYMENTS_RETRIES (attempts per request) to Retries") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 func TestParseErrorFloat32(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -559,6 +585,273 @@ func TestAlternateVarNames(t *testing.T) {
 	}
 }
 
+func TestAlternateVarNameConflict(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+	os.Setenv("ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT", "derived")
+	os.Setenv("MULTI_WORD_VAR_WITH_ALT", "alternate")
+
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.MultiWordVarWithAlt != "derived" {
+		t.Errorf("expected %q, got %q", "derived", s.MultiWordVarWithAlt)
+	}
+
+	err := ProcessWithOptions("env_config", &s, Options{ConflictPolicy: ConflictFail})
+	v, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected ConflictError, got %T %v", err, err)
+	}
+	if v.Key != "ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT" || v.Alt != "MULTI_WORD_VAR_WITH_ALT" {
+		t.Errorf("unexpected conflict keys %q and %q", v.Key, v.Alt)
+	}
+	if strings.Contains(err.Error(), "derived") || strings.Contains(err.Error(), "alternate") {
+		t.Errorf("conflict error leaks values: %v", err)
+	}
+
+	os.Setenv("MULTI_WORD_VAR_WITH_ALT", "derived")
+	if err := ProcessWithOptions("env_config", &s, Options{ConflictPolicy: ConflictFail}); err != nil {
+		t.Errorf("expected no error for equal values, got %v", err)
+	}
+}
+
+func TestAlternateVarNameConflictWarn(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+	os.Setenv("ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT", "derived")
+	os.Setenv("MULTI_WORD_VAR_WITH_ALT", "alternate")
+
+	buf := new(bytes.Buffer)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	if err := ProcessWithOptions("env_config", &s, Options{ConflictPolicy: ConflictWarn}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.MultiWordVarWithAlt != "derived" {
+		t.Errorf("expected %q, got %q", "derived", s.MultiWordVarWithAlt)
+	}
+	if !strings.Contains(buf.String(), "ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT and MULTI_WORD_VAR_WITH_ALT are both set") {
+		t.Errorf("expected conflict warning, got %q", buf.String())
+	}
+}
+
+func TestAliases(t *testing.T) {
+	var s struct {
+		DatabaseURL string `split_words:"true" alt:"OLD_DB_URL, legacy_db_url" required:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("LEGACY_DB_URL", "legacy")
+	var result Result
+	if err := ProcessWithOptions("app", &s, Options{Result: &result}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DatabaseURL != "legacy" || result.FromAlt != 1 {
+		t.Errorf("expected the legacy value from an alias, got %q and %+v", s.DatabaseURL, result)
+	}
+
+	os.Setenv("OLD_DB_URL", "old")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DatabaseURL != "old" {
+		t.Errorf("expected aliases to be consulted in order, got %q", s.DatabaseURL)
+	}
+
+	os.Setenv("APP_DATABASE_URL", "new")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DatabaseURL != "new" {
+		t.Errorf("expected the key to win over aliases, got %q", s.DatabaseURL)
+	}
+
+	os.Setenv("APP_OLD_URL", "older")
+	var prefixed struct {
+		DatabaseURL string `split_words:"true" alt:"APP_OLD_URL"`
+	}
+	if err := CheckDisallowed("app", &prefixed); err != nil {
+		t.Errorf("expected an alias under the prefix to be allowed, got %v", err)
+	}
+}
+
+func TestOnLookup(t *testing.T) {
+	var s struct {
+		Host string `envconfig:"SERVICE_HOST"`
+		Port int
+	}
+	os.Clearenv()
+	os.Setenv("SERVICE_HOST", "127.0.0.1")
+
+	var lookups []string
+	onLookup := func(key string, found bool, source string) {
+		lookups = append(lookups, fmt.Sprintf("%s %v %s", key, found, source))
+	}
+	if err := ProcessWithOptions("env_config", &s, Options{OnLookup: onLookup}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	want := []string{
+		"ENV_CONFIG_SERVICE_HOST false env",
+		"SERVICE_HOST true env",
+		"ENV_CONFIG_PORT false env",
+	}
+	if strings.Join(lookups, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected lookups:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(lookups, "\n"))
+	}
+}
+
+func TestLookuper(t *testing.T) {
+	var s struct {
+		Host string
+		Port int
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "from-env")
+	os.Setenv("ENV_CONFIG_PORT", "1")
+
+	fallback := LookuperFunc(func(key string) (string, bool) {
+		if key == "ENV_CONFIG_PORT" || key == "ENV_CONFIG_HOST" {
+			return map[string]string{"ENV_CONFIG_PORT": "8080", "ENV_CONFIG_HOST": "from-fallback"}[key], true
+		}
+		return "", false
+	})
+	var sources []string
+	options := Options{
+		Lookuper: fallback,
+		OnLookup: func(key string, found bool, source string) { sources = append(sources, source) },
+	}
+	if err := ProcessWithOptions("env_config", &s, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != "from-fallback" || s.Port != 8080 {
+		t.Errorf("expected values from the lookuper, got %+v", s)
+	}
+	if sources[0] != "envconfig.LookuperFunc" {
+		t.Errorf("expected source %q, got %q", "envconfig.LookuperFunc", sources[0])
+	}
+
+	os.Unsetenv("ENV_CONFIG_PORT")
+	options.Lookuper = MultiLookuper(OSLookuper(), fallback)
+	if err := ProcessWithOptions("env_config", &s, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != "from-env" || s.Port != 8080 {
+		t.Errorf("expected host from env and port from fallback, got %+v", s)
+	}
+}
+
+func TestProcessWithLookuper(t *testing.T) {
+	var s struct {
+		Host string
+		Port int
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "from-env")
+	if err := ProcessWithLookuper("env_config", &s, MapLookuper{"ENV_CONFIG_PORT": "8080"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != "" || s.Port != 8080 {
+		t.Errorf("expected only the port from the map, got %+v", s)
+	}
+
+	l, err := DotenvLookuper(strings.NewReader("# local\nENV_CONFIG_HOST=\"db.local\"\nexport ENV_CONFIG_PORT=5432\n"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := ProcessWithLookuper("env_config", &s, l); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != "db.local" || s.Port != 5432 {
+		t.Errorf("expected values from the dotenv file, got %+v", s)
+	}
+	if _, err := DotenvLookuper(strings.NewReader("NOPE")); err == nil {
+		t.Error("expected error for a malformed dotenv file, got nil")
+	}
+}
+
+func TestProcessMap(t *testing.T) {
+	type spec struct {
+		Host     string `required:"true"`
+		Port     int    `default:"80"`
+		Backends map[string]struct {
+			URL string
+		}
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "from-env")
+	os.Setenv("ENV_CONFIG_BACKENDS_ENV_URL", "http://env")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			env := map[string]string{
+				"ENV_CONFIG_HOST":              fmt.Sprintf("host-%d", i),
+				"ENV_CONFIG_BACKENDS_MAIN_URL": "http://main",
+			}
+			var s spec
+			if err := ProcessMap("env_config", &s, env, Options{Lookuper: OSLookuper()}); err != nil {
+				t.Error(err.Error())
+				return
+			}
+			if s.Host != fmt.Sprintf("host-%d", i) || s.Port != 80 {
+				t.Errorf("expected host-%d and the default port, got %+v", i, s)
+			}
+			if len(s.Backends) != 1 || s.Backends["main"].URL != "http://main" {
+				t.Errorf("expected only the backend from the map, got %+v", s.Backends)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var s spec
+	if err := ProcessMap("env_config", &s, nil, Options{}); err == nil {
+		t.Error("expected error for a required variable missing from the map, got nil")
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	var s struct {
+		Host    string `required:"true"`
+		Port    int
+		Timeout time.Duration
+		User    string `required:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	os.Setenv("ENV_CONFIG_TIMEOUT", "30s")
+	for _, parallel := range []bool{false, true} {
+		err := ProcessWithOptions("env_config", &s, Options{CollectErrors: true, ParallelExcecution: parallel})
+		var errs MultiError
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected MultiError, got %v", err)
+		}
+		if len(errs) != 3 {
+			t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.KeyName != "ENV_CONFIG_PORT" {
+			t.Errorf("expected a ParseError for ENV_CONFIG_PORT, got %v", perr)
+		}
+		// as errors.As does before Go 1.20
+		perr = nil
+		if !errs.As(&perr) || perr.KeyName != "ENV_CONFIG_PORT" || !errs.Is(errs[0]) || errs.Is(ErrInvalidSpecification) {
+			t.Errorf("expected the MultiError to match its errors, got %v", perr)
+		}
+		if !strings.Contains(errs[0].Error(), "ENV_CONFIG_HOST") || !strings.Contains(errs[2].Error(), "ENV_CONFIG_USER") {
+			t.Errorf("expected errors in declaration order, got %v", errs)
+		}
+		if s.Timeout != 30*time.Second {
+			t.Errorf("expected the valid field to be set, got %v", s.Timeout)
+		}
+	}
+}
+
 func TestRequiredVar(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -580,6 +873,15 @@ func TestRequiredMissing(t *testing.T) {
 	if err == nil {
 		t.Error("no failure when missing required variable")
 	}
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || rerr.KeyName != "ENV_CONFIG_REQUIREDVAR" || rerr.FieldName != "RequiredVar" {
+		t.Errorf("expected a RequiredError for ENV_CONFIG_REQUIREDVAR, got %v", err)
+	}
+
+	err = ProcessWithOptions("env_config", &s, Options{ParallelExcecution: true})
+	if !errors.As(err, &rerr) || rerr.KeyName != "ENV_CONFIG_REQUIREDVAR" {
+		t.Errorf("expected a RequiredError in parallel mode, got %v", err)
+	}
 }
 
 func TestBlankDefaultVar(t *testing.T) {
@@ -665,6 +967,256 @@ func TestRequiredDefault(t *testing.T) {
 	}
 }
 
+func TestOSSpecificDefault(t *testing.T) {
+	var s struct {
+		DataDir string `default:"/tmp/app" default_windows:"C:\\ProgramData\\app" default_plan9:"/lib/app"`
+	}
+	defer func(saved string) { goos = saved }(goos)
+
+	os.Clearenv()
+	goos = "windows"
+	if err := Process("env_config", &s); err != nil {
+		t.Error(err.Error())
+	}
+	if s.DataDir != `C:\ProgramData\app` {
+		t.Errorf("expected %q, got %q", `C:\ProgramData\app`, s.DataDir)
+	}
+
+	goos = "linux"
+	if err := Process("env_config", &s); err != nil {
+		t.Error(err.Error())
+	}
+	if s.DataDir != "/tmp/app" {
+		t.Errorf("expected %q, got %q", "/tmp/app", s.DataDir)
+	}
+
+	goos = "windows"
+	os.Setenv("ENV_CONFIG_DATADIR", "D:\\app")
+	if err := Process("env_config", &s); err != nil {
+		t.Error(err.Error())
+	}
+	if s.DataDir != `D:\app` {
+		t.Errorf("expected %q, got %q", `D:\app`, s.DataDir)
+	}
+}
+
+func TestProfileDefault(t *testing.T) {
+	var s struct {
+		LogLevel string `default:"info" default_prod:"warn" default_linux:"debug"`
+		Replicas int    `default:"1" default_prod:"numcpu*2"`
+	}
+	defer func(saved string) { goos = saved }(goos)
+	defer func(saved func() int) { numCPU = saved }(numCPU)
+	goos = "linux"
+	numCPU = func() int { return 4 }
+
+	os.Clearenv()
+	if err := ProcessWithOptions("env_config", &s, Options{Profile: "prod"}); err != nil {
+		t.Error(err.Error())
+	}
+	if s.LogLevel != "warn" {
+		t.Errorf("expected %q, got %q", "warn", s.LogLevel)
+	}
+	if s.Replicas != 8 {
+		t.Errorf("expected %d, got %d", 8, s.Replicas)
+	}
+
+	if err := ProcessWithOptions("env_config", &s, Options{Profile: "staging"}); err != nil {
+		t.Error(err.Error())
+	}
+	if s.LogLevel != "debug" {
+		t.Errorf("expected %q, got %q", "debug", s.LogLevel)
+	}
+	if s.Replicas != 1 {
+		t.Errorf("expected %d, got %d", 1, s.Replicas)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := UsagefWithOptions("env_config", &s, buf, "{{range .}}{{usage_default .}} {{end}}", Options{Profile: "prod"}); err != nil {
+		t.Error(err.Error())
+	}
+	if buf.String() != "warn numcpu*2 (8) " {
+		t.Errorf("expected %q, got %q", "warn numcpu*2 (8) ", buf.String())
+	}
+}
+
+type squashRedis struct {
+	RedisHost string `split_words:"true"`
+	RedisDB   int    `envconfig:"redis_db"`
+	Redis     string
+}
+
+func TestSquashPrefixes(t *testing.T) {
+	var s struct {
+		Redis    squashRedis
+		Squashed squashRedis `envconfig:"redis_cache" squash:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("APP_REDIS_HOST", "squashed")
+	os.Setenv("APP_REDIS_REDIS_HOST", "repeated")
+	os.Setenv("APP_REDIS_DB", "3")
+	os.Setenv("APP_REDIS_REDIS", "whole")
+	os.Setenv("APP_REDIS_CACHE_REDIS_HOST", "tagged")
+
+	if err := ProcessWithOptions("app", &s, Options{SquashPrefixes: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Redis.RedisHost != "squashed" {
+		t.Errorf("expected %q, got %q", "squashed", s.Redis.RedisHost)
+	}
+	if s.Redis.RedisDB != 3 {
+		t.Errorf("expected %d, got %d", 3, s.Redis.RedisDB)
+	}
+	if s.Redis.Redis != "whole" {
+		t.Errorf("expected %q, got %q", "whole", s.Redis.Redis)
+	}
+	// REDIS_CACHE does not end in REDIS, so nothing is squashed
+	if s.Squashed.RedisHost != "tagged" {
+		t.Errorf("expected %q, got %q", "tagged", s.Squashed.RedisHost)
+	}
+
+	s.Redis = squashRedis{}
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Redis.RedisHost != "repeated" {
+		t.Errorf("expected %q, got %q", "repeated", s.Redis.RedisHost)
+	}
+}
+
+func TestSquashTag(t *testing.T) {
+	var s struct {
+		Redis squashRedis `squash:"true"`
+		Plain squashRedis `envconfig:"redis" squash:"false"`
+	}
+	infos, err := gatherInfo("app", &s, Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if infos[0].Key != "APP_REDIS_HOST" {
+		t.Errorf("expected %q, got %q", "APP_REDIS_HOST", infos[0].Key)
+	}
+
+	infos, err = gatherInfo("app", &s, Options{SquashPrefixes: true})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if infos[3].Key != "APP_REDIS_REDIS_HOST" {
+		t.Errorf("expected %q, got %q", "APP_REDIS_REDIS_HOST", infos[3].Key)
+	}
+
+	// an unsplit key is one word and keeps it
+	var unsplit struct {
+		Redis struct {
+			RedisHost string
+		} `squash:"true"`
+	}
+	infos, err = gatherInfo("app", &unsplit, Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if infos[0].Key != "APP_REDIS_REDISHOST" {
+		t.Errorf("expected %q, got %q", "APP_REDIS_REDISHOST", infos[0].Key)
+	}
+}
+
+func TestAbsoluteKeys(t *testing.T) {
+	var s struct {
+		Port  int    `absolute:"true"`
+		Proxy string `envconfig:"HTTP_PROXY" absolute:"true"`
+		Name  string
+		AWS   struct {
+			Region string
+		} `absolute:"true"`
+		Nested struct {
+			Port int `absolute:"true"`
+		}
+	}
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	os.Setenv("HTTP_PROXY", "http://proxy:3128")
+	os.Setenv("APP_NAME", "app")
+	os.Setenv("AWS_REGION", "eu-west-1")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Port != 8080 {
+		t.Errorf("expected %d, got %d", 8080, s.Port)
+	}
+	if s.Proxy != "http://proxy:3128" {
+		t.Errorf("expected %q, got %q", "http://proxy:3128", s.Proxy)
+	}
+	if s.Name != "app" {
+		t.Errorf("expected %q, got %q", "app", s.Name)
+	}
+	if s.AWS.Region != "eu-west-1" {
+		t.Errorf("expected %q, got %q", "eu-west-1", s.AWS.Region)
+	}
+	if s.Nested.Port != 8080 {
+		t.Errorf("expected %d, got %d", 8080, s.Nested.Port)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	var s Specification
+	s.Port = 9000
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DEFAULTVAR", "from-env")
+
+	if err := ApplyDefaults(&s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DefaultVar != "foobar" {
+		t.Errorf("expected %q, got %q", "foobar", s.DefaultVar)
+	}
+	if s.RequiredDefault != "foo2bar" {
+		t.Errorf("expected %q, got %q", "foo2bar", s.RequiredDefault)
+	}
+	if s.SomePointerWithDefault == nil || *s.SomePointerWithDefault != "foo2baz" {
+		t.Errorf("expected %q, got %v", "foo2baz", s.SomePointerWithDefault)
+	}
+	if s.NestedSpecification.PropertyWithDefault != "fuzzybydefault" {
+		t.Errorf("expected %q, got %q", "fuzzybydefault", s.NestedSpecification.PropertyWithDefault)
+	}
+	if want := map[string]string{"one": "two", "three": "four"}; !reflect.DeepEqual(s.MapField, want) {
+		t.Errorf("expected %v, got %v", want, s.MapField)
+	}
+	if s.Port != 9000 {
+		t.Errorf("expected %d, got %d", 9000, s.Port)
+	}
+
+	if err := ApplyDefaults(s); err != ErrInvalidSpecification {
+		t.Errorf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+	os.Setenv("ENV_CONFIG_DEFAULTVAR", "from-env")
+	os.Setenv("ENV_CONFIG_PORT", "8080")
+	os.Setenv("ENV_CONFIG_SOMEPOINTER", "pointer")
+	os.Setenv("ENV_CONFIG_OUTER_INNER", "inner")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	s.Ignored = "kept"
+
+	if err := Reset(&s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Port != 0 || s.RequiredVar != "" || *s.SomePointer != "" || s.NestedSpecification.Property != "" {
+		t.Errorf("expected managed fields to be zeroed, got %+v", s)
+	}
+	if s.DefaultVar != "foobar" {
+		t.Errorf("expected %q, got %q", "foobar", s.DefaultVar)
+	}
+	if s.Ignored != "kept" {
+		t.Errorf("expected %q, got %q", "kept", s.Ignored)
+	}
+}
+
 func TestPointerFieldBlank(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -696,6 +1248,51 @@ func TestEmptyMapFieldOverride(t *testing.T) {
 	}
 }
 
+func TestDelimiters(t *testing.T) {
+	var s struct {
+		DSNs     []string          `delimiter:";"`
+		Backends map[string]string `delimiter:";" map_delimiter:"="`
+		Ports    []int
+	}
+	os.Clearenv()
+	os.Setenv("APP_DSNS", "host=a,port=1;host=b,port=2")
+	os.Setenv("APP_BACKENDS", "api=http://api:8080;web=http://web:80")
+	os.Setenv("APP_PORTS", "80|443")
+	if err := ProcessWithOptions("app", &s, Options{Delimiter: "|"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(s.DSNs, []string{"host=a,port=1", "host=b,port=2"}) {
+		t.Errorf("unexpected DSNs %q", s.DSNs)
+	}
+	if s.Backends["api"] != "http://api:8080" || s.Backends["web"] != "http://web:80" {
+		t.Errorf("unexpected backends %q", s.Backends)
+	}
+	if !reflect.DeepEqual(s.Ports, []int{80, 443}) {
+		t.Errorf("unexpected ports %v", s.Ports)
+	}
+}
+
+func TestProcessAs(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+	os.Setenv("ENV_CONFIG_PORT", "8080")
+	s, err := ProcessAs[Specification]("env_config")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Port != 8080 || s.RequiredVar != "foo" {
+		t.Errorf("unexpected values %d and %q", s.Port, s.RequiredVar)
+	}
+
+	os.Unsetenv("ENV_CONFIG_REQUIREDVAR")
+	if s, err := ProcessAs[Specification]("env_config"); err == nil || s != nil {
+		t.Errorf("expected error and nil spec, got %v and %v", err, s)
+	}
+	if _, err := ProcessAs[int]("env_config"); err != ErrInvalidSpecification {
+		t.Errorf("expected ErrInvalidSpecification, got %v", err)
+	}
+}
+
 func TestMustProcess(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -991,6 +1588,22 @@ func TestCheckDisallowedIgnored(t *testing.T) {
 	}
 }
 
+func TestCheckDisallowedLookuper(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_ZEBUG", "false")
+	l := MapLookuper{"ENV_CONFIG_DEBUG": "true", "ENV_CONFIG_YEBUG": "false"}
+	err := CheckDisallowedWithOptions("env_config", &s, Options{Lookuper: l})
+	if experr := "unknown environment variable ENV_CONFIG_YEBUG"; err == nil || err.Error() != experr {
+		t.Errorf("expected %s, got %v", experr, err)
+	}
+
+	l = MapLookuper{"ENV_CONFIG_DEBUG": "true"}
+	if err := CheckDisallowedWithOptions("env_config", &s, Options{Lookuper: l}); err != nil {
+		t.Errorf("expected the environment not to be checked, got %v", err)
+	}
+}
+
 func TestErrorMessageForRequiredAltVar(t *testing.T) {
 	var s struct {
 		Foo string `envconfig:"BAR" required:"true"`
@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+)
+
+// Environ returns the variables read by the specification that are currently
+// set, in the "KEY=value" form used by exec.Cmd.Env, so a child process can be
+// given exactly the configuration meant for it. Alternate names, and the
+// KEY_FILE variables of fields that may be read from files, are included
+// when they are set.
+func Environ(prefix string, spec interface{}) ([]string, error) {
+	return EnvironWithOptions(prefix, spec, Options{})
+}
+
+// EnvironWithOptions is like Environ() but with specified options. Values are
+// read through Options.Lookuper when it is set.
+func EnvironWithOptions(prefix string, spec interface{}, options Options) ([]string, error) {
+	infos, err := gatherOrdered(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	seen := make(map[string]struct{})
+	add := func(key string) {
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		if value, ok := options.lookup(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	for _, info := range infos {
+		add(info.Key)
+		if info.Alt != "" {
+			add(info.Alt)
+		}
+		for _, alias := range info.aliases() {
+			add(alias)
+		}
+		if info.fileIndirection(options) {
+			for _, key := range info.fileKeys() {
+				add(key)
+			}
+		}
+	}
+	return env, nil
+}
+
+// PrefixEnviron returns the variables of the process environment whose names
+// start with the prefix followed by an underscore, in "KEY=value" form.
+func PrefixEnviron(prefix string) []string {
+	prefix = envPrefix(prefix)
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) && strings.Contains(kv, "=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
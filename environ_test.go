@@ -0,0 +1,49 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestEnviron(t *testing.T) {
+	var s struct {
+		Host  string
+		Port  int
+		Proxy string `envconfig:"HTTP_PROXY"`
+	}
+	os.Clearenv()
+	os.Setenv("PLUGIN_HOST", "localhost")
+	os.Setenv("HTTP_PROXY", "http://proxy")
+	os.Setenv("PLUGIN_SECRET", "not for the plugin")
+	os.Setenv("DATABASE_PASSWORD", "not for the plugin")
+
+	env, err := Environ("plugin", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := []string{"PLUGIN_HOST=localhost", "HTTP_PROXY=http://proxy"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+}
+
+func TestPrefixEnviron(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PLUGIN_HOST", "localhost")
+	os.Setenv("PLUGIN_SECRET", "s3cr3t")
+	os.Setenv("PLUGINS", "outside")
+	os.Setenv("OTHER", "outside")
+
+	env := PrefixEnviron("plugin")
+	sort.Strings(env)
+	want := []string{"PLUGIN_HOST=localhost", "PLUGIN_SECRET=s3cr3t"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EnvrcPlaceholder is written for required variables that have no default.
+const EnvrcPlaceholder = "CHANGE_ME"
+
+// Envrc writes a direnv compatible .envrc block for the specification to out.
+// Variables with a default are exported with that default, required variables
+// without one are exported with EnvrcPlaceholder, and the remaining variables
+// are listed as comments. Defaults that envconfig computes, such as
+// expressions and references, are listed as comments too, so processing
+// computes them rather than read them back as values. Values already present
+// in the environment are kept.
+func Envrc(prefix string, spec interface{}, out io.Writer) error {
+	return EnvrcWithOptions(prefix, spec, out, Options{})
+}
+
+// EnvrcWithOptions is like Envrc() but with specified options.
+func EnvrcWithOptions(prefix string, spec interface{}, out io.Writer, options Options) error {
+	infos, err := gatherOrdered(prefix, spec, options)
+	if err != nil {
+		return err
+	}
+	defaults := options.withFields(infos)
+	defaults.Lookuper, defaults.OnLookup = MapLookuper{}, nil
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "# Generated by envconfig. Values already set in the environment take precedence.")
+	for _, info := range infos {
+		fmt.Fprintln(w)
+		if desc := info.Tags.Get("desc"); desc != "" {
+			fmt.Fprintf(w, "# %s\n", desc)
+		}
+
+		req := info.Tags.Get("required")
+		def := info.defaultValue()
+		if _, computed := info.computedDefault(defaults); def != "" && computed {
+			fmt.Fprintf(w, "# default: %s\n# export %s=\n", def, info.Key)
+			continue
+		}
+		switch {
+		case def != "":
+			fmt.Fprintf(w, "export %[1]s=${%[1]s:-%[2]s}\n", info.Key, shellQuote(def))
+		case isTrue(req) || (options.Required && !isFalse(req)):
+			fmt.Fprintf(w, "export %[1]s=${%[1]s:-%[2]s}\n", info.Key, shellQuote(EnvrcPlaceholder))
+		default:
+			fmt.Fprintf(w, "# export %s=\n", info.Key)
+		}
+	}
+	return w.Flush()
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
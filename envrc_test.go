@@ -0,0 +1,99 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+type envrcSpecification struct {
+	Host    string `required:"true" desc:"database host"`
+	Port    int    `default:"5432"`
+	Name    string `default:"it's"`
+	Verbose bool
+}
+
+func TestEnvrc(t *testing.T) {
+	var s envrcSpecification
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	if err := Envrc("app", &s, buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# Generated by envconfig. Values already set in the environment take precedence.
+
+# database host
+export APP_HOST=${APP_HOST:-'CHANGE_ME'}
+
+export APP_PORT=${APP_PORT:-'5432'}
+
+export APP_NAME=${APP_NAME:-'it'\''s'}
+
+# export APP_VERBOSE=
+`
+	if buf.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestEnvrcRequiredOption(t *testing.T) {
+	var s envrcSpecification
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	if err := EnvrcWithOptions("app", &s, buf, Options{Required: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("export APP_VERBOSE=${APP_VERBOSE:-'CHANGE_ME'}\n")) {
+		t.Errorf("expected placeholder for APP_VERBOSE, got:\n%s", buf.String())
+	}
+}
+
+func TestEnvrcInvalidSpecification(t *testing.T) {
+	if err := Envrc("app", envrcSpecification{}, new(bytes.Buffer)); err != ErrInvalidSpecification {
+		t.Errorf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+}
+
+func TestEnvrcComputedDefaults(t *testing.T) {
+	var s struct {
+		Workers int    `default:"numcpu*2"`
+		Host    string `default:"{{hostname}}"`
+		Addr    string `default:"${APP_HOST}:8080"`
+		Port    int    `default:"8080"`
+	}
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	if err := EnvrcWithOptions("app", &s, buf, Options{ExpandDefaults: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# Generated by envconfig. Values already set in the environment take precedence.
+
+# default: numcpu*2
+# export APP_WORKERS=
+
+# default: {{hostname}}
+# export APP_HOST=
+
+# default: ${APP_HOST}:8080
+# export APP_ADDR=
+
+export APP_PORT=${APP_PORT:-'8080'}
+`
+	if buf.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	// without ExpandDefaults the reference is a literal default
+	buf.Reset()
+	if err := Envrc("app", &s, buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("export APP_ADDR=${APP_ADDR:-'${APP_HOST}:8080'}\n")) {
+		t.Errorf("expected the literal default for APP_ADDR, got:\n%s", buf.String())
+	}
+}
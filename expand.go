@@ -0,0 +1,164 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxExpandDepth bounds how deeply references to other variables are
+// followed, as a backstop to the cycle check.
+const maxExpandDepth = 16
+
+// maxExpandLen bounds the length of an expanded value when
+// Options.MaxValueLen is not set, as values that refer to other variables
+// several times can otherwise double in length at every level.
+const maxExpandLen = 1 << 20
+
+// expansion reports whether references to other variables in the value are
+// substituted, as enabled by the `expand` tag or Options.ExpandEnv.
+func (info varInfo) expansion(options Options) bool {
+	tag := info.Tags.Get("expand")
+	return isTrue(tag) || options.ExpandEnv && !isFalse(tag)
+}
+
+// expandValue substitutes $NAME and ${NAME} in value with the values of the
+// named variables, which are expanded in turn. $$ stands for a literal $.
+func (info varInfo) expandValue(value string, options Options) (string, error) {
+	value, err := expandRefs(value, options, true, []string{info.Key})
+	if err != nil {
+		return "", fmt.Errorf("envconfig.Process: %v", err)
+	}
+	return value, nil
+}
+
+// withFields returns options that resolve references to the keys of infos
+// which are not set to the defaults of their fields.
+func (options Options) withFields(infos []varInfo) Options {
+	options.fields = make(map[string]varInfo, len(infos))
+	for _, info := range infos {
+		if info.Alt != "" {
+			options.fields[info.Alt] = info
+		}
+		options.fields[info.Key] = info
+	}
+	return options
+}
+
+// expandRefs substitutes the references in value. Defaults with
+// Options.ExpandDefaults have ${NAME} substituted, with $${ standing for a
+// literal ${; in full mode, as for values with expansion enabled, $NAME is
+// substituted too, $$ stands for a literal $ and referenced values are
+// expanded in turn. Variables that are
+// neither set nor a key of the specification with a default expand to "".
+// stack holds the variables being expanded, outermost first.
+func expandRefs(value string, options Options, full bool, stack []string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		var name string
+		switch c := value[i+1]; {
+		case c == '$' && (full || strings.HasPrefix(value[i+2:], "{")):
+			b.WriteByte('$')
+			i++
+			if !full {
+				b.WriteByte('{')
+				i++
+			}
+			continue
+		case c == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in value of %s", stack[0])
+			}
+			name = value[i+2 : i+2+end]
+			if !isVarName(name) {
+				return "", fmt.Errorf("invalid variable name %q in value of %s", name, stack[0])
+			}
+			i += end + 2
+		case full && isVarByte(c, true):
+			j := i + 1
+			for j < len(value) && isVarByte(value[j], j == i+1) {
+				j++
+			}
+			name = value[i+1 : j]
+			i = j - 1
+		default:
+			b.WriteByte('$')
+			continue
+		}
+
+		ref, err := resolveRef(name, options, full, stack)
+		if err != nil {
+			return "", err
+		}
+		if max := expandLimit(options); b.Len()+len(ref) > max {
+			return "", fmt.Errorf("value of %s expands to more than %d bytes", stack[0], max)
+		}
+		b.WriteString(ref)
+	}
+	return b.String(), nil
+}
+
+// expandLimit returns the length an expanded value may have.
+func expandLimit(options Options) int {
+	if options.MaxValueLen > 0 {
+		return options.MaxValueLen
+	}
+	return maxExpandLen
+}
+
+// resolveRef returns the value of the variable name, or the default of the
+// field with that key when it is not set.
+func resolveRef(name string, options Options, full bool, stack []string) (string, error) {
+	for _, seen := range stack {
+		if seen == name {
+			return "", fmt.Errorf("%s refers to itself via %s", stack[0], strings.Join(append(stack, name), " -> "))
+		}
+	}
+	if len(stack) > maxExpandDepth {
+		return "", fmt.Errorf("references in value of %s nest more than %d deep", stack[0], maxExpandDepth)
+	}
+	stack = append(stack[:len(stack):len(stack)], name)
+
+	value, ok, err := options.lookupErr(name)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if !full {
+			return value, nil
+		}
+		return expandRefs(value, options, true, stack)
+	}
+	if info, ok := options.fields[name]; ok && info.defaultValue() != "" {
+		return info.resolveDefaultRefs(options, stack)
+	}
+	return "", nil
+}
+
+func isVarName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isVarByte(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVarByte(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	var s struct {
+		DatabaseURL string `split_words:"true"`
+		Greeting    string `default:"hello ${USER_NAME}"`
+		Price       string
+		Raw         string `expand:"false"`
+		Port        int
+	}
+	os.Clearenv()
+	os.Setenv("DB_HOST", "db.internal")
+	os.Setenv("DB_PORT", "$DEFAULT_PORT")
+	os.Setenv("DEFAULT_PORT", "5432")
+	os.Setenv("USER_NAME", "gopher")
+	os.Setenv("APP_DATABASE_URL", "postgres://$DB_HOST:${DB_PORT}/app")
+	os.Setenv("APP_PRICE", "$$5 or $UNSET$")
+	os.Setenv("APP_RAW", "$DB_HOST")
+	os.Setenv("APP_PORT", "${DEFAULT_PORT}")
+	if err := ProcessWithOptions("app", &s, Options{ExpandEnv: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DatabaseURL != "postgres://db.internal:5432/app" {
+		t.Errorf("unexpected database url %q", s.DatabaseURL)
+	}
+	if s.Greeting != "hello gopher" {
+		t.Errorf("unexpected greeting %q", s.Greeting)
+	}
+	if s.Price != "$5 or $" {
+		t.Errorf("unexpected price %q", s.Price)
+	}
+	if s.Raw != "$DB_HOST" {
+		t.Errorf("expected %q, got %q", "$DB_HOST", s.Raw)
+	}
+	if s.Port != 5432 {
+		t.Errorf("expected %d, got %d", 5432, s.Port)
+	}
+
+	// without the option values are kept as written
+	os.Unsetenv("APP_PORT")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DatabaseURL != "postgres://$DB_HOST:${DB_PORT}/app" {
+		t.Errorf("unexpected database url %q", s.DatabaseURL)
+	}
+}
+
+func TestExpandTag(t *testing.T) {
+	var s struct {
+		Addr string `expand:"true"`
+		Name string
+	}
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+	os.Setenv("APP_ADDR", "$HOST:80")
+	os.Setenv("APP_NAME", "$HOST")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Addr != "example.com:80" || s.Name != "$HOST" {
+		t.Errorf("unexpected values %+v", s)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	var s struct {
+		Value string
+	}
+	for _, tc := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"APP_VALUE": "$APP_VALUE"}, "refers to itself"},
+		{map[string]string{"APP_VALUE": "$A", "A": "${B}", "B": "$A"}, "APP_VALUE -> A -> B -> A"},
+		{map[string]string{"APP_VALUE": "${A"}, "unterminated"},
+		{map[string]string{"APP_VALUE": "${A-B}"}, "invalid variable name"},
+	} {
+		os.Clearenv()
+		for k, v := range tc.env {
+			os.Setenv(k, v)
+		}
+		err := ProcessWithOptions("app", &s, Options{ExpandEnv: true})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.env, tc.want, err)
+		}
+	}
+}
+
+func TestExpandLength(t *testing.T) {
+	var s struct {
+		Value string
+	}
+	os.Clearenv()
+	os.Setenv("APP_VALUE", "$A1$A1")
+	for i := 1; i < 12; i++ {
+		os.Setenv(fmt.Sprintf("A%d", i), fmt.Sprintf("$A%[1]d$A%[1]d", i+1))
+	}
+	os.Setenv("A12", strings.Repeat("x", 1024))
+	err := ProcessWithOptions("app", &s, Options{ExpandEnv: true})
+	if err == nil || !strings.Contains(err.Error(), "expands to more than 1048576 bytes") {
+		t.Errorf("expected error for a value that doubles at every level, got %v", err)
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_VALUE", "$A$A$A")
+	os.Setenv("A", strings.Repeat("x", 40))
+	err = ProcessWithOptions("app", &s, Options{ExpandEnv: true, MaxValueLen: 100})
+	if err == nil || !strings.Contains(err.Error(), "expands to more than 100 bytes") {
+		t.Errorf("expected error for a value expanding past MaxValueLen, got %v", err)
+	}
+}
+
+func TestDefaultReferences(t *testing.T) {
+	var s struct {
+		Host    string `default:"localhost"`
+		Addr    string `default:"${APP_HOST}:8080"`
+		DataDir string `split_words:"true" default:"${HOME}/data"`
+		Pattern string `default:"^$$HOME $${HOME}$"`
+		Workers int    `default:"${APP_CORES}*2"`
+		Cores   int    `default:"2"`
+	}
+	os.Clearenv()
+	os.Setenv("HOME", "/home/gopher")
+	options := Options{ExpandDefaults: true}
+	if err := ProcessWithOptions("app", &s, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Addr != "localhost:8080" {
+		t.Errorf("expected %q, got %q", "localhost:8080", s.Addr)
+	}
+	if s.DataDir != "/home/gopher/data" {
+		t.Errorf("expected %q, got %q", "/home/gopher/data", s.DataDir)
+	}
+	if s.Pattern != "^$$HOME ${HOME}$" {
+		t.Errorf("expected %q, got %q", "^$$HOME ${HOME}$", s.Pattern)
+	}
+	if s.Workers != 4 {
+		t.Errorf("expected %d, got %d", 4, s.Workers)
+	}
+
+	os.Setenv("APP_HOST", "db.internal")
+	os.Setenv("APP_CORES", "8")
+	if err := ProcessWithOptions("app", &s, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Addr != "db.internal:8080" || s.Workers != 16 {
+		t.Errorf("unexpected values %+v", s)
+	}
+
+	// without the option defaults are kept as written
+	var literal struct {
+		DataDir string `default:"${HOME}/data"`
+	}
+	if err := Process("app", &literal); err != nil {
+		t.Fatal(err.Error())
+	}
+	if literal.DataDir != "${HOME}/data" {
+		t.Errorf("expected %q, got %q", "${HOME}/data", literal.DataDir)
+	}
+
+	var d struct {
+		Host string `default:"localhost"`
+		Addr string `default:"${HOST}:8080"`
+		Data string `default:"${HOME}/data"`
+	}
+	os.Setenv("HOST", "db.internal")
+	if err := ApplyDefaultsWithOptions(&d, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	if d.Addr != "localhost:8080" || d.Data != "/data" {
+		t.Errorf("unexpected defaults %+v", d)
+	}
+
+	var c struct {
+		A string `default:"${APP_B}"`
+		B string `default:"x${APP_A}"`
+	}
+	var perr *ParseError
+	if err := ProcessWithOptions("app", &c, options); !errors.As(err, &perr) || !strings.Contains(err.Error(), "APP_A -> APP_B -> APP_A") {
+		t.Errorf("expected a ParseError for the cycle, got %v", err)
+	}
+}
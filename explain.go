@@ -0,0 +1,150 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Explain returns a human readable account of how the environment variable
+// key is handled for the specification: the field it maps to, how its name was
+// derived, which variables were consulted and what was found, and the default
+// that applies, with the value it resolves to when it has references,
+// providers or an expression. Values of fields tagged `sensitive:"true"`, and of
+// sensitive types such as PEMPrivateKey, are not shown.
+func Explain(prefix string, spec interface{}, key string) (string, error) {
+	return ExplainWithOptions(prefix, spec, key, Options{})
+}
+
+// ExplainWithOptions is like Explain() but with specified options.
+func ExplainWithOptions(prefix string, spec interface{}, key string, options Options) (string, error) {
+	infos, err := gatherInfo(prefix, spec, options)
+	if err != nil {
+		return "", err
+	}
+	options = options.withFields(infos)
+
+	for _, info := range infos {
+		if strings.EqualFold(info.Key, key) || (info.Alt != "" && strings.EqualFold(info.Alt, key)) {
+			return explainInfo(info, options), nil
+		}
+		for _, alias := range info.aliases() {
+			if strings.EqualFold(alias, key) {
+				return explainInfo(info, options), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("envconfig.Explain: no field maps to %s", strings.ToUpper(key))
+}
+
+func explainInfo(info varInfo, options Options) string {
+	var b bytes.Buffer
+	show := func(value string) string {
+		if info.isSensitive() {
+			return "<sensitive>"
+		}
+		return fmt.Sprintf("%q", value)
+	}
+
+	fmt.Fprintf(&b, "%s\n", info.Key)
+	fmt.Fprintf(&b, "  field:    %s (%s)\n", info.Path, info.Field.Type())
+	if desc := info.Tags.Get("desc"); desc != "" {
+		fmt.Fprintf(&b, "  desc:     %s\n", desc)
+	}
+
+	var name, namePart string
+	tagSplitWords := info.Tags.Get("split_words")
+	switch {
+	case info.Alt != "":
+		name = fmt.Sprintf("envconfig tag %q", info.Tags.Get("envconfig"))
+		namePart = info.Alt
+	case isTrue(tagSplitWords) || options.SplitWords && !isFalse(tagSplitWords):
+		name = fmt.Sprintf("field name %s split into words", info.Name)
+		namePart = strings.ToUpper(splitWords(info.Name))
+	default:
+		name = fmt.Sprintf("field name %s", info.Name)
+		namePart = strings.ToUpper(info.Name)
+	}
+	if strings.HasSuffix(info.Key, "_"+namePart) {
+		name += " under prefix " + strings.TrimSuffix(info.Key, "_"+namePart)
+	}
+	fmt.Fprintf(&b, "  name:     %s\n", name)
+
+	var found, foundAt string
+	consult := func(key string) {
+		value, ok := options.lookup(key)
+		if !ok {
+			fmt.Fprintf(&b, "  lookup:   %s: not set\n", key)
+			return
+		}
+		fmt.Fprintf(&b, "  lookup:   %s: set to %s\n", key, show(value))
+		if foundAt == "" {
+			found, foundAt = value, key
+		}
+	}
+	consult(info.Key)
+	if info.Alt != "" && info.Alt != info.Key {
+		consult(info.Alt)
+	}
+	for _, alias := range info.aliases() {
+		consult(alias)
+	}
+	var file, fileAt string
+	if info.fileIndirection(options) {
+		for _, key := range info.fileKeys() {
+			path, ok := options.lookup(key)
+			if !ok {
+				fmt.Fprintf(&b, "  lookup:   %s: not set\n", key)
+				continue
+			}
+			fmt.Fprintf(&b, "  lookup:   %s: set to %q\n", key, path)
+			if fileAt == "" {
+				file, fileAt = path, key
+			}
+		}
+	}
+
+	def := info.defaultValue()
+	applied, defErr := def, error(nil)
+	if def != "" {
+		applied, defErr = info.resolveDefault(options)
+		if perr, ok := defErr.(*ParseError); ok {
+			defErr = perr.Err
+		}
+	}
+	switch {
+	case def == "":
+		fmt.Fprintf(&b, "  default:  none\n")
+	case defErr != nil:
+		fmt.Fprintf(&b, "  default:  %s, which does not resolve: %v\n", show(def), defErr)
+	case applied != def:
+		fmt.Fprintf(&b, "  default:  %s, resolved to %s\n", show(def), show(applied))
+	default:
+		fmt.Fprintf(&b, "  default:  %s\n", show(def))
+	}
+	req := info.Tags.Get("required")
+	required := isTrue(req) || (options.Required && !isFalse(req))
+	fmt.Fprintf(&b, "  required: %v\n", required)
+
+	switch {
+	case foundAt != "" && fileAt != "":
+		fmt.Fprintf(&b, "  result:   error, %s and %s are both set\n", foundAt, fileAt)
+	case foundAt != "":
+		fmt.Fprintf(&b, "  result:   %s from %s\n", show(found), foundAt)
+	case fileAt != "":
+		fmt.Fprintf(&b, "  result:   contents of %s from %s\n", file, fileAt)
+	case def != "" && defErr != nil:
+		fmt.Fprintf(&b, "  result:   error, default does not resolve\n")
+	case def != "":
+		fmt.Fprintf(&b, "  result:   default %s\n", show(applied))
+	case required:
+		fmt.Fprintf(&b, "  result:   error, required key missing value\n")
+	default:
+		fmt.Fprintf(&b, "  result:   field left unchanged\n")
+	}
+	return b.String()
+}
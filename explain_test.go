@@ -0,0 +1,114 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("MULTI_WORD_VAR_WITH_ALT", "alt value")
+
+	got, err := Explain("env_config", &s, "env_config_multi_word_var_with_alt")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := `ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT
+  field:    MultiWordVarWithAlt (string)
+  desc:     what alt
+  name:     envconfig tag "MULTI_WORD_VAR_WITH_ALT" under prefix ENV_CONFIG
+  lookup:   ENV_CONFIG_MULTI_WORD_VAR_WITH_ALT: not set
+  lookup:   MULTI_WORD_VAR_WITH_ALT: set to "alt value"
+  default:  none
+  required: false
+  result:   "alt value" from MULTI_WORD_VAR_WITH_ALT
+`
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestExplainNestedDefault(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+
+	got, err := Explain("env_config", &s, "ENV_CONFIG_OUTER_PROPERTYWITHDEFAULT")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := `ENV_CONFIG_OUTER_PROPERTYWITHDEFAULT
+  field:    NestedSpecification.PropertyWithDefault (string)
+  name:     field name PropertyWithDefault under prefix ENV_CONFIG_OUTER
+  lookup:   ENV_CONFIG_OUTER_PROPERTYWITHDEFAULT: not set
+  default:  "fuzzybydefault"
+  required: false
+  result:   default "fuzzybydefault"
+`
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestExplainComputedDefault(t *testing.T) {
+	var s struct {
+		Size int    `default:"2*3"`
+		Host string `default:"localhost"`
+		Addr string `default:"${APP_HOST}:8080"`
+		Bad  int    `default:"2*"`
+	}
+	os.Clearenv()
+	os.Setenv("APP_HOST", "db")
+	options := Options{ExpandDefaults: true}
+	for key, want := range map[string]string{
+		"APP_SIZE": "  default:  \"2*3\", resolved to \"6\"\n  required: false\n  result:   default \"6\"\n",
+		"APP_ADDR": "  default:  \"${APP_HOST}:8080\", resolved to \"db:8080\"\n  required: false\n  result:   default \"db:8080\"\n",
+		"APP_HOST": "  result:   \"db\" from APP_HOST\n",
+		"APP_BAD":  "  result:   error, default does not resolve\n",
+	} {
+		got, err := ExplainWithOptions("app", &s, key, options)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if !strings.HasSuffix(got, want) {
+			t.Errorf("%s: expected to end with:\n%s\ngot:\n%s", key, want, got)
+		}
+	}
+}
+
+func TestExplainSensitive(t *testing.T) {
+	var s struct {
+		APIKey string `required:"true" sensitive:"true" split_words:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("APP_API_KEY", "secret")
+
+	got, err := Explain("app", &s, "APP_API_KEY")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := `APP_API_KEY
+  field:    APIKey (string)
+  name:     field name APIKey split into words under prefix APP
+  lookup:   APP_API_KEY: set to <sensitive>
+  default:  none
+  required: true
+  result:   <sensitive> from APP_API_KEY
+`
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestExplainUnknownKey(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	if _, err := Explain("env_config", &s, "NOPE"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Runtime facts available to default expressions. They are variables so tests
+// can pin them to known values.
+var (
+	numCPU      = runtime.NumCPU
+	gomaxprocs  = func() int { return runtime.GOMAXPROCS(0) }
+	totalMemory = systemMemory
+)
+
+// isNumericField reports whether field holds a plain (or pointer to a) number
+// that does not decode itself. time.Duration is not considered a number.
+func isNumericField(field reflect.Value) bool {
+	if decoderFrom(field) != nil || setterFrom(field) != nil || textUnmarshaler(field) != nil || binaryUnmarshaler(field) != nil {
+		return false
+	}
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.PkgPath() == "time" && typ.Name() == "Duration" {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// evalDefault evaluates a numeric default such as "numcpu*2" or "25%mem" and
+// formats the result for field. Defaults that are already plain numbers are
+// returned unchanged.
+func evalDefault(def string, field reflect.Value) (string, error) {
+	if _, err := strconv.ParseFloat(def, 64); err == nil {
+		return def, nil
+	}
+	if _, err := strconv.ParseInt(def, 0, 64); err == nil {
+		return def, nil
+	}
+
+	p := &exprParser{src: def}
+	v, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v, 'g', -1, typ.Bits()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v < 0 {
+			return "", fmt.Errorf("default expression %q is negative", def)
+		}
+		return strconv.FormatUint(uint64(math.Floor(v)), 10), nil
+	default:
+		return strconv.FormatInt(int64(math.Trunc(v)), 10), nil
+	}
+}
+
+// exprParser is a recursive descent parser for default expressions:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number [ "%" [ factor ] ] | ident | call | "(" expr ")" | "-" factor
+//	call   = ("min" | "max") "(" expr { "," expr } ")"
+//
+// The identifiers numcpu, gomaxprocs and mem (total memory in bytes) are
+// available. "25%mem" reads as a quarter of total memory.
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) parse() (float64, error) {
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return 0, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return v, nil
+}
+
+func (p *exprParser) expr() (float64, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			r, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			v += r
+		case '-':
+			p.pos++
+			r, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) term() (float64, error) {
+	v, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*':
+			p.pos++
+			r, err := p.factor()
+			if err != nil {
+				return 0, err
+			}
+			v *= r
+		case '/':
+			p.pos++
+			r, err := p.factor()
+			if err != nil {
+				return 0, err
+			}
+			if r == 0 {
+				return 0, p.errorf("division by zero")
+			}
+			v /= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *exprParser) factor() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '-':
+		p.pos++
+		v, err := p.factor()
+		return -v, err
+	case c == '(':
+		p.pos++
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, p.errorf("missing )")
+		}
+		p.pos++
+		return v, nil
+	case c == '.' || unicode.IsDigit(rune(c)):
+		return p.number()
+	case unicode.IsLetter(rune(c)):
+		return p.ident()
+	case c == 0:
+		return 0, p.errorf("unexpected end of expression")
+	}
+	return 0, p.errorf("unexpected %q", string(c))
+}
+
+func (p *exprParser) number() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (p.src[p.pos] == '.' || unicode.IsDigit(rune(p.src[p.pos]))) {
+		p.pos++
+	}
+	v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", p.src[start:p.pos])
+	}
+	if p.peek() != '%' {
+		return v, nil
+	}
+	p.pos++
+	v /= 100
+	if c := p.peek(); c == '(' || unicode.IsLetter(rune(c)) {
+		r, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		v *= r
+	}
+	return v, nil
+}
+
+func (p *exprParser) ident() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.src[start:p.pos])
+	switch name {
+	case "numcpu":
+		return float64(numCPU()), nil
+	case "gomaxprocs":
+		return float64(gomaxprocs()), nil
+	case "mem":
+		m, err := totalMemory()
+		if err != nil {
+			return 0, err
+		}
+		return float64(m), nil
+	case "min", "max":
+		return p.call(name)
+	}
+	return 0, p.errorf("unknown identifier %q", name)
+}
+
+func (p *exprParser) call(name string) (float64, error) {
+	if p.peek() != '(' {
+		return 0, p.errorf("%s requires arguments", name)
+	}
+	p.pos++
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == ',' {
+		p.pos++
+		r, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if name == "min" {
+			v = math.Min(v, r)
+		} else {
+			v = math.Max(v, r)
+		}
+	}
+	if p.peek() != ')' {
+		return 0, p.errorf("missing )")
+	}
+	p.pos++
+	return v, nil
+}
+
+// peek skips white space and returns the next byte, or 0 at the end.
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("default expression %q: %s", p.src, fmt.Sprintf(format, args...))
+}
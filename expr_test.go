@@ -0,0 +1,93 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func pinRuntimeFacts(t *testing.T, cpus int, mem uint64) {
+	savedCPU, savedProcs, savedMem := numCPU, gomaxprocs, totalMemory
+	numCPU = func() int { return cpus }
+	gomaxprocs = func() int { return cpus / 2 }
+	totalMemory = func() (uint64, error) { return mem, nil }
+	t.Cleanup(func() { numCPU, gomaxprocs, totalMemory = savedCPU, savedProcs, savedMem })
+}
+
+func TestRuntimeDefaults(t *testing.T) {
+	var s struct {
+		Workers   int           `default:"numcpu*2"`
+		Procs     uint          `default:"max(1, gomaxprocs-1)"`
+		CacheSize uint64        `default:"25%mem"`
+		Ratio     float64       `default:"numcpu/8"`
+		Literal   int           `default:"0x10"`
+		Name      string        `default:"numcpu*2"`
+		Timeout   time.Duration `default:"5s"`
+	}
+	os.Clearenv()
+	pinRuntimeFacts(t, 4, 1<<30)
+
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Workers != 8 {
+		t.Errorf("expected %d, got %d", 8, s.Workers)
+	}
+	if s.Procs != 1 {
+		t.Errorf("expected %d, got %d", 1, s.Procs)
+	}
+	if s.CacheSize != 1<<28 {
+		t.Errorf("expected %d, got %d", 1<<28, s.CacheSize)
+	}
+	if s.Ratio != 0.5 {
+		t.Errorf("expected %v, got %v", 0.5, s.Ratio)
+	}
+	if s.Literal != 16 {
+		t.Errorf("expected %d, got %d", 16, s.Literal)
+	}
+	if s.Name != "numcpu*2" {
+		t.Errorf("expected %q, got %q", "numcpu*2", s.Name)
+	}
+	if s.Timeout != 5*time.Second {
+		t.Errorf("expected %s, got %s", 5*time.Second, s.Timeout)
+	}
+}
+
+func TestRuntimeDefaultOverridden(t *testing.T) {
+	var s struct {
+		Workers int `default:"numcpu*2"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_WORKERS", "3")
+	pinRuntimeFacts(t, 4, 1<<30)
+
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Workers != 3 {
+		t.Errorf("expected %d, got %d", 3, s.Workers)
+	}
+}
+
+func TestRuntimeDefaultErrors(t *testing.T) {
+	for _, def := range []string{"numcpu*", "cores*2", "(numcpu", "numcpu/0", "min"} {
+		p := &exprParser{src: def}
+		if _, err := p.parse(); err == nil {
+			t.Errorf("%q: expected error, got nil", def)
+		}
+	}
+
+	var s struct {
+		Workers uint `default:"1-numcpu"`
+	}
+	os.Clearenv()
+	pinRuntimeFacts(t, 4, 1<<30)
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package faultlookup implements an envconfig.Lookuper for tests that wraps
+// another and injects faults on demand: keys that go missing, lookups that
+// are slow, and values that flap between lookups. It lets a service test how
+// it behaves with degraded configuration, and how an envconfig.Watcher copes
+// with a source that misbehaves:
+//
+//	l := faultlookup.New(envconfig.MapLookuper{"MYAPP_LEVEL": "info"})
+//	l.Flap("MYAPP_LEVEL", "info", "debug")
+//	l.Slow(50*time.Millisecond)
+//	w := &envconfig.Watcher{Prefix: "myapp", Options: envconfig.Options{Lookuper: l}}
+//
+// It is not meant for production use.
+package faultlookup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Lookuper wraps an envconfig.Lookuper and injects the faults configured
+// with its methods, which may be called while lookups are in progress.
+type Lookuper struct {
+	next envconfig.Lookuper
+
+	mu           sync.Mutex
+	missing      map[string]bool
+	intermittent map[string]int
+	flapping     map[string][]string
+	delays       map[string]time.Duration
+	delay        time.Duration
+	lookups      map[string]int
+}
+
+// New returns a Lookuper that reads values from next, the process
+// environment when it is nil, until faults are injected.
+func New(next envconfig.Lookuper) *Lookuper {
+	if next == nil {
+		next = envconfig.OSLookuper()
+	}
+	return &Lookuper{next: next}
+}
+
+// Missing makes keys look unset.
+func (l *Lookuper) Missing(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		setKey(&l.missing, key, true)
+	}
+}
+
+// Intermittent makes every nth lookup of key look unset, counting from the
+// first lookup afterwards.
+func (l *Lookuper) Intermittent(key string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	setKey(&l.intermittent, key, n)
+	delete(l.lookups, key)
+}
+
+// Flap makes successive lookups of key return each of values in turn, then
+// start over.
+func (l *Lookuper) Flap(key string, values ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	setKey(&l.flapping, key, values)
+	delete(l.lookups, key)
+}
+
+// Slow delays lookups of keys by d, or all lookups when no keys are given.
+// Lookups made through LookupContext stop waiting when the context is done
+// and report the key as unset.
+func (l *Lookuper) Slow(d time.Duration, keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(keys) == 0 {
+		l.delay = d
+		return
+	}
+	for _, key := range keys {
+		setKey(&l.delays, key, d)
+	}
+}
+
+// Reset removes the faults of keys, or all faults when no keys are given.
+func (l *Lookuper) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(keys) == 0 {
+		l.missing, l.intermittent, l.flapping, l.delays, l.delay = nil, nil, nil, nil, 0
+		return
+	}
+	for _, key := range keys {
+		delete(l.missing, key)
+		delete(l.intermittent, key)
+		delete(l.flapping, key)
+		delete(l.delays, key)
+	}
+}
+
+// Lookups returns the number of times key has been looked up since its
+// fault was last set or the Lookuper was created.
+func (l *Lookuper) Lookups(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lookups[key]
+}
+
+// Lookup implements envconfig.Lookuper.
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	return l.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements envconfig.ContextLookuper.
+func (l *Lookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	l.mu.Lock()
+	setKey(&l.lookups, key, l.lookups[key]+1)
+	n := l.lookups[key]
+	delay, ok := l.delays[key]
+	if !ok {
+		delay = l.delay
+	}
+	missing := l.missing[key]
+	if every := l.intermittent[key]; every > 0 && n%every == 0 {
+		missing = true
+	}
+	values, flapping := l.flapping[key]
+	l.mu.Unlock()
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return "", false
+		}
+	}
+	switch {
+	case missing:
+		return "", false
+	case flapping && len(values) > 0:
+		return values[(n-1)%len(values)], true
+	}
+	if cl, ok := l.next.(envconfig.ContextLookuper); ok {
+		return cl.LookupContext(ctx, key)
+	}
+	return l.next.Lookup(key)
+}
+
+// Keys implements envconfig.KeyLister when the wrapped Lookuper does, leaving
+// out keys that are missing.
+func (l *Lookuper) Keys() []string {
+	kl, ok := l.next.(envconfig.KeyLister)
+	if !ok {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var keys []string
+	for _, key := range kl.Keys() {
+		if !l.missing[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// String names the Lookuper in envconfig.Options.OnLookup reports.
+func (l *Lookuper) String() string {
+	if s, ok := l.next.(fmt.Stringer); ok {
+		return "fault:" + s.String()
+	}
+	return fmt.Sprintf("fault:%T", l.next)
+}
+
+// setKey sets m[key], allocating m first if needed.
+func setKey[V any](m *map[string]V, key string, value V) {
+	if *m == nil {
+		*m = make(map[string]V)
+	}
+	(*m)[key] = value
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package faultlookup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestLookuper(t *testing.T) {
+	l := New(envconfig.MapLookuper{"HOST": "db", "PORT": "5432"})
+	if v, ok := l.Lookup("HOST"); !ok || v != "db" {
+		t.Errorf("expected db, got %q", v)
+	}
+
+	l.Missing("HOST")
+	if _, ok := l.Lookup("HOST"); ok {
+		t.Error("expected HOST to be missing")
+	}
+	if keys := l.Keys(); len(keys) != 1 || keys[0] != "PORT" {
+		t.Errorf("expected only PORT to be listed, got %v", keys)
+	}
+
+	l.Intermittent("PORT", 3)
+	var found []bool
+	for i := 0; i < 6; i++ {
+		_, ok := l.Lookup("PORT")
+		found = append(found, ok)
+	}
+	if want := []bool{true, true, false, true, true, false}; !equalBools(found, want) {
+		t.Errorf("expected %v, got %v", want, found)
+	}
+
+	l.Flap("LEVEL", "info", "debug")
+	var values []string
+	for i := 0; i < 3; i++ {
+		v, _ := l.Lookup("LEVEL")
+		values = append(values, v)
+	}
+	if strings.Join(values, ",") != "info,debug,info" || l.Lookups("LEVEL") != 3 {
+		t.Errorf("expected the values to flap, got %v", values)
+	}
+
+	l.Reset()
+	keys := l.Keys()
+	sort.Strings(keys)
+	if v, ok := l.Lookup("HOST"); !ok || v != "db" || strings.Join(keys, ",") != "HOST,PORT" {
+		t.Errorf("expected the faults to be removed, got %q and %v", v, keys)
+	}
+}
+
+func TestSlow(t *testing.T) {
+	l := New(envconfig.MapLookuper{"MYAPP_HOST": "db"})
+	l.Slow(20*time.Millisecond, "MYAPP_HOST")
+
+	start := time.Now()
+	if v, ok := l.Lookup("MYAPP_HOST"); !ok || v != "db" {
+		t.Errorf("expected db, got %q", v)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected the lookup to be delayed")
+	}
+
+	var s struct {
+		Host string
+	}
+	l.Slow(time.Hour)
+	l.Reset("MYAPP_HOST")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := envconfig.ProcessContext(ctx, "myapp", &s, envconfig.Options{Lookuper: l})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func equalBools(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
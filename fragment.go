@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	fragmentsMu sync.RWMutex
+	fragments   = make(map[string]reflect.Value)
+	// fragmentOrder holds the names of fragments in registration order.
+	fragmentOrder []string
+)
+
+// RegisterFragment makes a reusable specification available under name. The
+// fragment is a struct value (or pointer to one) whose current field values
+// act as a prototype: every UseFragment call starts from a deep copy of it.
+// RegisterFragment panics if name is already registered or fragment is not a
+// struct, as it is meant to be called from init functions.
+func RegisterFragment(name string, fragment interface{}) {
+	v := reflect.ValueOf(fragment)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("envconfig: fragment %q must be a struct, got %T", name, fragment))
+	}
+
+	fragmentsMu.Lock()
+	defer fragmentsMu.Unlock()
+	if _, dup := fragments[name]; dup {
+		panic("envconfig: RegisterFragment called twice for fragment " + name)
+	}
+	fragments[name] = deepCopy(v)
+	fragmentOrder = append(fragmentOrder, name)
+}
+
+// Fragments returns the sorted names of the registered fragments.
+func Fragments() []string {
+	fragmentsMu.RLock()
+	defer fragmentsMu.RUnlock()
+	names := make([]string, 0, len(fragments))
+	for name := range fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseFragment processes a copy of the fragment registered under name using
+// prefix and returns a pointer to it.
+func UseFragment(name, prefix string) (interface{}, error) {
+	return UseFragmentWithOptions(name, prefix, Options{})
+}
+
+// UseFragmentWithOptions is like UseFragment() but with specified options.
+func UseFragmentWithOptions(name, prefix string, options Options) (interface{}, error) {
+	fragmentsMu.RLock()
+	proto, ok := fragments[name]
+	fragmentsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("envconfig: unknown fragment %q", name)
+	}
+
+	spec := reflect.New(proto.Type())
+	spec.Elem().Set(deepCopy(proto))
+	if err := ProcessWithOptions(prefix, spec.Interface(), options); err != nil {
+		return nil, err
+	}
+	return spec.Interface(), nil
+}
+
+// Use processes a new T using prefix, so one specification type can be read
+// for several sections, e.g. Use[RedisSpec]("CACHE") and
+// Use[RedisSpec]("SESSIONS"). If fragments of type T are registered, a deep
+// copy of the prototype of the first one registered is the starting point.
+func Use[T any](prefix string) (*T, error) {
+	return UseWithOptions[T](prefix, Options{})
+}
+
+// UseWithOptions is like Use() but with specified options.
+func UseWithOptions[T any](prefix string, options Options) (*T, error) {
+	spec := new(T)
+	typ := reflect.TypeOf(spec).Elem()
+
+	fragmentsMu.RLock()
+	for _, name := range fragmentOrder {
+		if proto := fragments[name]; proto.Type() == typ {
+			reflect.ValueOf(spec).Elem().Set(deepCopy(proto))
+			break
+		}
+	}
+	fragmentsMu.RUnlock()
+
+	if err := ProcessWithOptions(prefix, spec, options); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+type fragmentRedis struct {
+	Addr  string `default:"localhost:6379"`
+	DB    int
+	Pool  int
+	Hosts []string
+}
+
+func init() {
+	RegisterFragment("test_redis", fragmentRedis{Pool: 10, Hosts: []string{"a"}})
+	RegisterFragment("test_redis_other", fragmentRedis{Pool: 20})
+}
+
+func TestUse(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("CACHE_ADDR", "cache:6379")
+	os.Setenv("SESSIONS_DB", "2")
+
+	cache, err := Use[fragmentRedis]("CACHE")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	sessions, err := Use[fragmentRedis]("sessions")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cache.Addr != "cache:6379" || cache.DB != 0 || cache.Pool != 10 {
+		t.Errorf("unexpected cache values %+v", cache)
+	}
+	if sessions.Addr != "localhost:6379" || sessions.DB != 2 || sessions.Pool != 10 {
+		t.Errorf("unexpected sessions values %+v", sessions)
+	}
+
+	// the copies share nothing with the prototype
+	cache.Hosts[0] = "changed"
+	if sessions.Hosts[0] != "a" {
+		t.Errorf("expected the prototype's hosts, got %v", sessions.Hosts)
+	}
+}
+
+func TestUseFragment(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("CACHE_POOL", "50")
+
+	v, err := UseFragment("test_redis", "CACHE")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cache, ok := v.(*fragmentRedis)
+	if !ok {
+		t.Fatalf("expected *fragmentRedis, got %T", v)
+	}
+	if cache.Pool != 50 || cache.Addr != "localhost:6379" {
+		t.Errorf("unexpected cache values %+v", cache)
+	}
+
+	// the prototype is not modified by processing
+	v, _ = UseFragment("test_redis", "OTHER")
+	if v.(*fragmentRedis).Pool != 10 {
+		t.Errorf("expected prototype pool %d, got %d", 10, v.(*fragmentRedis).Pool)
+	}
+
+	if _, err := UseFragment("no_such_fragment", "CACHE"); err == nil {
+		t.Error("expected error for unknown fragment")
+	}
+}
+
+func TestRegisterFragmentPanics(t *testing.T) {
+	for name, fragment := range map[string]interface{}{"test_redis": fragmentRedis{}, "test_invalid": 42} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			RegisterFragment(name, fragment)
+		}()
+	}
+
+	found := false
+	for _, name := range Fragments() {
+		found = found || name == "test_redis"
+	}
+	if !found {
+		t.Errorf("expected test_redis in %v", Fragments())
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Frozen guards a processed specification that is shared between components.
+// Go cannot intercept writes to struct fields, so Frozen keeps a private deep
+// copy and compares the shared specification against it: Verify reports any
+// difference, and in builds with the envconfig_debug tag every accessor
+// panics as soon as a mutation is detected.
+type Frozen struct {
+	spec     reflect.Value
+	snapshot reflect.Value
+}
+
+// A MutationError reports the fields of a frozen specification that changed.
+type MutationError struct {
+	Fields []string
+}
+
+func (e *MutationError) Error() string {
+	return "envconfig: frozen specification mutated: " + strings.Join(e.Fields, ", ")
+}
+
+// Freeze records the current state of spec, which must be a struct pointer.
+func Freeze(spec interface{}) (*Frozen, error) {
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+	return &Frozen{spec: v.Elem(), snapshot: deepCopy(v.Elem())}, nil
+}
+
+// Value returns a deep copy of the frozen specification as a struct pointer.
+// Changing the copy does not affect the specification.
+func (f *Frozen) Value() interface{} {
+	f.debugVerify()
+	p := reflect.New(f.snapshot.Type())
+	p.Elem().Set(deepCopy(f.snapshot))
+	return p.Interface()
+}
+
+// Get returns a deep copy of the field at the dotted path, such as
+// "Database.Host".
+func (f *Frozen) Get(path string) (interface{}, error) {
+	f.debugVerify()
+	v := f.snapshot
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, fmt.Errorf("envconfig: %s: nil pointer", path)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("envconfig: %s: %s is not a struct", path, v.Type())
+		}
+		field, ok := v.Type().FieldByName(name)
+		if !ok || field.PkgPath != "" {
+			return nil, fmt.Errorf("envconfig: %s: no field %s", path, name)
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+	return deepCopy(v).Interface(), nil
+}
+
+// Verify returns a MutationError if the specification passed to Freeze has
+// changed since it was frozen.
+func (f *Frozen) Verify() error {
+	var changed []string
+	diffValues("", f.snapshot, f.spec, &changed)
+	if len(changed) > 0 {
+		return &MutationError{Fields: changed}
+	}
+	return nil
+}
+
+func (f *Frozen) debugVerify() {
+	if !freezeDebug {
+		return
+	}
+	if err := f.Verify(); err != nil {
+		panic(err)
+	}
+}
+
+// diffValues appends the paths of the exported fields that differ between a
+// and b to changed, descending into nested structs.
+func diffValues(path string, a, b reflect.Value, changed *[]string) {
+	if a.Kind() == reflect.Struct {
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			name := t.Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValues(name, a.Field(i), b.Field(i), changed)
+		}
+		return
+	}
+	if a.Kind() == reflect.Ptr && !a.IsNil() && !b.IsNil() && a.Elem().Kind() == reflect.Struct {
+		diffValues(path, a.Elem(), b.Elem(), changed)
+		return
+	}
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		*changed = append(*changed, path)
+	}
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices or maps with
+// it. Unexported struct fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p := reflect.New(v.Type().Elem())
+			p.Elem().Set(deepCopy(v.Elem()))
+			out.Set(p)
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				s.Index(i).Set(deepCopy(v.Index(i)))
+			}
+			out.Set(s)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			m := reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				m.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+			}
+			out.Set(m)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(deepCopy(v.Elem()))
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
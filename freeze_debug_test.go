@@ -0,0 +1,23 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build envconfig_debug
+// +build envconfig_debug
+
+package envconfig
+
+import "testing"
+
+func TestFreezeDebugPanics(t *testing.T) {
+	s := Specification{Port: 80}
+	f, _ := Freeze(&s)
+	s.Port = 81
+
+	defer func() {
+		if _, ok := recover().(*MutationError); !ok {
+			t.Error("expected MutationError panic")
+		}
+	}()
+	f.Get("Port")
+}
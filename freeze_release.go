@@ -0,0 +1,11 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build !envconfig_debug
+// +build !envconfig_debug
+
+package envconfig
+
+// freezeDebug makes Frozen accessors panic when a mutation is detected.
+const freezeDebug = false
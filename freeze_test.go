@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+	os.Setenv("ENV_CONFIG_ADMINUSERS", "john,adam")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	f, err := Freeze(&s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := f.Verify(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// copies can be changed freely
+	c := f.Value().(*Specification)
+	c.AdminUsers[0] = "mallory"
+	c.MapField["one"] = "changed"
+	users, err := f.Get("AdminUsers")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := []string{"john", "adam"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("expected %v, got %v", want, users)
+	}
+	inner, err := f.Get("NestedSpecification.PropertyWithDefault")
+	if err != nil || inner != "fuzzybydefault" {
+		t.Errorf("expected %q, got %v (%v)", "fuzzybydefault", inner, err)
+	}
+	if err := f.Verify(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the shared specification cannot
+	s.AdminUsers[1] = "eve"
+	s.NestedSpecification.Property = "changed"
+	err = f.Verify()
+	v, ok := err.(*MutationError)
+	if !ok {
+		t.Fatalf("expected MutationError, got %T %v", err, err)
+	}
+	if want := []string{"AdminUsers", "NestedSpecification.Property"}; !reflect.DeepEqual(v.Fields, want) {
+		t.Errorf("expected %v, got %v", want, v.Fields)
+	}
+}
+
+func TestFreezeErrors(t *testing.T) {
+	if _, err := Freeze(Specification{}); err != ErrInvalidSpecification {
+		t.Errorf("expected %v, got %v", ErrInvalidSpecification, err)
+	}
+	f, _ := Freeze(&Specification{})
+	for _, path := range []string{"Nope", "Port.Value", "UrlPointer.Value"} {
+		if _, err := f.Get(path); err == nil {
+			t.Errorf("%s: expected error, got nil", path)
+		}
+	}
+}
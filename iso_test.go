@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCountryCode(t *testing.T) {
+	var c CountryCode
+	if err := c.Decode(" de "); err != nil {
+		t.Fatal(err.Error())
+	}
+	if c != "DE" {
+		t.Errorf("expected %q, got %q", "DE", c)
+	}
+	for _, value := range []string{"UK", "USA", "XX", ""} {
+		if err := c.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %q", value, c)
+		}
+	}
+}
+
+func TestCurrencyCode(t *testing.T) {
+	var c CurrencyCode
+	if err := c.Decode("eur"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if c != "EUR" {
+		t.Errorf("expected %q, got %q", "EUR", c)
+	}
+	for _, value := range []string{"EU", "EURO", "ABC", "US$"} {
+		if err := c.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %q", value, c)
+		}
+	}
+}
+
+func TestProcessISOCodes(t *testing.T) {
+	var s struct {
+		DefaultCurrency  CurrencyCode `default:"usd"`
+		AllowedCountries []CountryCode
+	}
+	os.Clearenv()
+	os.Setenv("SHOP_ALLOWEDCOUNTRIES", "us,ca,mx")
+	if err := Process("shop", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DefaultCurrency != "USD" {
+		t.Errorf("expected %q, got %q", "USD", s.DefaultCurrency)
+	}
+	if len(s.AllowedCountries) != 3 || s.AllowedCountries[2] != "MX" {
+		t.Errorf("unexpected countries %v", s.AllowedCountries)
+	}
+
+	os.Setenv("SHOP_ALLOWEDCOUNTRIES", "us,uk")
+	if err := Process("shop", &s); err == nil {
+		t.Error("expected error for UK, got nil")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// jitterInt63n draws the random offsets of JitteredDuration.Next. It is a
+// variable so tests can make the draw predictable.
+var jitterInt63n = rand.Int63n
+
+// JitteredDuration is a base duration with a symmetric random jitter, for
+// retry and poll intervals. It decodes "30s±10%" (or the ASCII "30s+-10%"),
+// "30s±5s", or a plain duration without jitter.
+type JitteredDuration struct {
+	Base   time.Duration
+	Jitter time.Duration
+}
+
+// Decode implements Decoder.
+func (d *JitteredDuration) Decode(value string) error {
+	s := strings.TrimSpace(value)
+	base, jitter := s, ""
+	for _, sep := range []string{"±", "+-"} {
+		if i := strings.Index(s, sep); i >= 0 {
+			base, jitter = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(sep):])
+			break
+		}
+	}
+
+	b, err := time.ParseDuration(base)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", base)
+	}
+	if b < 0 {
+		return fmt.Errorf("duration %q is negative", base)
+	}
+
+	var j time.Duration
+	if strings.HasSuffix(jitter, "%") {
+		ratio, err := parseRatio(jitter)
+		if err != nil {
+			return err
+		}
+		j = time.Duration(math.Round(float64(b) * ratio))
+	} else if jitter != "" {
+		if j, err = time.ParseDuration(jitter); err != nil {
+			return fmt.Errorf("invalid jitter %q", jitter)
+		}
+		if j < 0 {
+			return fmt.Errorf("jitter %q is negative", jitter)
+		}
+	} else if s != base {
+		return fmt.Errorf("missing jitter in %q", value)
+	}
+
+	d.Base, d.Jitter = b, j
+	return nil
+}
+
+// Min returns the shortest duration Next can return.
+func (d JitteredDuration) Min() time.Duration {
+	if d.Jitter > d.Base {
+		return 0
+	}
+	return d.Base - d.Jitter
+}
+
+// Max returns the longest duration Next can return.
+func (d JitteredDuration) Max() time.Duration {
+	return d.Base + d.Jitter
+}
+
+// Next returns a duration drawn uniformly from [Min(), Max()].
+func (d JitteredDuration) Next() time.Duration {
+	if d.Jitter <= 0 {
+		return d.Base
+	}
+	return d.Min() + time.Duration(jitterInt63n(int64(d.Max()-d.Min())+1))
+}
+
+// String formats the duration as "30s±3s", or without jitter as "30s".
+func (d JitteredDuration) String() string {
+	if d.Jitter == 0 {
+		return d.Base.String()
+	}
+	return d.Base.String() + "±" + d.Jitter.String()
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJitteredDuration(t *testing.T) {
+	tests := []struct {
+		value        string
+		base, jitter time.Duration
+	}{
+		{"30s±10%", 30 * time.Second, 3 * time.Second},
+		{"30s +- 10%", 30 * time.Second, 3 * time.Second},
+		{"1m±5s", time.Minute, 5 * time.Second},
+		{"250ms", 250 * time.Millisecond, 0},
+	}
+	for _, tt := range tests {
+		var d JitteredDuration
+		if err := d.Decode(tt.value); err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if d.Base != tt.base || d.Jitter != tt.jitter {
+			t.Errorf("%q: expected %s±%s, got %s", tt.value, tt.base, tt.jitter, d)
+		}
+	}
+
+	for _, value := range []string{"", "fast", "30s±", "30s±lots", "30s±150%", "-1s", "1s±-1s"} {
+		var d JitteredDuration
+		if err := d.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %s", value, d)
+		}
+	}
+}
+
+func TestJitteredDurationNext(t *testing.T) {
+	defer func(saved func(int64) int64) { jitterInt63n = saved }(jitterInt63n)
+
+	d := JitteredDuration{Base: 10 * time.Second, Jitter: 2 * time.Second}
+	jitterInt63n = func(n int64) int64 { return 0 }
+	if got := d.Next(); got != 8*time.Second {
+		t.Errorf("expected %s, got %s", 8*time.Second, got)
+	}
+	jitterInt63n = func(n int64) int64 { return n - 1 }
+	if got := d.Next(); got != 12*time.Second {
+		t.Errorf("expected %s, got %s", 12*time.Second, got)
+	}
+
+	d = JitteredDuration{Base: time.Second, Jitter: 5 * time.Second}
+	if d.Min() != 0 || d.Max() != 6*time.Second {
+		t.Errorf("expected [0s, 6s], got [%s, %s]", d.Min(), d.Max())
+	}
+}
+
+func TestJitteredDurationField(t *testing.T) {
+	var s struct {
+		Poll  JitteredDuration `default:"30s±10%"`
+		Retry *JitteredDuration
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_RETRY", "1s±250ms")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Poll.String() != "30s±3s" {
+		t.Errorf("expected %q, got %q", "30s±3s", s.Poll.String())
+	}
+	if s.Retry == nil || s.Retry.Jitter != 250*time.Millisecond {
+		t.Errorf("expected 250ms jitter, got %v", s.Retry)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// isJSON reports whether the variable holds its value as JSON, as requested
+// by the `format:"json"` tag.
+func (info varInfo) isJSON() bool {
+	return info.Tags.Get("format") == "json"
+}
+
+// decodeJSON replaces the value of field with the JSON document in value.
+// Object keys that match no struct field and data after the document are
+// errors, so typos do not go unnoticed.
+func decodeJSON(value string, field reflect.Value) error {
+	v := reflect.New(field.Type())
+	dec := json.NewDecoder(bytes.NewReader([]byte(value)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v.Interface()); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid data after the JSON document")
+	}
+	field.Set(v.Elem())
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type jsonRoute struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+}
+
+func TestJSONFormat(t *testing.T) {
+	var s struct {
+		Routes  []jsonRoute             `format:"json"`
+		Limits  map[string]int          `format:"json" default:"{\"default\":10}"`
+		Feature *struct{ Enabled bool } `format:"json"`
+		Extra   map[string]interface{}  `format:"json"`
+	}
+	os.Clearenv()
+	os.Setenv("APP_ROUTES", `[{"path":"/api","methods":["GET","POST"]},{"path":"/health"}]`)
+	os.Setenv("APP_FEATURE", ` {"Enabled": true} `)
+	os.Setenv("APP_EXTRA", `{"a":[1,2],"b":{"c":null}}`)
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := []jsonRoute{{"/api", []string{"GET", "POST"}}, {Path: "/health"}}
+	if !reflect.DeepEqual(s.Routes, want) {
+		t.Errorf("expected %+v, got %+v", want, s.Routes)
+	}
+	if s.Limits["default"] != 10 {
+		t.Errorf("unexpected limits %v", s.Limits)
+	}
+	if s.Feature == nil || !s.Feature.Enabled {
+		t.Errorf("unexpected feature %+v", s.Feature)
+	}
+	if len(s.Extra) != 2 {
+		t.Errorf("unexpected extra %v", s.Extra)
+	}
+
+	// a value replaces the previous one rather than merging into it
+	os.Setenv("APP_LIMITS", `{"burst":5}`)
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(s.Limits, map[string]int{"burst": 5}) {
+		t.Errorf("unexpected limits %v", s.Limits)
+	}
+
+	for _, value := range []string{`[{"path":"/a","method":"GET"}]`, `[]x`, `{"path":"/a"}`} {
+		os.Setenv("APP_ROUTES", value)
+		var perr *ParseError
+		if err := Process("app", &s); !errors.As(err, &perr) || perr.KeyName != "APP_ROUTES" {
+			t.Errorf("%s: expected a ParseError for APP_ROUTES, got %v", value, err)
+		}
+	}
+}
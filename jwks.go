@@ -0,0 +1,273 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefresh is how long keys fetched from a JWKS URL are cached.
+const DefaultJWKSRefresh = time.Hour
+
+// jwksMinRefetch limits how often an unknown key ID triggers a fetch.
+const jwksMinRefetch = time.Minute
+
+// maxJWKSLen bounds the size of a fetched key set.
+const maxJWKSLen = 1 << 20
+
+// JWKS is a source of JWT verification keys: either an inline JSON Web Key
+// Set (RFC 7517) or the https URL of one, such as an OIDC provider's jwks_uri.
+// Inline sets are parsed by Decode. Sets behind a URL are fetched on first
+// use, cached for Refresh and fetched again early when a token names a key ID
+// that is not in the cache.
+//
+//	Keys envconfig.JWKS `envconfig:"JWKS" required:"true"`
+//
+// RSA, EC (P-256, P-384, P-521) and Ed25519 keys are supported; keys of other
+// types or curves and private key members are ignored.
+type JWKS struct {
+	// URL is the location of the key set, or empty for an inline set.
+	URL string
+	// Refresh is how long fetched keys are cached; DefaultJWKSRefresh is
+	// used when zero.
+	Refresh time.Duration
+	// Client is used for requests; http.DefaultClient is used when nil.
+	Client *http.Client
+
+	cache *jwksCache
+}
+
+type jwksCache struct {
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// Decode implements Decoder.
+func (j *JWKS) Decode(value string) error {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		keys, err := parseJWKS([]byte(value))
+		if err != nil {
+			return err
+		}
+		j.URL = ""
+		j.cache = &jwksCache{keys: keys}
+		return nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || u.Scheme != "https" {
+		return fmt.Errorf("invalid JWKS %q: expected a JSON document or an https URL", value)
+	}
+	j.URL = value
+	j.cache = &jwksCache{}
+	return nil
+}
+
+// Keys returns the keys of the set by key ID, fetching them if the set is
+// behind a URL and the cache is empty or stale.
+func (j *JWKS) Keys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	c, err := j.state()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if j.URL != "" && (c.keys == nil || time.Since(c.fetched) > j.refresh()) {
+		if err := j.fetch(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	return c.keys, nil
+}
+
+// Key returns the key with the given key ID. An unknown ID causes the set to
+// be fetched again, at most once a minute, to pick up rotated keys.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keys, err := j.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	c := j.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if j.URL != "" && time.Since(c.fetched) > jwksMinRefetch {
+		if err := j.fetch(ctx, c); err != nil {
+			return nil, err
+		}
+		if key, ok := c.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("envconfig: no JWKS key with ID %q", kid)
+}
+
+func (j *JWKS) state() (*jwksCache, error) {
+	if j.cache == nil {
+		return nil, errors.New("envconfig: JWKS has not been decoded")
+	}
+	return j.cache, nil
+}
+
+func (j *JWKS) refresh() time.Duration {
+	if j.Refresh > 0 {
+		return j.Refresh
+	}
+	return DefaultJWKSRefresh
+}
+
+// fetch replaces the cached keys with those at j.URL. c.mu must be held.
+func (j *JWKS) fetch(ctx context.Context, c *jwksCache) error {
+	client := j.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest("GET", j.URL, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("envconfig: fetching JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("envconfig: fetching JWKS from %s: %s", j.URL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSLen+1))
+	if err != nil {
+		return fmt.Errorf("envconfig: fetching JWKS: %v", err)
+	}
+	if len(body) > maxJWKSLen {
+		return fmt.Errorf("envconfig: fetching JWKS from %s: longer than %d bytes", j.URL, maxJWKSLen)
+	}
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return fmt.Errorf("envconfig: fetching JWKS from %s: %v", j.URL, err)
+	}
+	c.keys, c.fetched = keys, time.Now()
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the public keys of a JSON Web Key Set by key ID. Keys used
+// for encryption rather than signatures are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %d: %v", i, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid JWKS: no signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey returns the key, or nil for a key type or curve that is not
+// supported.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testJWKS(t *testing.T) string {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b64 := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	return fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"r1","use":"sig","n":%q,"e":%q},
+		{"kty":"EC","kid":"e1","crv":"P-256","x":%q,"y":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQ","e":"AQAB"},
+		{"kty":"oct","kid":"hmac","k":"c2VjcmV0"},
+		{"kty":"EC","kid":"k1","crv":"secp256k1","x":"AQ","y":"AQ"},
+		{"kty":"OKP","kid":"x1","crv":"X25519","x":"AQ"}
+	]}`, b64(rsaKey.N), b64(big.NewInt(int64(rsaKey.E))), b64(ecKey.X), b64(ecKey.Y))
+}
+
+func TestJWKSInline(t *testing.T) {
+	var s struct {
+		Keys JWKS
+	}
+	os.Clearenv()
+	os.Setenv("AUTH_KEYS", testJWKS(t))
+	if err := Process("auth", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	keys, err := s.Keys.Keys(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(keys))
+	}
+	if key, err := s.Keys.Key(context.Background(), "r1"); err != nil {
+		t.Error(err)
+	} else if _, ok := key.(*rsa.PublicKey); !ok {
+		t.Errorf("expected an RSA key, got %T", key)
+	}
+	if _, err := s.Keys.Key(context.Background(), "missing"); err == nil {
+		t.Error("expected error for unknown key ID, got nil")
+	}
+}
+
+func TestJWKSURL(t *testing.T) {
+	doc := testJWKS(t)
+	fetches := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		fmt.Fprint(w, doc)
+	}))
+	defer srv.Close()
+
+	j := JWKS{Client: srv.Client()}
+	if err := j.Decode(srv.URL + "/jwks.json"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if fetches != 0 {
+		t.Errorf("expected no fetch on decode, got %d", fetches)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := j.Key(context.Background(), "e1"); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected keys to be cached, got %d fetches", fetches)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	j.cache.keys = nil
+	if _, err := j.Keys(ctx); err == nil {
+		t.Error("expected error with a canceled context, got nil")
+	}
+
+	doc = strings.Repeat(" ", maxJWKSLen) + doc
+	j.cache.keys = nil
+	if _, err := j.Keys(context.Background()); err == nil || !strings.Contains(err.Error(), "longer than") {
+		t.Errorf("expected error for an oversized key set, got %v", err)
+	}
+}
+
+func TestJWKSErrors(t *testing.T) {
+	for _, value := range []string{"", "not a url", "ftp://example.com/jwks", "http://example.com/jwks", `{"keys":[]}`, `{"keys":`} {
+		var j JWKS
+		if err := j.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+	var j JWKS
+	if _, err := j.Keys(context.Background()); err == nil {
+		t.Error("expected error for an undecoded JWKS, got nil")
+	}
+}
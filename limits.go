@@ -0,0 +1,50 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A ValueTooLongError occurs when a value exceeds the `maxbytes` limit of its
+// field or Options.MaxValueLen. The value is left out of the message as it may
+// be sensitive, and is likely too long to be useful.
+type ValueTooLongError struct {
+	KeyName   string
+	FieldName string
+	Len       int
+	Max       int
+}
+
+func (e *ValueTooLongError) Error() string {
+	return fmt.Sprintf("envconfig.Process: value of %s for %s is %d bytes, the limit is %d", e.KeyName, e.FieldName, e.Len, e.Max)
+}
+
+// checkLength rejects a value longer than the field's `maxbytes` tag or, when
+// the tag is absent, options.MaxValueLen. A limit of zero means no limit.
+func (info varInfo) checkLength(value string, options Options) error {
+	max, err := info.maxLen(options)
+	if err != nil {
+		return err
+	}
+	if max > 0 && len(value) > max {
+		return &ValueTooLongError{KeyName: info.Key, FieldName: info.Name, Len: len(value), Max: max}
+	}
+	return nil
+}
+
+// maxLen returns the limit checkLength applies.
+func (info varInfo) maxLen(options Options) (int, error) {
+	max := options.MaxValueLen
+	if tag := info.Tags.Get("maxbytes"); tag != "" {
+		n, err := strconv.Atoi(tag)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("envconfig.Process: invalid maxbytes %q for %s", tag, info.Name)
+		}
+		max = n
+	}
+	return max, nil
+}
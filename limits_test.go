@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type limitSpecification struct {
+	Name string
+	Cert []byte `maxbytes:"16"`
+	Blob []byte `maxbytes:"0"`
+}
+
+func TestMaxBytes(t *testing.T) {
+	var s limitSpecification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_CERT", strings.Repeat("x", 17))
+	err := Process("env_config", &s)
+	lerr, ok := err.(*ValueTooLongError)
+	if !ok {
+		t.Fatalf("expected ValueTooLongError, got %T %v", err, err)
+	}
+	if lerr.KeyName != "ENV_CONFIG_CERT" || lerr.Len != 17 || lerr.Max != 16 {
+		t.Errorf("unexpected error %+v", lerr)
+	}
+	if strings.Contains(lerr.Error(), "xxx") {
+		t.Errorf("value leaked into error: %q", lerr.Error())
+	}
+
+	os.Setenv("ENV_CONFIG_CERT", strings.Repeat("x", 16))
+	if err := Process("env_config", &s); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMaxValueLen(t *testing.T) {
+	var s limitSpecification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "too long")
+	os.Setenv("ENV_CONFIG_BLOB", strings.Repeat("x", 64))
+	err := ProcessWithOptions("env_config", &s, Options{MaxValueLen: 4})
+	if lerr, ok := err.(*ValueTooLongError); !ok || lerr.KeyName != "ENV_CONFIG_NAME" {
+		t.Fatalf("expected ValueTooLongError for ENV_CONFIG_NAME, got %v", err)
+	}
+
+	// maxbytes:"0" lifts the global limit for the field.
+	os.Setenv("ENV_CONFIG_NAME", "ok")
+	if err := ProcessWithOptions("env_config", &s, Options{MaxValueLen: 4}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestInvalidMaxBytes(t *testing.T) {
+	var s struct {
+		Name string `maxbytes:"lots"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "x")
+	if err := Process("env_config", &s); err == nil {
+		t.Error("expected error for invalid maxbytes, got nil")
+	}
+}
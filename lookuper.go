@@ -0,0 +1,225 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Lookuper is a source of variable values other than the process
+// environment. Lookup reports whether key is set, like os.LookupEnv.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// KeyLister is implemented by Lookupers that can list the keys they hold.
+// Maps of structs, whose keys are not known in advance, are only populated
+// from the process environment and from Lookupers that implement it.
+type KeyLister interface {
+	Keys() []string
+}
+
+// ErrorLookuper is implemented by Lookupers, typically remote ones, whose
+// lookups can fail. Processing returns the error of a failed lookup rather
+// than treat the variable as unset and fall back to its default. ctx is the
+// context of ProcessContext, or context.Background().
+type ErrorLookuper interface {
+	Lookuper
+	LookupErr(ctx context.Context, key string) (string, bool, error)
+}
+
+// LookuperFunc adapts an ordinary function to a Lookuper.
+type LookuperFunc func(key string) (string, bool)
+
+// Lookup implements Lookuper.
+func (f LookuperFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// MapLookuper is a Lookuper backed by a map, for example of values fixed in a
+// test.
+type MapLookuper map[string]string
+
+// Lookup implements Lookuper.
+func (m MapLookuper) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Keys implements KeyLister.
+func (m MapLookuper) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// String names the Lookuper in Options.OnLookup reports.
+func (m MapLookuper) String() string {
+	return "map"
+}
+
+// DotenvLookuper reads a .env file of KEY=VALUE lines from r and returns a
+// Lookuper for its values. The syntax is that of `format:"dotenv"` fields.
+func DotenvLookuper(r io.Reader) (Lookuper, error) {
+	doc, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := parseDotenv(string(doc))
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: reading dotenv: %w", err)
+	}
+	return MapLookuper(pairs), nil
+}
+
+// ProcessWithLookuper is like Process() but reads values from l instead of
+// the process environment.
+func ProcessWithLookuper(prefix string, spec interface{}, l Lookuper) error {
+	return ProcessWithOptions(prefix, spec, Options{Lookuper: l})
+}
+
+// ProcessMap is like ProcessWithOptions() but reads values only from env, in
+// place of the process environment and of any Options.Lookuper. Unlike
+// setting the variables with os.Setenv, it does not affect the process or
+// other goroutines, which suits tests, event payloads and saved snapshots.
+func ProcessMap(prefix string, spec interface{}, env map[string]string, options Options) error {
+	options.Lookuper = MapLookuper(env)
+	return ProcessWithOptions(prefix, spec, options)
+}
+
+// OSLookuper returns a Lookuper backed by the process environment.
+func OSLookuper() Lookuper {
+	return osLookuper{}
+}
+
+type osLookuper struct{}
+
+// lookupEnv reads the process environment. os.LookupEnv, unlike os.Getenv,
+// tells a variable set to the empty string from one that is unset.
+var lookupEnv = os.LookupEnv
+
+func (osLookuper) Lookup(key string) (string, bool) {
+	return lookupEnv(key)
+}
+
+func (osLookuper) Keys() []string {
+	return environKeys()
+}
+
+func (osLookuper) String() string {
+	return "env"
+}
+
+// environKeys returns the names of the variables of the process environment.
+func environKeys() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(env))
+	for _, kv := range env {
+		if i := strings.Index(kv, "="); i > 0 {
+			keys = append(keys, kv[:i])
+		}
+	}
+	return keys
+}
+
+// MultiLookuper returns a Lookuper that asks each of lookupers in turn and
+// returns the first value found. It lists the keys of those that implement
+// KeyLister, passes the context of ProcessContext to those that implement
+// ContextLookuper, and stops at the first error of an ErrorLookuper.
+func MultiLookuper(lookupers ...Lookuper) Lookuper {
+	return multiLookuper(lookupers)
+}
+
+type multiLookuper []Lookuper
+
+func (m multiLookuper) Lookup(key string) (string, bool) {
+	for _, l := range m {
+		if value, ok := l.Lookup(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func (m multiLookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	for _, l := range m {
+		lookup := l.Lookup
+		if cl, ok := l.(ContextLookuper); ok {
+			lookup = func(key string) (string, bool) { return cl.LookupContext(ctx, key) }
+		}
+		if value, ok := lookup(key); ok {
+			return value, true
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", false
+}
+
+// LookupErr implements ErrorLookuper, returning the first error rather than
+// a value found by a later Lookuper.
+func (m multiLookuper) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	for _, l := range m {
+		value, ok, err := lookupWith(ctx, l, key)
+		if err != nil || ok {
+			return value, ok, err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", false, nil
+}
+
+func (m multiLookuper) Keys() []string {
+	var keys []string
+	for _, l := range m {
+		if kl, ok := l.(KeyLister); ok {
+			keys = append(keys, kl.Keys()...)
+		}
+	}
+	return keys
+}
+
+func (m multiLookuper) String() string {
+	names := make([]string, len(m))
+	for i, l := range m {
+		names[i] = sourceName(l)
+	}
+	return strings.Join(names, "+")
+}
+
+// lookupWith looks key up in l, passing ctx to an ErrorLookuper or
+// ContextLookuper. ctx may be nil outside ProcessContext.
+func lookupWith(ctx context.Context, l Lookuper, key string) (string, bool, error) {
+	if el, ok := l.(ErrorLookuper); ok {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return el.LookupErr(ctx, key)
+	}
+	if cl, ok := l.(ContextLookuper); ok && ctx != nil {
+		value, found := cl.LookupContext(ctx, key)
+		return value, found, nil
+	}
+	value, found := l.Lookup(key)
+	return value, found, nil
+}
+
+// sourceName names a Lookuper in OnLookup reports: its String method if it
+// has one, otherwise its type.
+func sourceName(l Lookuper) string {
+	if s, ok := l.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", l)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// A Manifest describes the variables a build of a program expects. It is
+// generated with WriteManifestFile, embedded in the binary with go:embed, and
+// printed by the flag PrintSchemaFlag registers, so operators can inspect any
+// build without its source.
+type Manifest struct {
+	Prefix string    `json:"prefix"`
+	Schema string    `json:"schema"`
+	Vars   []VarSpec `json:"vars"`
+}
+
+// NewManifest returns the manifest of a specification: its Describe output
+// and its SchemaHash.
+func NewManifest(prefix string, spec interface{}, options Options) (*Manifest, error) {
+	vars, err := DescribeWithOptions(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := SchemaHashWithOptions(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{Prefix: prefix, Schema: hash, Vars: vars}, nil
+}
+
+// marshalManifest returns the JSON of the manifest of a specification, as
+// written to the manifest file.
+func marshalManifest(prefix string, spec interface{}, options Options) ([]byte, error) {
+	m, err := NewManifest(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(doc, '\n'), nil
+}
+
+// WriteManifestFile writes the manifest of a specification to path as JSON,
+// leaving the file untouched when it is up to date. It is meant to be run
+// by go generate from a small program next to the one that embeds the file:
+//
+//	//go:generate go run ./internal/configschema
+//	//go:embed config-schema.json
+//	var configSchema []byte
+//
+// Defaults that depend on the operating system are those of the machine
+// running go generate.
+func WriteManifestFile(path, prefix string, spec interface{}, options Options) error {
+	doc, err := marshalManifest(prefix, spec, options)
+	if err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, doc) {
+		return nil
+	}
+	return os.WriteFile(path, doc, 0644)
+}
+
+// CheckManifest returns an error if manifest, as written by
+// WriteManifestFile, is not that of the specification, so a test can catch
+// a build whose embedded manifest was not regenerated.
+func CheckManifest(manifest []byte, prefix string, spec interface{}, options Options) error {
+	doc, err := marshalManifest(prefix, spec, options)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(manifest, doc) {
+		return fmt.Errorf("envconfig: the manifest of %s is out of date; run go generate", prefix)
+	}
+	return nil
+}
+
+// PrintSchemaFlag registers a -print-config-schema flag on fs that prints
+// manifest to standard output and exits when it is given, before the
+// remaining flags are parsed.
+func PrintSchemaFlag(fs *flag.FlagSet, manifest []byte) {
+	fs.Var(&schemaFlag{out: os.Stdout, manifest: manifest}, "print-config-schema", "print the configuration schema and exit")
+}
+
+type schemaFlag struct {
+	out      io.Writer
+	manifest []byte
+}
+
+func (f *schemaFlag) String() string   { return "false" }
+func (f *schemaFlag) IsBoolFlag() bool { return true }
+
+func (f *schemaFlag) Set(value string) error {
+	if ok, err := strconv.ParseBool(value); err != nil || !ok {
+		return err
+	}
+	f.out.Write(f.manifest)
+	exit(0)
+	return nil
+}
+
+// exit is replaced in tests.
+var exit = os.Exit
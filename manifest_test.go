@@ -0,0 +1,88 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	type spec struct {
+		Host string `required:"true" desc:"database host"`
+		Port int    `default:"5432"`
+	}
+	path := filepath.Join(t.TempDir(), "config-schema.json")
+	if err := WriteManifestFile(path, "myapp", &spec{}, Options{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var m Manifest
+	if err := json.Unmarshal(doc, &m); err != nil {
+		t.Fatal(err.Error())
+	}
+	hash, _ := SchemaHash("myapp", &spec{})
+	if m.Prefix != "myapp" || m.Schema != hash || len(m.Vars) != 2 || m.Vars[0].Key != "MYAPP_HOST" || !m.Vars[0].Required {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+
+	// an up to date file is left alone
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(path, old, old)
+	if err := WriteManifestFile(path, "myapp", &spec{}, Options{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if fi, _ := os.Stat(path); !fi.ModTime().Equal(old) {
+		t.Error("expected an up to date manifest not to be rewritten")
+	}
+
+	if err := CheckManifest(doc, "myapp", &spec{}, Options{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var changed struct {
+		Host string `required:"true" desc:"database host"`
+		Port uint16 `default:"5432"`
+	}
+	if err := CheckManifest(doc, "myapp", &changed, Options{}); err == nil {
+		t.Error("expected error for an out of date manifest, got nil")
+	}
+}
+
+func TestPrintSchemaFlag(t *testing.T) {
+	defer func(fn func(int)) { exit = fn }(exit)
+	code := -1
+	exit = func(c int) { code = c }
+
+	for _, args := range [][]string{{"-print-config-schema"}, {"-print-config-schema=true", "-v"}, {"-v"}} {
+		code = -1
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Bool("v", false, "verbose")
+		PrintSchemaFlag(fs, []byte(`{"prefix":"myapp"}`))
+		var buf bytes.Buffer
+		fs.Lookup("print-config-schema").Value.(*schemaFlag).out = &buf
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err.Error())
+		}
+		if args[0] == "-v" {
+			if code != -1 || buf.Len() != 0 {
+				t.Errorf("%v: expected nothing printed, got %q", args, buf.String())
+			}
+			continue
+		}
+		if code != 0 || buf.String() != `{"prefix":"myapp"}` {
+			t.Errorf("%v: expected the manifest and exit 0, got %q and %d", args, buf.String(), code)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the variables that processing the populated spec under
+// prefix would read to reproduce it, the inverse of Process. Keys follow the
+// `envconfig` and `split_words` tags, and slices and maps of structs are
+// written out element by element. Nil pointers are left out. Sensitive values
+// are included as they are, so the result suits a child process's
+// environment or a .env file written with WriteDotenv. spec is not modified.
+func Marshal(prefix string, spec interface{}) (map[string]string, error) {
+	return MarshalWithOptions(prefix, spec, Options{})
+}
+
+// MarshalWithOptions is like Marshal() but with specified options. When
+// several fields cannot be marshaled, the error names the first of them in
+// options.Order.
+func MarshalWithOptions(prefix string, spec interface{}, options Options) (map[string]string, error) {
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	options.fromValues = true
+	infos, err := gatherOrdered(prefix, deepCopy(v).Interface(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(infos))
+	for _, info := range infos {
+		value, ok, err := info.marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("envconfig.Marshal: %s: %v", info.Key, err)
+		}
+		if ok {
+			env[info.Key] = value
+		}
+	}
+	return env, nil
+}
+
+// WriteDotenv writes env to w as a .env file of double-quoted KEY="value"
+// lines sorted by key, as read by DotenvLookuper and docker compose.
+func WriteDotenv(w io.Writer, env map[string]string) error {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, quoteDotenv(env[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshal returns the value of the variable that makes processing reproduce
+// the field, or false for a nil pointer, which is left unset. Options must
+// have fromValues set, so slices and maps of structs are written out.
+func (info varInfo) marshal(options Options) (string, bool, error) {
+	field := info.Field
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return "", false, nil
+	}
+
+	var value string
+	var err error
+	switch {
+	case info.isJSON():
+		var b []byte
+		b, err = json.Marshal(field.Interface())
+		value = string(b)
+	case info.isDotenv():
+		value, err = marshalDotenv(field, options)
+	default:
+		value, err = formatValue(field, info.Delimiter, info.MapDelimiter)
+	}
+	if err == nil {
+		if unit := info.Tags.Get("unit"); unit != "" {
+			value, err = unapplyUnit(value, unit, field)
+		}
+	}
+	value = untransform(value, info.Tags.Get("transform"))
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// formatValue formats v as processField parses it.
+func formatValue(v reflect.Value, sep, kvSep string) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	typ := v.Type()
+
+	switch typ {
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	case ipNetType:
+		n := v.Interface().(net.IPNet)
+		return n.String(), nil
+	case mailAddressType:
+		a := v.Interface().(mail.Address)
+		return a.String(), nil
+	}
+
+	addr := v
+	if !v.CanAddr() {
+		addr = reflect.New(typ).Elem()
+		addr.Set(v)
+	}
+	switch {
+	case registeredDecoder(typ) != nil || decoderFrom(addr) != nil || setterFrom(addr) != nil:
+		if s, ok := marshalerFrom(addr); ok {
+			return s()
+		}
+		return "", fmt.Errorf("%s has a decoder but no String or MarshalText method", typ)
+	case textUnmarshaler(addr) != nil:
+		var m encoding.TextMarshaler
+		interfaceFrom(addr, func(i interface{}, ok *bool) { m, *ok = i.(encoding.TextMarshaler) })
+		if m == nil {
+			return "", fmt.Errorf("%s has no MarshalText method", typ)
+		}
+		b, err := m.MarshalText()
+		return string(b), err
+	case binaryUnmarshaler(addr) != nil:
+		var m encoding.BinaryMarshaler
+		interfaceFrom(addr, func(i interface{}, ok *bool) { m, *ok = i.(encoding.BinaryMarshaler) })
+		if m == nil {
+			return "", fmt.Errorf("%s has no MarshalBinary method", typ)
+		}
+		b, err := m.MarshalBinary()
+		return string(b), err
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, typ.Bits()), nil
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			item, err := formatItem(v.Index(i), sep, kvSep)
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return strings.Join(items, sep), nil
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k, err := formatItem(iter.Key(), sep, kvSep)
+			if err != nil {
+				return "", err
+			}
+			if isSetType(typ) {
+				pairs = append(pairs, k)
+				continue
+			}
+			if strings.Contains(k, kvSep) {
+				return "", fmt.Errorf("map key %q contains %q", k, kvSep)
+			}
+			e, err := formatItem(iter.Value(), sep, kvSep)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, k+kvSep+e)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, sep), nil
+	}
+	return "", fmt.Errorf("cannot marshal %s", typ)
+}
+
+// formatItem formats an item of a slice or map, which must not contain the
+// delimiter between items.
+func formatItem(v reflect.Value, sep, kvSep string) (string, error) {
+	s, err := formatValue(v, sep, kvSep)
+	if err == nil && strings.Contains(s, sep) {
+		err = fmt.Errorf("item %q contains %q", s, sep)
+	}
+	return s, err
+}
+
+// marshalerFrom returns the String or MarshalText method of v, preferring
+// String, which decoders such as Percent pair with Decode.
+func marshalerFrom(v reflect.Value) (func() (string, error), bool) {
+	var s fmt.Stringer
+	interfaceFrom(v, func(i interface{}, ok *bool) { s, *ok = i.(fmt.Stringer) })
+	if s != nil {
+		return func() (string, error) { return s.String(), nil }, true
+	}
+	var m encoding.TextMarshaler
+	interfaceFrom(v, func(i interface{}, ok *bool) { m, *ok = i.(encoding.TextMarshaler) })
+	if m != nil {
+		return func() (string, error) {
+			b, err := m.MarshalText()
+			return string(b), err
+		}, true
+	}
+	return nil, false
+}
+
+// marshalDotenv formats a nested struct as the KEY=VALUE document that
+// assignDotenv reads.
+func marshalDotenv(field reflect.Value, options Options) (string, error) {
+	inner := options
+	inner.Lookuper, inner.OnLookup = MapLookuper{}, nil
+	infos, err := gatherInfo("", field.Addr().Interface(), inner)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, info := range infos {
+		value, ok, err := info.marshal(inner)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", info.Key, err)
+		}
+		if ok {
+			fmt.Fprintf(&b, "%s=%s\n", info.Key, quoteDotenv(value))
+		}
+	}
+	return b.String(), nil
+}
+
+// quoteDotenv double quotes value as parseDotenv reads it.
+func quoteDotenv(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// unapplyUnit writes a number of bytes as a number of unit, the inverse of
+// applyUnit. Durations are written with their own units and need nothing.
+func unapplyUnit(value, unit string, field reflect.Value) (string, error) {
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == durationType {
+		return value, nil
+	}
+	m, ok := byteUnits[strings.ToLower(unit)]
+	if !ok {
+		return "", fmt.Errorf("unknown byte unit %q", unit)
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(n/m, 'g', -1, 64), nil
+}
+
+// untransform undoes the transformers that can be undone, base64, and
+// leaves the others, which are expected to be idempotent, in place.
+func untransform(value, names string) string {
+	list := strings.Split(names, ",")
+	for i := len(list) - 1; i >= 0; i-- {
+		if strings.TrimSpace(list[i]) == "base64" {
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	}
+	return value
+}
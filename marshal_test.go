@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	type spec struct {
+		LogLevel string `split_words:"true"`
+		Port     int
+		Timeout  time.Duration
+		Tags     []string
+		Token    string `envconfig:"API_TOKEN" sensitive:"true"`
+		Proxy    *string
+		Backends []struct {
+			Host string
+		}
+	}
+	s := spec{
+		LogLevel: "debug",
+		Port:     8080,
+		Timeout:  1500 * time.Millisecond,
+		Tags:     []string{"a", "b"},
+		Token:    "secret\nline",
+		Backends: []struct{ Host string }{{Host: "one"}, {Host: "two"}},
+	}
+	env, err := Marshal("myapp", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := map[string]string{
+		"MYAPP_LOG_LEVEL":       "debug",
+		"MYAPP_PORT":            "8080",
+		"MYAPP_TIMEOUT":         "1.5s",
+		"MYAPP_TAGS":            "a,b",
+		"MYAPP_API_TOKEN":       "secret\nline",
+		"MYAPP_BACKENDS_0_HOST": "one",
+		"MYAPP_BACKENDS_1_HOST": "two",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("expected %v, got %v", want, env)
+	}
+	if s.Proxy != nil {
+		t.Error("expected the spec not to be modified")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDotenv(&buf, env); err != nil {
+		t.Fatal(err.Error())
+	}
+	l, err := DotenvLookuper(&buf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var back spec
+	if err := ProcessWithLookuper("myapp", &back, l); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(back, s) {
+		t.Errorf("expected %+v after processing the .env file, got %+v", s, back)
+	}
+
+	var bad struct {
+		Tags []string
+	}
+	bad.Tags = []string{"a,b"}
+	if _, err := Marshal("myapp", &bad); err == nil {
+		t.Error("expected error for an item containing the delimiter, got nil")
+	}
+	var both struct {
+		Zones []string
+		Areas []string
+	}
+	both.Zones, both.Areas = []string{"a,b"}, []string{"c,d"}
+	if _, err := MarshalWithOptions("myapp", &both, Options{Order: OrderAlphabetical}); err == nil || !strings.Contains(err.Error(), "MYAPP_AREAS") {
+		t.Errorf("expected the error for MYAPP_AREAS first in alphabetical order, got %v", err)
+	}
+	if _, err := Marshal("myapp", s); err != ErrInvalidSpecification {
+		t.Errorf("expected ErrInvalidSpecification, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// A MatrixCase is an environment generated by Matrix for a table-driven test.
+type MatrixCase struct {
+	// Name describes the value the case exercises, e.g. "APP_PORT=max".
+	Name string
+
+	// Env holds the variables to set, e.g. through a MapLookuper.
+	Env map[string]string
+
+	// Valid reports whether the values respect the tags of the
+	// specification, so that processing is expected to succeed. Validate
+	// methods are not taken into account.
+	Valid bool
+}
+
+// Matrix returns test environments for spec exercising the boundaries of its
+// variables: the `min`, `max` and `oneof` values and the values just outside
+// them, the limits of sized integers, `maxbytes`, and optional variables both
+// set and unset. The first case sets only the required variables; every
+// other case varies one variable from it, so the number of cases grows with
+// the number of variables rather than with their product.
+//
+//	cases, _ := envconfig.Matrix("myapp", &Specification{})
+//	for _, c := range cases {
+//		var s Specification
+//		err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Lookuper: envconfig.MapLookuper(c.Env)})
+//		if (err == nil) != c.Valid { ... }
+//	}
+//
+// Variables of types with decoding methods cannot be sampled; Matrix fails
+// if such a variable is required and has no default.
+func Matrix(prefix string, spec interface{}) ([]MatrixCase, error) {
+	return MatrixWithOptions(prefix, spec, Options{})
+}
+
+// MatrixWithOptions is like Matrix() but with specified options.
+func MatrixWithOptions(prefix string, spec interface{}, options Options) ([]MatrixCase, error) {
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	infos, err := gatherInfo(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]string)
+	for _, info := range infos {
+		req := info.Tags.Get("required")
+		if !(isTrue(req) || options.Required && !isFalse(req)) || info.defaultValue() != "" {
+			continue
+		}
+		sample, ok := info.sample()
+		if !ok {
+			return nil, fmt.Errorf("envconfig.Matrix: no sample value for %s of type %s; give it a default", info.Key, info.Field.Type())
+		}
+		base[info.Key] = sample
+	}
+
+	cases := []MatrixCase{{Name: "base", Env: base, Valid: true}}
+	with := func(info varInfo, name, value string, valid bool) {
+		env := make(map[string]string, len(base)+1)
+		for k, v := range base {
+			env[k] = v
+		}
+		env[info.Key] = value
+		cases = append(cases, MatrixCase{Name: info.Key + name, Env: env, Valid: valid})
+	}
+
+	for _, info := range infos {
+		if _, inBase := base[info.Key]; inBase {
+			env := make(map[string]string, len(base))
+			for k, v := range base {
+				if k != info.Key {
+					env[k] = v
+				}
+			}
+			cases = append(cases, MatrixCase{Name: info.Key + " unset", Env: env})
+		} else if sample, ok := info.sample(); ok {
+			with(info, " set", sample, true)
+		}
+		if info.isDotenv() || info.isJSON() || implementsInterface(info.Field.Type()) {
+			continue
+		}
+
+		typ := info.Field.Type()
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if allowed := info.oneOf(); allowed != nil {
+			for _, v := range allowed {
+				with(info, "="+v, v, true)
+			}
+			with(info, " not in oneof", "not-"+allowed[0], false)
+		}
+		min, max := info.Tags.Get("min"), info.Tags.Get("max")
+		if min != "" {
+			with(info, "=min", min, true)
+			if below, ok := stepNumber(min, typ, -1); ok {
+				with(info, " below min", below, false)
+			}
+		}
+		if max != "" {
+			with(info, "=max", max, true)
+			if above, ok := stepNumber(max, typ, 1); ok {
+				with(info, " above max", above, false)
+			}
+		}
+		if min == "" && max == "" && typ.PkgPath() == "" {
+			for _, c := range typeBounds(typ) {
+				with(info, c.name, c.value, c.valid)
+			}
+		}
+		switch typ.Kind() {
+		case reflect.Bool:
+			with(info, "=true", "true", true)
+			with(info, "=false", "false", true)
+			with(info, " not a bool", "maybe", false)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			with(info, " not a number", "x", false)
+		case reflect.String:
+			if n, err := strconv.Atoi(info.Tags.Get("maxbytes")); err == nil && n > 0 && info.oneOf() == nil {
+				with(info, " at maxbytes", strings.Repeat("x", n), true)
+				with(info, " over maxbytes", strings.Repeat("x", n+1), false)
+			}
+		}
+	}
+	return cases, nil
+}
+
+// sample returns a value the variable accepts, preferring its default and
+// then the values allowed by its tags.
+func (info varInfo) sample() (string, bool) {
+	if def := info.defaultValue(); def != "" {
+		return def, true
+	}
+	if allowed := info.oneOf(); allowed != nil {
+		return allowed[0], true
+	}
+	if min := info.Tags.Get("min"); min != "" {
+		return min, true
+	}
+	if max := info.Tags.Get("max"); max != "" {
+		return max, true
+	}
+	if info.isDotenv() || info.isJSON() {
+		return "", false
+	}
+	return sampleOf(info.Field.Type(), info.Delimiter, info.MapDelimiter)
+}
+
+func sampleOf(typ reflect.Type, sep, kvSep string) (string, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == durationType {
+		return "1s", true
+	}
+	if implementsInterface(typ) {
+		return "", false
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "sample", true
+	case reflect.Bool:
+		return "true", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "1", true
+	case reflect.Float32, reflect.Float64:
+		return "1.5", true
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "sample", true
+		}
+		item, ok := sampleOf(typ.Elem(), sep, kvSep)
+		return item + sep + item, ok
+	case reflect.Map:
+		if isSetType(typ) {
+			return sampleOf(typ.Key(), sep, kvSep)
+		}
+		k, kok := sampleOf(typ.Key(), sep, kvSep)
+		v, vok := sampleOf(typ.Elem(), sep, kvSep)
+		return k + kvSep + v, kok && vok
+	}
+	return "", false
+}
+
+// stepNumber returns the number bound of type typ moved by the smallest step
+// in direction, or false if that overflows the type.
+func stepNumber(bound string, typ reflect.Type, direction int) (string, bool) {
+	v := reflect.New(typ).Elem()
+	if err := processField(bound, v); err != nil {
+		return "", false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int() + int64(direction)
+		if v.OverflowInt(n) || (direction < 0) != (n < v.Int()) {
+			return "", false
+		}
+		if typ == durationType {
+			return time.Duration(n).String(), true
+		}
+		return strconv.FormatInt(n, 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := v.Uint() + uint64(direction)
+		if v.OverflowUint(n) || (direction < 0) != (n < v.Uint()) {
+			return "", false
+		}
+		return strconv.FormatUint(n, 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float()+float64(direction), 'g', -1, typ.Bits()), true
+	}
+	return "", false
+}
+
+type boundCase struct {
+	name, value string
+	valid       bool
+}
+
+// typeBounds returns the cases at and beyond the range of an integer type.
+func typeBounds(typ reflect.Type) []boundCase {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := uint(typ.Bits())
+		max := uint64(1)<<(bits-1) - 1
+		return []boundCase{
+			{" at type minimum", "-" + strconv.FormatUint(max+1, 10), true},
+			{" at type maximum", strconv.FormatUint(max, 10), true},
+			{" overflows", strconv.FormatUint(max+1, 10), false},
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bits := uint(typ.Bits())
+		max := ^uint64(0) >> (64 - bits)
+		over := "18446744073709551616"
+		if bits < 64 {
+			over = strconv.FormatUint(max+1, 10)
+		}
+		return []boundCase{
+			{" at type minimum", "0", true},
+			{" at type maximum", strconv.FormatUint(max, 10), true},
+			{" overflows", over, false},
+			{" negative", "-1", false},
+		}
+	}
+	return nil
+}
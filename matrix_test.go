@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"testing"
+	"time"
+)
+
+type matrixSpecification struct {
+	Host    string        `required:"true" maxbytes:"16"`
+	Port    uint16        `default:"8080" min:"1"`
+	Level   string        `oneof:"debug,info,warn" default:"info"`
+	Workers int8          `required:"true"`
+	Timeout time.Duration `min:"1s" max:"1m"`
+	Debug   bool
+	Tags    []string `oneof:"a,b"`
+	Ratio   Percent
+}
+
+func TestMatrix(t *testing.T) {
+	cases, err := Matrix("app", &matrixSpecification{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if cases[0].Name != "base" || len(cases[0].Env) != 2 || cases[0].Env["APP_WORKERS"] != "1" {
+		t.Errorf("unexpected base case %+v", cases[0])
+	}
+
+	names := make(map[string]bool)
+	for _, c := range cases {
+		if names[c.Name] {
+			t.Errorf("duplicate case %s", c.Name)
+		}
+		names[c.Name] = true
+
+		var s matrixSpecification
+		err := ProcessWithOptions("app", &s, Options{Lookuper: MapLookuper(c.Env)})
+		if (err == nil) != c.Valid {
+			t.Errorf("%s %v: expected valid %v, got %v", c.Name, c.Env, c.Valid, err)
+		}
+	}
+	for _, name := range []string{
+		"APP_HOST unset", "APP_HOST over maxbytes", "APP_PORT=min", "APP_PORT below min",
+		"APP_LEVEL=warn", "APP_LEVEL not in oneof", "APP_WORKERS overflows", "APP_WORKERS at type minimum",
+		"APP_TIMEOUT=max", "APP_TIMEOUT above max", "APP_DEBUG not a bool", "APP_TAGS=b",
+	} {
+		if !names[name] {
+			t.Errorf("missing case %s", name)
+		}
+	}
+	if names["APP_RATIO set"] {
+		t.Error("unexpected case for a type with a decoder")
+	}
+
+	var bad struct {
+		Ratio Percent `required:"true"`
+	}
+	if _, err := Matrix("app", &bad); err == nil {
+		t.Error("expected error for a required variable without a sample, got nil")
+	}
+}
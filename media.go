@@ -0,0 +1,192 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// MediaType is a validated media type such as "application/json" or
+// "text/html; charset=utf-8". It decodes to the canonical lower case form.
+type MediaType string
+
+// Decode implements Decoder.
+func (m *MediaType) Decode(value string) error {
+	mt, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return fmt.Errorf("invalid media type %q: %v", value, err)
+	}
+	slash := strings.IndexByte(mt, '/')
+	if slash <= 0 || slash == len(mt)-1 || strings.Count(mt, "/") != 1 {
+		return fmt.Errorf("invalid media type %q: expected type/subtype", value)
+	}
+	*m = MediaType(mime.FormatMediaType(mt, params))
+	return nil
+}
+
+// Type returns the media type without parameters, e.g. "text/html".
+func (m MediaType) Type() string {
+	mt, _, _ := mime.ParseMediaType(string(m))
+	return mt
+}
+
+// Params returns the parameters of the media type, e.g. {"charset": "utf-8"}.
+func (m MediaType) Params() map[string]string {
+	_, params, _ := mime.ParseMediaType(string(m))
+	return params
+}
+
+// charsets holds the IANA names of the character sets in common use, keyed
+// by lower case name and alias.
+var charsets = map[string]string{
+	"utf-8": "UTF-8", "utf8": "UTF-8",
+	"utf-16": "UTF-16", "utf-16be": "UTF-16BE", "utf-16le": "UTF-16LE",
+	"utf-32": "UTF-32", "utf-32be": "UTF-32BE", "utf-32le": "UTF-32LE",
+	"us-ascii": "US-ASCII", "ascii": "US-ASCII",
+	"iso-8859-1": "ISO-8859-1", "latin1": "ISO-8859-1",
+	"iso-8859-2": "ISO-8859-2", "iso-8859-3": "ISO-8859-3", "iso-8859-4": "ISO-8859-4",
+	"iso-8859-5": "ISO-8859-5", "iso-8859-6": "ISO-8859-6", "iso-8859-7": "ISO-8859-7",
+	"iso-8859-8": "ISO-8859-8", "iso-8859-9": "ISO-8859-9", "iso-8859-10": "ISO-8859-10",
+	"iso-8859-13": "ISO-8859-13", "iso-8859-14": "ISO-8859-14", "iso-8859-15": "ISO-8859-15",
+	"iso-8859-16":  "ISO-8859-16",
+	"windows-1250": "windows-1250", "windows-1251": "windows-1251", "windows-1252": "windows-1252",
+	"windows-1253": "windows-1253", "windows-1254": "windows-1254", "windows-1255": "windows-1255",
+	"windows-1256": "windows-1256", "windows-1257": "windows-1257", "windows-1258": "windows-1258",
+	"koi8-r": "KOI8-R", "koi8-u": "KOI8-U",
+	"shift_jis": "Shift_JIS", "euc-jp": "EUC-JP", "iso-2022-jp": "ISO-2022-JP",
+	"gb2312": "GB2312", "gbk": "GBK", "gb18030": "GB18030", "big5": "Big5",
+	"euc-kr": "EUC-KR", "iso-2022-kr": "ISO-2022-KR",
+}
+
+// Charset is the IANA name of a character set in common use, such as
+// "UTF-8". Names are matched case-insensitively, a few aliases such as
+// "utf8" and "latin1" are accepted, and the canonical name is stored.
+type Charset string
+
+// Decode implements Decoder.
+func (c *Charset) Decode(value string) error {
+	name, ok := charsets[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return fmt.Errorf("unknown charset %q", value)
+	}
+	*c = Charset(name)
+	return nil
+}
+
+// LanguageTag is a well-formed BCP 47 language tag such as "en-US" or
+// "zh-Hant-TW". It decodes to the conventional case: lower case language,
+// title case script and upper case region. Only well-formedness is checked;
+// the subtags are not looked up in the IANA registry.
+type LanguageTag string
+
+// Decode implements Decoder.
+func (l *LanguageTag) Decode(value string) error {
+	tag, err := parseLanguageTag(value)
+	if err != nil {
+		return err
+	}
+	*l = LanguageTag(tag)
+	return nil
+}
+
+// Language returns the primary language subtag, e.g. "en".
+func (l LanguageTag) Language() string {
+	return strings.SplitN(string(l), "-", 2)[0]
+}
+
+// parseLanguageTag checks the langtag production of RFC 5646 and returns the
+// tag in conventional case.
+func parseLanguageTag(value string) (string, error) {
+	invalid := func(why string) (string, error) {
+		return "", fmt.Errorf("invalid language tag %q: %s", value, why)
+	}
+	subtags := strings.Split(strings.ToLower(strings.Replace(strings.TrimSpace(value), "_", "-", -1)), "-")
+	for _, s := range subtags {
+		if s == "" || len(s) > 8 || !isAlnum(s) {
+			return invalid("subtags must be 1 to 8 letters or digits")
+		}
+	}
+
+	i := 0
+	if subtags[0] == "x" {
+		if len(subtags) < 2 {
+			return invalid("empty private use tag")
+		}
+		return strings.Join(subtags, "-"), nil
+	}
+	if len(subtags[0]) < 2 || !isAlpha(subtags[0]) {
+		return invalid("language must be 2 to 8 letters")
+	}
+	i++
+	// extlang: up to three 3 letter subtags after a 2 or 3 letter language
+	for n := 0; n < 3 && len(subtags[0]) <= 3 && i < len(subtags) && len(subtags[i]) == 3 && isAlpha(subtags[i]); n++ {
+		i++
+	}
+	if i < len(subtags) && len(subtags[i]) == 4 && isAlpha(subtags[i]) {
+		subtags[i] = strings.ToUpper(subtags[i][:1]) + subtags[i][1:]
+		i++
+	}
+	if i < len(subtags) && (len(subtags[i]) == 2 && isAlpha(subtags[i]) || len(subtags[i]) == 3 && isDigits(subtags[i])) {
+		subtags[i] = strings.ToUpper(subtags[i])
+		i++
+	}
+	for i < len(subtags) && (len(subtags[i]) >= 5 || len(subtags[i]) == 4 && isDigits(subtags[i][:1])) {
+		i++
+	}
+	seen := make(map[string]bool)
+	for i < len(subtags) && len(subtags[i]) == 1 && subtags[i] != "x" {
+		if seen[subtags[i]] {
+			return invalid("duplicate extension " + subtags[i])
+		}
+		seen[subtags[i]] = true
+		i++
+		n := 0
+		for ; i < len(subtags) && len(subtags[i]) >= 2; i++ {
+			n++
+		}
+		if n == 0 {
+			return invalid("empty extension")
+		}
+	}
+	if i < len(subtags) && subtags[i] == "x" {
+		if i == len(subtags)-1 {
+			return invalid("empty private use section")
+		}
+		i = len(subtags)
+	}
+	if i != len(subtags) {
+		return invalid(fmt.Sprintf("unexpected subtag %q", subtags[i]))
+	}
+	return strings.Join(subtags, "-"), nil
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'a' || s[i] > 'z') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
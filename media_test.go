@@ -0,0 +1,96 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMediaType(t *testing.T) {
+	var m MediaType
+	if err := m.Decode("Text/HTML; Charset=utf-8"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if m != "text/html; charset=utf-8" {
+		t.Errorf("expected %q, got %q", "text/html; charset=utf-8", m)
+	}
+	if m.Type() != "text/html" || m.Params()["charset"] != "utf-8" {
+		t.Errorf("unexpected type %q or params %v", m.Type(), m.Params())
+	}
+	for _, value := range []string{"json", "application/", "/json", "application/json/x", "application/json; charset"} {
+		if err := m.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %q", value, m)
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	tests := map[string]Charset{"utf-8": "UTF-8", "UTF8": "UTF-8", "Latin1": "ISO-8859-1", "shift_jis": "Shift_JIS"}
+	for value, want := range tests {
+		var c Charset
+		if err := c.Decode(value); err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+		} else if c != want {
+			t.Errorf("%q: expected %q, got %q", value, want, c)
+		}
+	}
+	var c Charset
+	if err := c.Decode("utf-9"); err == nil {
+		t.Error("expected error for unknown charset, got nil")
+	}
+}
+
+func TestLanguageTag(t *testing.T) {
+	tests := map[string]LanguageTag{
+		"en":                 "en",
+		"EN-us":              "en-US",
+		"en_GB":              "en-GB",
+		"zh-hant-tw":         "zh-Hant-TW",
+		"es-419":             "es-419",
+		"zh-yue-HK":          "zh-yue-HK",
+		"de-CH-1996":         "de-CH-1996",
+		"sl-rozaj-biske":     "sl-rozaj-biske",
+		"en-US-u-ca-gregory": "en-US-u-ca-gregory",
+		"en-x-internal":      "en-x-internal",
+		"x-whatever":         "x-whatever",
+	}
+	for value, want := range tests {
+		var l LanguageTag
+		if err := l.Decode(value); err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+		} else if l != want {
+			t.Errorf("%q: expected %q, got %q", value, want, l)
+		}
+	}
+	for _, value := range []string{"", "e", "en-US-US", "en--us", "en-US-u", "en-a-b-c", "en-u-ca-u-nu", "en-x", "1en", "en-US-!"} {
+		var l LanguageTag
+		if err := l.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %q", value, l)
+		}
+	}
+}
+
+func TestMediaFields(t *testing.T) {
+	var s struct {
+		ContentType MediaType `default:"application/json"`
+		Charset     Charset   `default:"utf8"`
+		Languages   []LanguageTag
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LANGUAGES", "en-us,fr-ca")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.ContentType != "application/json" || s.Charset != "UTF-8" {
+		t.Errorf("unexpected specification %+v", s)
+	}
+	if len(s.Languages) != 2 || s.Languages[1] != "fr-CA" {
+		t.Errorf("unexpected languages %q", s.Languages)
+	}
+	if s.Languages[0].Language() != "en" {
+		t.Errorf("expected %q, got %q", "en", s.Languages[0].Language())
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding/binary"
+	"errors"
+	"syscall"
+)
+
+// systemMemory returns the physical memory of the host in bytes.
+func systemMemory() (uint64, error) {
+	s, err := syscall.Sysctl("hw.memsize")
+	if err != nil {
+		return 0, err
+	}
+	// Sysctl trims the trailing NUL byte, which may be part of the value
+	b := append([]byte(s), make([]byte, 8)...)[:8]
+	if len(s) == 0 {
+		return 0, errors.New("hw.memsize is empty")
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// cgroupMemoryLimits lists the files that may hold a container memory limit,
+// for cgroup v2 and v1 respectively.
+var cgroupMemoryLimits = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// systemMemory returns the memory available to the process in bytes: the
+// physical memory of the host, or the cgroup limit when that is lower.
+func systemMemory() (uint64, error) {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0, err
+	}
+	total := uint64(info.Totalram) * uint64(info.Unit)
+
+	for _, path := range cgroupMemoryLimits {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			// "max" means no limit
+			continue
+		}
+		if limit < total {
+			total = limit
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+package envconfig
+
+import "errors"
+
+// systemMemory is not available on this platform.
+func systemMemory() (uint64, error) {
+	return 0, errors.New("total memory is not available on this platform")
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// lenientNumber normalizes value for a field tagged `lenient_numbers:"true"`.
+// Values for fields that are not numbers are returned unchanged.
+func lenientNumber(value string, field reflect.Value) string {
+	if !isNumericField(field) {
+		return value
+	}
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	integer := typ.Kind() != reflect.Float32 && typ.Kind() != reflect.Float64
+	return normalizeNumber(value, integer)
+}
+
+// normalizeNumber rewrites a number written with locale conventions into the
+// form accepted by strconv. Underscores and spaces are dropped. For integers
+// "." and "," are always digit grouping; otherwise the separator appearing
+// last is the decimal point when it appears once and every other occurrence
+// is digit grouping. Grouped digits must come in groups of three after the
+// first; a value that is not grouped that way, such as "1.5" for an integer,
+// is returned with only underscores and spaces dropped, so it fails to parse:
+//
+//	1_000_000     -> 1000000
+//	1 000,5       -> 1000.5
+//	1.000.000,25  -> 1000000.25
+//	1,000,000.25  -> 1000000.25
+//	1,000,000     -> 1000000
+func normalizeNumber(value string, integer bool) string {
+	value = strings.Map(func(r rune) rune {
+		switch r {
+		case '_', ' ', ' ', ' ':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(value))
+
+	dot, comma := strings.LastIndex(value, "."), strings.LastIndex(value, ",")
+	decimal := -1
+	switch {
+	case integer:
+	case dot > comma && strings.Count(value, ".") == 1:
+		decimal = dot
+	case comma > dot && strings.Count(value, ",") == 1:
+		decimal = comma
+	}
+
+	whole := value
+	if decimal >= 0 {
+		whole = value[:decimal]
+	}
+	digits, ok := ungroup(whole)
+	if !ok {
+		return value
+	}
+	if decimal >= 0 {
+		return digits + "." + value[decimal+1:]
+	}
+	return digits
+}
+
+// ungroup removes the "." or "," digit grouping of s, and reports whether it
+// is well formed: a single separator, with one to three digits before the
+// first and three between and after the others.
+func ungroup(s string) (string, bool) {
+	i := strings.IndexAny(s, ".,")
+	if i < 0 {
+		return s, true
+	}
+	groups := strings.Split(s, s[i:i+1])
+	if first := strings.TrimLeft(groups[0], "+-"); len(first) == 0 || len(first) > 3 {
+		return s, false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 || strings.ContainsAny(g, ".,") {
+			return s, false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		value   string
+		integer bool
+		want    string
+	}{
+		{"1_000_000", true, "1000000"},
+		{"1,5", false, "1.5"},
+		{"1 000,5", false, "1000.5"},
+		{"1 000,5", false, "1000.5"},
+		{"1.000.000,25", false, "1000000.25"},
+		{"1,000,000.25", false, "1000000.25"},
+		{"1,000,000", false, "1000000"},
+		{"1.000.000", false, "1000000"},
+		{"1,000", true, "1000"},
+		{"1.000", true, "1000"},
+		{"-2,5", false, "-2.5"},
+		{"0.5", false, "0.5"},
+		{"1.5", true, "1.5"},
+		{"1,5", true, "1,5"},
+		{"1,50,000", true, "1,50,000"},
+		{"1.000,000", true, "1.000,000"},
+		{"12,34.5", false, "12,34.5"},
+		{"1234.567,5", false, "1234.567,5"},
+	}
+	for _, tt := range tests {
+		if got := normalizeNumber(tt.value, tt.integer); got != tt.want {
+			t.Errorf("normalizeNumber(%q, %v): expected %q, got %q", tt.value, tt.integer, tt.want, got)
+		}
+	}
+}
+
+func TestLenientNumbers(t *testing.T) {
+	var s struct {
+		Limit   int64    `lenient_numbers:"true"`
+		Ratio   float64  `lenient_numbers:"true"`
+		Pointer *float32 `lenient_numbers:"true"`
+		Label   string   `lenient_numbers:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LIMIT", "1.000.000")
+	os.Setenv("ENV_CONFIG_RATIO", "1,5")
+	os.Setenv("ENV_CONFIG_POINTER", "2 500,25")
+	os.Setenv("ENV_CONFIG_LABEL", "1,5")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Limit != 1000000 {
+		t.Errorf("expected %d, got %d", 1000000, s.Limit)
+	}
+	if s.Ratio != 1.5 {
+		t.Errorf("expected %v, got %v", 1.5, s.Ratio)
+	}
+	if s.Pointer == nil || *s.Pointer != 2500.25 {
+		t.Errorf("expected %v, got %v", 2500.25, s.Pointer)
+	}
+	if s.Label != "1,5" {
+		t.Errorf("expected %q, got %q", "1,5", s.Label)
+	}
+}
+
+func TestLenientNumbersDefault(t *testing.T) {
+	var s struct {
+		Limit   int     `lenient_numbers:"true" default:"1,000"`
+		Ratio   float64 `lenient_numbers:"true" default:"2 500,5"`
+		Workers int     `lenient_numbers:"true" default:"max(numcpu, 2)"`
+	}
+	os.Clearenv()
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Limit != 1000 || s.Ratio != 2500.5 || s.Workers < 2 {
+		t.Errorf("unexpected values %+v", s)
+	}
+}
+
+func TestLenientNumbersDecimalInteger(t *testing.T) {
+	var s struct {
+		Limit int `lenient_numbers:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LIMIT", "1.5")
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
+
+func TestLenientNumbersOptIn(t *testing.T) {
+	var s struct {
+		Ratio float64
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_RATIO", "1,5")
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
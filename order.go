@@ -0,0 +1,32 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "sort"
+
+// Order controls the order in which generated output such as Usage, Envrc and
+// Environ lists variables.
+type Order int
+
+const (
+	// OrderDeclaration lists variables in the order their fields are
+	// declared, with the fields of a nested struct in place of the struct.
+	OrderDeclaration Order = iota
+	// OrderAlphabetical lists variables sorted by key.
+	OrderAlphabetical
+)
+
+// gatherOrdered is like gatherInfo but orders the variables as requested by
+// options.Order. Output is deterministic for either order.
+func gatherOrdered(prefix string, spec interface{}, options Options) ([]varInfo, error) {
+	infos, err := gatherInfo(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+	if options.Order == OrderAlphabetical {
+		sort.SliceStable(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	}
+	return infos, nil
+}
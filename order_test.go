@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+type orderSpecification struct {
+	Zeta  string
+	Inner struct {
+		Beta  string
+		Alpha string
+	}
+	Gamma string
+}
+
+func TestOrder(t *testing.T) {
+	var s orderSpecification
+	os.Clearenv()
+	tests := map[Order]string{
+		OrderDeclaration:  "APP_ZETA APP_INNER_BETA APP_INNER_ALPHA APP_GAMMA ",
+		OrderAlphabetical: "APP_GAMMA APP_INNER_ALPHA APP_INNER_BETA APP_ZETA ",
+	}
+	for order, want := range tests {
+		for i := 0; i < 3; i++ {
+			buf := new(bytes.Buffer)
+			err := UsagefWithOptions("app", &s, buf, "{{range .}}{{usage_key .}} {{end}}", Options{Order: order})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if buf.String() != want {
+				t.Errorf("order %d: expected %q, got %q", order, want, buf.String())
+			}
+		}
+	}
+}
+
+func TestOrderEnviron(t *testing.T) {
+	var s orderSpecification
+	os.Clearenv()
+	os.Setenv("APP_ZETA", "z")
+	os.Setenv("APP_INNER_ALPHA", "a")
+	env, err := EnvironWithOptions("app", &s, Options{Order: OrderAlphabetical})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(env) != 2 || env[0] != "APP_INNER_ALPHA=a" || env[1] != "APP_ZETA=z" {
+		t.Errorf("unexpected environment %q", env)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OrderedMap is a map that remembers the order in which its pairs were
+// written in the environment value, e.g. "primary:10,secondary:20". Pairs
+// are separated like those of plain maps, by the `delimiter` and
+// `map_delimiter` tags or Options.Delimiter and Options.MapDelimiter.
+type OrderedMap[K comparable, V any] struct {
+	keys   []K
+	values map[K]V
+}
+
+// Decode implements Decoder, with the default delimiters.
+func (m *OrderedMap[K, V]) Decode(value string) error {
+	return m.decodeSep(value, ",", ":")
+}
+
+// decodeSep implements sepDecoder. A value may contain kvSep, as in
+// plain maps.
+func (m *OrderedMap[K, V]) decodeSep(value, sep, kvSep string) error {
+	keys := []K{}
+	values := map[K]V{}
+	if len(strings.TrimSpace(value)) != 0 {
+		for _, pair := range strings.Split(value, sep) {
+			kvpair := strings.SplitN(pair, kvSep, 2)
+			if len(kvpair) != 2 {
+				return fmt.Errorf("invalid map item: %q", pair)
+			}
+			var k K
+			if err := processField(kvpair[0], reflect.ValueOf(&k).Elem()); err != nil {
+				return err
+			}
+			if _, ok := values[k]; ok {
+				return fmt.Errorf("duplicate map key: %q", kvpair[0])
+			}
+			var v V
+			if err := processField(kvpair[1], reflect.ValueOf(&v).Elem()); err != nil {
+				return err
+			}
+			keys = append(keys, k)
+			values[k] = v
+		}
+	}
+	m.keys, m.values = keys, values
+	return nil
+}
+
+// Keys returns the keys in the order they were written.
+func (m OrderedMap[K, V]) Keys() []K {
+	return append([]K(nil), m.keys...)
+}
+
+// Get returns the value stored under k.
+func (m OrderedMap[K, V]) Get(k K) (V, bool) {
+	v, ok := m.values[k]
+	return v, ok
+}
+
+// Len returns the number of pairs in the map.
+func (m OrderedMap[K, V]) Len() int {
+	return len(m.keys)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var s struct {
+		Upstreams OrderedMap[string, int]
+		Timeouts  OrderedMap[string, time.Duration] `default:"b:1s,a:2s"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_UPSTREAMS", "zeta:3,alpha:1,mid:2")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(s.Upstreams.Keys(), want) {
+		t.Errorf("expected %v, got %v", want, s.Upstreams.Keys())
+	}
+	if v, ok := s.Upstreams.Get("alpha"); !ok || v != 1 {
+		t.Errorf("expected %d, got %d", 1, v)
+	}
+	if _, ok := s.Upstreams.Get("missing"); ok {
+		t.Error("expected missing key to be absent")
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(s.Timeouts.Keys(), want) {
+		t.Errorf("expected %v, got %v", want, s.Timeouts.Keys())
+	}
+	if v, _ := s.Timeouts.Get("a"); v != 2*time.Second {
+		t.Errorf("expected %s, got %s", 2*time.Second, v)
+	}
+}
+
+func TestOrderedMapDelimiters(t *testing.T) {
+	var s struct {
+		Links  OrderedMap[string, string] `delimiter:";" map_delimiter:"="`
+		Hosts  OrderedMap[string, string]
+		Weight OrderedMap[string, int]
+	}
+	env := map[string]string{
+		"APP_LINKS":  "docs=https://example.com:8443/docs;home=https://example.com",
+		"APP_HOSTS":  "db=postgres://db:5432",
+		"APP_WEIGHT": "a=1|b=2",
+	}
+	if err := ProcessMap("app", &s, env, Options{Delimiter: "|", MapDelimiter: "="}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := s.Links.Get("docs"); v != "https://example.com:8443/docs" || s.Links.Len() != 2 {
+		t.Errorf("unexpected links %v", s.Links.Keys())
+	}
+	if v, _ := s.Weight.Get("b"); v != 2 {
+		t.Errorf("expected %d, got %d", 2, v)
+	}
+
+	if err := ProcessMap("app", &s, env, Options{}); err == nil {
+		t.Error("expected error for pairs without the default delimiter, got nil")
+	}
+	env["APP_HOSTS"], env["APP_WEIGHT"] = "db:postgres://db:5432", "a:1,b:2"
+	if err := ProcessMap("app", &s, env, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, _ := s.Hosts.Get("db"); v != "postgres://db:5432" {
+		t.Errorf("expected a value containing the map delimiter, got %q", v)
+	}
+}
+
+func TestOrderedMapErrors(t *testing.T) {
+	for _, value := range []string{"a:1,a:2", "a", "a:x"} {
+		var m OrderedMap[string, int]
+		if err := m.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+
+	var m OrderedMap[string, int]
+	if err := m.Decode(""); err != nil || m.Len() != 0 {
+		t.Errorf("expected empty map, got %v (%v)", m.Keys(), err)
+	}
+}
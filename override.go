@@ -0,0 +1,144 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers read by OverrideMiddleware and set by SignOverride.
+// OverrideHeader holds the variables to override, URL-encoded like a query
+// string: "MYAPP_FEATURE_X=true&MYAPP_TIMEOUT=2s". OverrideTimestampHeader
+// holds the time of signing in Unix seconds. OverrideSignatureHeader holds
+// the hex-encoded HMAC-SHA256 of the timestamp, the method, the request
+// target and the overrides, each followed by a newline but the last.
+const (
+	OverrideHeader          = "X-Envconfig-Override"
+	OverrideTimestampHeader = "X-Envconfig-Timestamp"
+	OverrideSignatureHeader = "X-Envconfig-Signature"
+)
+
+// OverrideMaxAge is how far the timestamp of a signed override may be from
+// the time the request is served.
+const OverrideMaxAge = 5 * time.Minute
+
+type overrideKey struct{}
+
+// OverrideMiddleware lets requests signed with key, as by SignOverride,
+// override the fields of spec tagged both `reloadable:"true"` and
+// `overridable:"true"` in a copy of it for the duration of the request,
+// which handlers get with Overridden. Requests without an OverrideHeader see
+// spec itself. A bad signature, or one made for another method or request
+// target or more than OverrideMaxAge from now, is rejected with 403
+// Forbidden, and a variable that is not overridable or a value that does
+// not decode or validate with 400 Bad Request.
+//
+// It is meant for experiments in staging environments. Unless the program is
+// built with the envconfig_override tag it returns next, which ignores the
+// headers, so it cannot be used in production builds by mistake.
+func OverrideMiddleware[T any](prefix string, spec *T, key []byte, next http.Handler) http.Handler {
+	if len(key) == 0 {
+		panic("envconfig: OverrideMiddleware called without a key")
+	}
+	if !overridesEnabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get(OverrideHeader)
+		if raw == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ts := r.Header.Get(OverrideTimestampHeader)
+		sig, err := hex.DecodeString(r.Header.Get(OverrideSignatureHeader))
+		if err != nil || !hmac.Equal(sig, overrideMAC(key, ts, r, raw)) {
+			http.Error(w, "invalid override signature", http.StatusForbidden)
+			return
+		}
+		sec, _ := strconv.ParseInt(ts, 10, 64)
+		if age := time.Since(time.Unix(sec, 0)); age > OverrideMaxAge || age < -OverrideMaxAge {
+			http.Error(w, "stale override signature", http.StatusForbidden)
+			return
+		}
+		copied, err := overrideSpec(prefix, spec, raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), overrideKey{}, copied)))
+	})
+}
+
+// SignOverride sets the headers that make OverrideMiddleware apply overrides,
+// URL-encoded like a query string, to r, signed with key for its method and
+// target at the current time.
+func SignOverride(r *http.Request, key []byte, overrides string) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(OverrideHeader, overrides)
+	r.Header.Set(OverrideTimestampHeader, ts)
+	r.Header.Set(OverrideSignatureHeader, hex.EncodeToString(overrideMAC(key, ts, r, overrides)))
+}
+
+// overrideMAC returns the signature of overrides for r made at ts.
+func overrideMAC(key []byte, ts string, r *http.Request, overrides string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join([]string{ts, r.Method, r.URL.RequestURI(), overrides}, "\n")))
+	return mac.Sum(nil)
+}
+
+// Overridden returns the copy of spec with the overrides of the request
+// whose context is ctx, or spec when the request has none.
+func Overridden[T any](ctx context.Context, spec *T) *T {
+	if s, ok := ctx.Value(overrideKey{}).(*T); ok {
+		return s
+	}
+	return spec
+}
+
+// overrideSpec returns a copy of spec with the variables in raw assigned.
+func overrideSpec[T any](prefix string, spec *T, raw string) (*T, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overrides: %v", err)
+	}
+	copied := deepCopy(reflect.ValueOf(spec)).Interface().(*T)
+	options := Options{Lookuper: MapLookuper{}}
+	infos, err := gatherInfo(prefix, copied, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		vs, ok := values[info.Key]
+		if !ok {
+			continue
+		}
+		delete(values, info.Key)
+		if !isTrue(info.Tags.Get("reloadable")) || !isTrue(info.Tags.Get("overridable")) {
+			return nil, fmt.Errorf("%s cannot be overridden", info.Key)
+		}
+		if err := info.assign(vs[len(vs)-1], options); err != nil {
+			return nil, err
+		}
+	}
+	for key := range values {
+		return nil, fmt.Errorf("unknown variable %s", key)
+	}
+	commitInfos(infos)
+	if err := validateSpec(copied, nil); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build !envconfig_override
+// +build !envconfig_override
+
+package envconfig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOverrideMiddlewareDisabled(t *testing.T) {
+	key := []byte("staging")
+	type spec struct {
+		Timeout time.Duration `reloadable:"true" overridable:"true"`
+	}
+	s := &spec{Timeout: time.Second}
+	var seen *spec
+	h := OverrideMiddleware("app", s, key, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = Overridden(r.Context(), s)
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	SignOverride(r, key, "APP_TIMEOUT=5s")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || seen != s {
+		t.Errorf("expected overrides to be ignored without the envconfig_override tag, got %d, %+v", w.Code, seen)
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build envconfig_override
+// +build envconfig_override
+
+package envconfig
+
+// overridesEnabled makes OverrideMiddleware apply signed overrides.
+const overridesEnabled = true
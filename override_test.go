@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build envconfig_override
+// +build envconfig_override
+
+package envconfig
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type overrideSpecification struct {
+	Timeout  time.Duration `reloadable:"true" overridable:"true"`
+	FeatureX bool          `split_words:"true" reloadable:"true" overridable:"true"`
+	Port     int           `reloadable:"true"`
+	Limits   struct {
+		Burst int `reloadable:"true" overridable:"true"`
+	}
+}
+
+func TestOverrideMiddleware(t *testing.T) {
+	key := []byte("staging")
+	spec := &overrideSpecification{Timeout: time.Second, Port: 8080}
+	spec.Limits.Burst = 10
+	var seen *overrideSpecification
+	h := OverrideMiddleware("app", spec, key, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = Overridden(r.Context(), spec)
+	}))
+	do := func(r *http.Request) int {
+		seen = nil
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	serve := func(overrides string, sign bool) int {
+		r := httptest.NewRequest("GET", "/", nil)
+		if overrides != "" {
+			signKey := key
+			if !sign {
+				signKey = []byte("other")
+			}
+			SignOverride(r, signKey, overrides)
+		}
+		return do(r)
+	}
+
+	if code := serve("", false); code != http.StatusOK || seen != spec {
+		t.Errorf("expected the spec itself, got %d, %p", code, seen)
+	}
+	if code := serve("APP_TIMEOUT=5s&APP_FEATURE_X=true&APP_LIMITS_BURST=3", true); code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, code)
+	}
+	if seen == spec || seen.Timeout != 5*time.Second || !seen.FeatureX || seen.Limits.Burst != 3 || seen.Port != 8080 {
+		t.Errorf("unexpected overridden spec %+v", seen)
+	}
+	if spec.Timeout != time.Second || spec.FeatureX || spec.Limits.Burst != 10 {
+		t.Errorf("the spec was modified: %+v", spec)
+	}
+
+	for overrides, want := range map[string]int{
+		"APP_PORT=9090":     http.StatusBadRequest,
+		"APP_UNKNOWN=1":     http.StatusBadRequest,
+		"APP_TIMEOUT=never": http.StatusBadRequest,
+	} {
+		if code := serve(overrides, true); code != want || seen != nil {
+			t.Errorf("%s: expected %d, got %d", overrides, want, code)
+		}
+	}
+	if code := serve("APP_TIMEOUT=5s", false); code != http.StatusForbidden || seen != nil {
+		t.Errorf("expected %d for a bad signature, got %d", http.StatusForbidden, code)
+	}
+
+	// a signature does not carry over to another request target or method
+	signed := httptest.NewRequest("GET", "/a?x=1", nil)
+	SignOverride(signed, key, "APP_TIMEOUT=5s")
+	for _, target := range []string{"/a?x=2", "/b"} {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header = signed.Header
+		if code := do(r); code != http.StatusForbidden {
+			t.Errorf("%s: expected %d for a replayed signature, got %d", target, http.StatusForbidden, code)
+		}
+	}
+	r := httptest.NewRequest("POST", "/a?x=1", nil)
+	r.Header = signed.Header
+	if code := do(r); code != http.StatusForbidden {
+		t.Errorf("expected %d for another method, got %d", http.StatusForbidden, code)
+	}
+	if code := do(signed); code != http.StatusOK {
+		t.Errorf("expected %d for the signed request, got %d", http.StatusOK, code)
+	}
+
+	// nor does it last
+	stale := httptest.NewRequest("GET", "/", nil)
+	ts := strconv.FormatInt(time.Now().Add(-2*OverrideMaxAge).Unix(), 10)
+	stale.Header.Set(OverrideHeader, "APP_TIMEOUT=5s")
+	stale.Header.Set(OverrideTimestampHeader, ts)
+	stale.Header.Set(OverrideSignatureHeader, hex.EncodeToString(overrideMAC(key, ts, stale, "APP_TIMEOUT=5s")))
+	if code := do(stale); code != http.StatusForbidden || seen != nil {
+		t.Errorf("expected %d for a stale signature, got %d", http.StatusForbidden, code)
+	}
+}
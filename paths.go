@@ -0,0 +1,93 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Glob is a validated filepath.Match pattern such as "/var/log/*.log".
+type Glob string
+
+// Decode implements Decoder.
+func (g *Glob) Decode(value string) error {
+	if _, err := filepath.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %v", value, err)
+	}
+	*g = Glob(value)
+	return nil
+}
+
+// Match reports whether name matches the pattern.
+func (g Glob) Match(name string) bool {
+	ok, _ := filepath.Match(string(g), name)
+	return ok
+}
+
+// Files returns the names of the files matching the pattern.
+func (g Glob) Files() ([]string, error) {
+	return filepath.Glob(string(g))
+}
+
+// PathList is a list of paths separated by the operating system's list
+// separator, like PATH: ":" on Unix and ";" on Windows. Empty entries are
+// dropped.
+type PathList []string
+
+// Decode implements Decoder.
+func (p *PathList) Decode(value string) error {
+	var list PathList
+	for _, path := range filepath.SplitList(value) {
+		if path = strings.TrimSpace(path); path != "" {
+			list = append(list, path)
+		}
+	}
+	*p = list
+	return nil
+}
+
+// String joins the paths with the list separator.
+func (p PathList) String() string {
+	return strings.Join(p, string(os.PathListSeparator))
+}
+
+// checkExists implements the `exists:"true"` tag: path fields must name an
+// existing file, every entry of a PathList must exist, and a Glob must match
+// at least one file.
+func checkExists(field reflect.Value) error {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	switch v := field.Interface().(type) {
+	case Glob:
+		files, err := v.Files()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no files match %q", string(v))
+		}
+		return nil
+	case PathList:
+		for _, path := range v {
+			if _, err := os.Stat(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if field.Kind() != reflect.String {
+		return fmt.Errorf("exists requires a path, PathList or Glob field, got %s", field.Type())
+	}
+	_, err := os.Stat(field.String())
+	return err
+}
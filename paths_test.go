@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	var g Glob
+	if err := g.Decode("logs/*.log"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !g.Match("logs/app.log") || g.Match("logs/app.txt") {
+		t.Errorf("unexpected matches for %q", g)
+	}
+	if err := g.Decode("logs/[a-"); err == nil {
+		t.Error("expected error for invalid pattern, got nil")
+	}
+}
+
+func TestPathList(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	var p PathList
+	if err := p.Decode(strings.Join([]string{"/usr/bin", "", " /opt/bin "}, sep)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(p) != 2 || p[0] != "/usr/bin" || p[1] != "/opt/bin" {
+		t.Errorf("unexpected paths %q", p)
+	}
+	if p.String() != "/usr/bin"+sep+"/opt/bin" {
+		t.Errorf("unexpected string %q", p.String())
+	}
+}
+
+func TestExists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envconfig")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.log"), nil, 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var s struct {
+		Logs   Glob     `exists:"true"`
+		Plugin PathList `exists:"true"`
+		Config string   `exists:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LOGS", filepath.Join(dir, "*.log"))
+	os.Setenv("ENV_CONFIG_PLUGIN", dir)
+	os.Setenv("ENV_CONFIG_CONFIG", filepath.Join(dir, "app.log"))
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_LOGS":   filepath.Join(dir, "*.txt"),
+		"ENV_CONFIG_PLUGIN": dir + string(os.PathListSeparator) + filepath.Join(dir, "missing"),
+		"ENV_CONFIG_CONFIG": filepath.Join(dir, "missing.yaml"),
+	} {
+		saved := os.Getenv(key)
+		os.Setenv(key, value)
+		err := Process("env_config", &s)
+		if perr, ok := err.(*ParseError); !ok || perr.KeyName != key {
+			t.Errorf("%s: expected ParseError, got %v", key, err)
+		}
+		os.Setenv(key, saved)
+	}
+}
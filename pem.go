@@ -0,0 +1,131 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sensitiveValue is implemented by types whose values are always treated as
+// if their field were tagged `sensitive:"true"`.
+type sensitiveValue interface {
+	sensitive()
+}
+
+// unescapePEM turns the single line form of PEM common in environment
+// variables, with literal `\n` sequences in place of line breaks, back into
+// PEM. Values that already contain line breaks are returned unchanged.
+func unescapePEM(value string) []byte {
+	if !strings.Contains(value, "\n") {
+		value = strings.Replace(value, `\r\n`, "\n", -1)
+		value = strings.Replace(value, `\n`, "\n", -1)
+	}
+	return []byte(strings.TrimSpace(value))
+}
+
+// PEMCertificate is one or more X.509 certificates given inline in PEM form,
+// either as written or on a single line with `\n` in place of line breaks.
+// The embedded Certificate is the first in the value; Chain holds them all in
+// order, so a leaf followed by its intermediates can be served as is.
+type PEMCertificate struct {
+	*x509.Certificate
+	Chain []*x509.Certificate
+}
+
+// Decode implements Decoder.
+func (c *PEMCertificate) Decode(value string) error {
+	var chain []*x509.Certificate
+	rest := unescapePEM(value)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block %q, expected CERTIFICATE", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return errors.New("no PEM encoded certificate found")
+	}
+	if len(strings.TrimSpace(string(rest))) != 0 {
+		return errors.New("unexpected data after PEM encoded certificates")
+	}
+	c.Certificate, c.Chain = chain[0], chain
+	return nil
+}
+
+// PEMPrivateKey is an RSA, ECDSA or Ed25519 private key given inline in PEM
+// form, either as written or on a single line with `\n` in place of line
+// breaks. PKCS #8, PKCS #1 ("RSA PRIVATE KEY") and SEC 1 ("EC PRIVATE KEY")
+// blocks are accepted; encrypted keys are not.
+//
+// Fields of this type are always treated as sensitive: their values are not
+// shown by Explain or in errors, they are prompted for with echo disabled, and
+// the key formats as "<sensitive>".
+type PEMPrivateKey struct {
+	crypto.Signer
+}
+
+// Decode implements Decoder.
+func (k *PEMPrivateKey) Decode(value string) error {
+	block, rest := pem.Decode(unescapePEM(value))
+	if block == nil {
+		return errors.New("no PEM encoded private key found")
+	}
+	if len(strings.TrimSpace(string(rest))) != 0 {
+		return errors.New("unexpected data after PEM encoded private key")
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "ENCRYPTED PRIVATE KEY":
+		return errors.New("encrypted private keys are not supported")
+	default:
+		return fmt.Errorf("unexpected PEM block %q, expected a private key", block.Type)
+	}
+	if err != nil {
+		// the parse errors do not include key material
+		return err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported private key type %T", key)
+	}
+	k.Signer = signer
+	return nil
+}
+
+// String implements fmt.Stringer without revealing the key.
+func (k PEMPrivateKey) String() string {
+	return "<sensitive>"
+}
+
+// GoString implements fmt.GoStringer without revealing the key.
+func (k PEMPrivateKey) GoString() string {
+	return "envconfig.PEMPrivateKey{<sensitive>}"
+}
+
+func (PEMPrivateKey) sensitive() {}
@@ -0,0 +1,54 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Percent is a ratio between 0 and 1. It decodes either a percentage ("75%")
+// or a plain ratio ("0.75").
+type Percent float64
+
+// Decode implements Decoder.
+func (p *Percent) Decode(value string) error {
+	v, err := parseRatio(value)
+	if err != nil {
+		return err
+	}
+	*p = Percent(v)
+	return nil
+}
+
+// Float returns the ratio as a float64.
+func (p Percent) Float() float64 {
+	return float64(p)
+}
+
+// String formats the ratio as a percentage.
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p)*100, 'f', -1, 64) + "%"
+}
+
+// parseRatio parses "75%" or "0.75" into a value between 0 and 1.
+func parseRatio(value string) (float64, error) {
+	s := strings.TrimSpace(value)
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+		scale = 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ratio %q", value)
+	}
+	v /= scale
+	if v < 0 || v > 1 {
+		return 0, fmt.Errorf("ratio %q out of range [0%%, 100%%]", value)
+	}
+	return v, nil
+}
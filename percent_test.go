@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"75%", 0.75},
+		{"0.75", 0.75},
+		{" 12.5 % ", 0.125},
+		{"0", 0},
+		{"100%", 1},
+		{"1", 1},
+	}
+	for _, tt := range tests {
+		var p Percent
+		if err := p.Decode(tt.value); err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if p.Float() != tt.want {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.want, p.Float())
+		}
+	}
+}
+
+func TestPercentErrors(t *testing.T) {
+	for _, value := range []string{"", "%", "abc", "75", "101%", "-1%", "1.5"} {
+		var p Percent
+		if err := p.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %v", value, p)
+		}
+	}
+}
+
+func TestPercentField(t *testing.T) {
+	var s struct {
+		SampleRate Percent `default:"10%"`
+		Threshold  *Percent
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_THRESHOLD", "0.9")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.SampleRate != 0.1 {
+		t.Errorf("expected %v, got %v", 0.1, s.SampleRate.Float())
+	}
+	if s.Threshold == nil || *s.Threshold != 0.9 {
+		t.Errorf("expected %v, got %v", 0.9, s.Threshold)
+	}
+	if s.SampleRate.String() != "10%" {
+		t.Errorf("expected %q, got %q", "10%", s.SampleRate.String())
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package platform provides envconfig specifications for the variables that
+// common hosting platforms set. The keys are absolute, so the structs decode
+// the same way whatever prefix they are processed with, and they can be
+// embedded as fields of a larger specification.
+package platform
+
+import (
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Heroku holds the variables set on Heroku dynos. The HEROKU_* values require
+// the dyno metadata feature.
+type Heroku struct {
+	Port           int    `envconfig:"PORT" absolute:"true" desc:"port to listen on"`
+	Dyno           string `envconfig:"DYNO" absolute:"true" desc:"dyno name, e.g. web.1"`
+	AppID          string `envconfig:"HEROKU_APP_ID" absolute:"true"`
+	AppName        string `envconfig:"HEROKU_APP_NAME" absolute:"true"`
+	DynoID         string `envconfig:"HEROKU_DYNO_ID" absolute:"true"`
+	ReleaseVersion string `envconfig:"HEROKU_RELEASE_VERSION" absolute:"true"`
+	SlugCommit     string `envconfig:"HEROKU_SLUG_COMMIT" absolute:"true"`
+}
+
+// Lambda holds the variables set in the AWS Lambda execution environment.
+type Lambda struct {
+	Region          string `envconfig:"AWS_REGION" absolute:"true"`
+	DefaultRegion   string `envconfig:"AWS_DEFAULT_REGION" absolute:"true"`
+	ExecutionEnv    string `envconfig:"AWS_EXECUTION_ENV" absolute:"true"`
+	FunctionName    string `envconfig:"AWS_LAMBDA_FUNCTION_NAME" absolute:"true"`
+	FunctionVersion string `envconfig:"AWS_LAMBDA_FUNCTION_VERSION" absolute:"true"`
+	MemorySizeMB    int    `envconfig:"AWS_LAMBDA_FUNCTION_MEMORY_SIZE" absolute:"true"`
+	LogGroupName    string `envconfig:"AWS_LAMBDA_LOG_GROUP_NAME" absolute:"true"`
+	LogStreamName   string `envconfig:"AWS_LAMBDA_LOG_STREAM_NAME" absolute:"true"`
+	RuntimeAPI      string `envconfig:"AWS_LAMBDA_RUNTIME_API" absolute:"true"`
+	TaskRoot        string `envconfig:"LAMBDA_TASK_ROOT" absolute:"true"`
+	Handler         string `envconfig:"_HANDLER" absolute:"true"`
+}
+
+// CloudRun holds the variables set on Cloud Run services and jobs.
+type CloudRun struct {
+	Port          int    `envconfig:"PORT" absolute:"true" desc:"port to listen on"`
+	Service       string `envconfig:"K_SERVICE" absolute:"true"`
+	Revision      string `envconfig:"K_REVISION" absolute:"true"`
+	Configuration string `envconfig:"K_CONFIGURATION" absolute:"true"`
+	Job           string `envconfig:"CLOUD_RUN_JOB" absolute:"true"`
+	Execution     string `envconfig:"CLOUD_RUN_EXECUTION" absolute:"true"`
+	TaskIndex     int    `envconfig:"CLOUD_RUN_TASK_INDEX" absolute:"true"`
+	TaskAttempt   int    `envconfig:"CLOUD_RUN_TASK_ATTEMPT" absolute:"true"`
+	TaskCount     int    `envconfig:"CLOUD_RUN_TASK_COUNT" absolute:"true"`
+}
+
+// KubernetesService holds the service link variables Kubernetes injects for
+// a service, such as REDIS_MASTER_SERVICE_HOST. Process it with the service
+// name as the prefix, or use KubernetesServiceFor.
+type KubernetesService struct {
+	ServiceHost string `split_words:"true"`
+	ServicePort int    `split_words:"true"`
+}
+
+// KubernetesServiceFor reads the service link variables for the named
+// service. Kubernetes derives the variable names by upper casing the name and
+// replacing dashes with underscores.
+func KubernetesServiceFor(name string) (KubernetesService, error) {
+	var svc KubernetesService
+	prefix := strings.ToUpper(strings.Replace(name, "-", "_", -1))
+	err := envconfig.Process(prefix, &svc)
+	return svc, err
+}
+
+// KubernetesAPI reads the address of the Kubernetes API server as seen from
+// inside a pod.
+func KubernetesAPI() (KubernetesService, error) {
+	return KubernetesServiceFor("kubernetes")
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package platform
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestHerokuEmbedded(t *testing.T) {
+	var s struct {
+		Heroku Heroku
+		Debug  bool
+	}
+	os.Clearenv()
+	os.Setenv("PORT", "5000")
+	os.Setenv("DYNO", "web.1")
+	os.Setenv("HEROKU_APP_NAME", "example")
+	os.Setenv("MYAPP_DEBUG", "true")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Heroku.Port != 5000 || s.Heroku.Dyno != "web.1" || s.Heroku.AppName != "example" {
+		t.Errorf("unexpected heroku values %+v", s.Heroku)
+	}
+	if !s.Debug {
+		t.Errorf("expected %v, got %v", true, s.Debug)
+	}
+}
+
+func TestLambda(t *testing.T) {
+	var l Lambda
+	os.Clearenv()
+	os.Setenv("AWS_REGION", "eu-west-1")
+	os.Setenv("AWS_LAMBDA_FUNCTION_NAME", "handler")
+	os.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "512")
+	os.Setenv("_HANDLER", "main")
+	if err := envconfig.Process("", &l); err != nil {
+		t.Fatal(err.Error())
+	}
+	if l.Region != "eu-west-1" || l.FunctionName != "handler" || l.MemorySizeMB != 512 || l.Handler != "main" {
+		t.Errorf("unexpected lambda values %+v", l)
+	}
+}
+
+func TestCloudRun(t *testing.T) {
+	var c CloudRun
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	os.Setenv("K_SERVICE", "api")
+	os.Setenv("K_REVISION", "api-00001")
+	if err := envconfig.Process("anything", &c); err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Port != 8080 || c.Service != "api" || c.Revision != "api-00001" {
+		t.Errorf("unexpected cloud run values %+v", c)
+	}
+}
+
+func TestKubernetesService(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("REDIS_MASTER_SERVICE_HOST", "10.0.0.11")
+	os.Setenv("REDIS_MASTER_SERVICE_PORT", "6379")
+	os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	os.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	svc, err := KubernetesServiceFor("redis-master")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if svc.ServiceHost != "10.0.0.11" || svc.ServicePort != 6379 {
+		t.Errorf("unexpected service values %+v", svc)
+	}
+
+	api, err := KubernetesAPI()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if api.ServiceHost != "10.0.0.1" || api.ServicePort != 443 {
+		t.Errorf("unexpected api values %+v", api)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Policy checks a resolved configuration against rules that apply across
+// services, such as "TLS must be enabled in prod" or "debug endpoints are
+// off". An engine such as OPA can be plugged in by evaluating a query with
+// the PolicyInput, which marshals to JSON, as its input.
+type Policy interface {
+	Check(input PolicyInput) []PolicyViolation
+}
+
+// PolicyFunc adapts an ordinary function to a Policy.
+type PolicyFunc func(input PolicyInput) []PolicyViolation
+
+// Check implements Policy.
+func (f PolicyFunc) Check(input PolicyInput) []PolicyViolation {
+	return f(input)
+}
+
+// PolicyInput is the configuration a Policy checks: the value of every
+// variable as it would be written out by Marshal, by key and by field path,
+// with sensitive values replaced by "<sensitive>". Variables of nil pointers
+// and of types that cannot be written out are left out.
+type PolicyInput struct {
+	Prefix string `json:"prefix"`
+	// Vars holds values by key, e.g. MYAPP_TLS_ENABLED.
+	Vars map[string]string `json:"vars"`
+	// Fields holds values by field path, e.g. TLS.Enabled, which does not
+	// depend on the prefix.
+	Fields map[string]string `json:"fields"`
+}
+
+// A PolicyViolation is a rule broken by a configuration.
+type PolicyViolation struct {
+	// Policy names the rule, e.g. "tls-in-prod".
+	Policy string `json:"policy"`
+	// Key is the variable at fault, if there is a single one.
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v PolicyViolation) String() string {
+	if v.Key == "" {
+		return fmt.Sprintf("%s: %s", v.Policy, v.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Policy, v.Key, v.Message)
+}
+
+// A PolicyError occurs when a configuration violates Options.Policies.
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "envconfig.Process: policy violations:\n\t" + strings.Join(msgs, "\n\t")
+}
+
+// checkPolicies runs options.Policies over the processed spec.
+func checkPolicies(prefix string, spec interface{}, options Options) error {
+	if len(options.Policies) == 0 {
+		return nil
+	}
+	input, err := policyInput(prefix, spec, options)
+	if err != nil {
+		return err
+	}
+	var violations []PolicyViolation
+	for _, p := range options.Policies {
+		violations = append(violations, p.Check(input)...)
+	}
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func policyInput(prefix string, spec interface{}, options Options) (PolicyInput, error) {
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	options.fromValues = true
+	infos, err := gatherInfo(prefix, deepCopy(reflect.ValueOf(spec)).Interface(), options)
+	if err != nil {
+		return PolicyInput{}, err
+	}
+	input := PolicyInput{
+		Prefix: prefix,
+		Vars:   make(map[string]string, len(infos)),
+		Fields: make(map[string]string, len(infos)),
+	}
+	for _, info := range infos {
+		if value, ok, err := info.marshal(options); err == nil && ok {
+			value = info.shownValue(value)
+			input.Vars[info.Key], input.Fields[info.Path] = value, value
+		}
+	}
+	return input, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPolicies(t *testing.T) {
+	type spec struct {
+		Env string `default:"dev"`
+		TLS struct {
+			Enabled bool
+		}
+		Debug    bool
+		Password string `sensitive:"true"`
+	}
+	var seen PolicyInput
+	tlsInProd := PolicyFunc(func(input PolicyInput) []PolicyViolation {
+		seen = input
+		if input.Fields["Env"] == "prod" && input.Fields["TLS.Enabled"] != "true" {
+			return []PolicyViolation{{Policy: "tls-in-prod", Key: input.Prefix + "_TLS_ENABLED", Message: "TLS must be enabled in prod"}}
+		}
+		return nil
+	})
+	debugOff := PolicyFunc(func(input PolicyInput) []PolicyViolation {
+		if input.Fields["Debug"] == "true" {
+			return []PolicyViolation{{Policy: "debug-off", Message: "debug endpoints must be off"}}
+		}
+		return nil
+	})
+	options := Options{Policies: []Policy{tlsInProd, debugOff}}
+
+	var s spec
+	env := map[string]string{"MYAPP_PASSWORD": "hunter2"}
+	if err := ProcessMap("MYAPP", &s, env, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := map[string]string{
+		"MYAPP_ENV":         "dev",
+		"MYAPP_TLS_ENABLED": "false",
+		"MYAPP_DEBUG":       "false",
+		"MYAPP_PASSWORD":    "<sensitive>",
+	}
+	if !reflect.DeepEqual(seen.Vars, want) || seen.Fields["Password"] != "<sensitive>" {
+		t.Errorf("expected the redacted configuration %v, got %+v", want, seen)
+	}
+
+	env = map[string]string{"MYAPP_ENV": "prod", "MYAPP_DEBUG": "true"}
+	err := ProcessMap("MYAPP", &s, env, options)
+	var perr *PolicyError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PolicyError, got %v", err)
+	}
+	if len(perr.Violations) != 2 || perr.Violations[0].Key != "MYAPP_TLS_ENABLED" || perr.Violations[1].Policy != "debug-off" {
+		t.Errorf("unexpected violations %v", perr.Violations)
+	}
+	want2 := "envconfig.Process: policy violations:\n\ttls-in-prod: MYAPP_TLS_ENABLED: TLS must be enabled in prod\n\tdebug-off: debug endpoints must be off"
+	if err.Error() != want2 {
+		t.Errorf("expected %q, got %q", want2, err.Error())
+	}
+
+	options.Policies = nil
+	if err := ProcessMap("MYAPP", &s, env, options); err != nil {
+		t.Errorf("expected no error without policies, got %v", err)
+	}
+}
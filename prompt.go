@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// promptState holds the terminal the interactive prompt talks to. It is a
+// package variable so tests can substitute their own input and output.
+var promptState = struct {
+	sync.Mutex
+	in         io.Reader
+	out        io.Writer
+	isTerminal func() bool
+	readHidden func() (string, error)
+}{
+	in:         os.Stdin,
+	out:        os.Stderr,
+	isTerminal: func() bool { return isTerminal(os.Stdin) },
+	readHidden: func() (string, error) { return readPassword(os.Stdin) },
+}
+
+// isTerminal reports whether f is attached to a character device.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// promptFor asks the user for the value of a missing variable. It returns
+// false when no terminal is attached, when the input is hidden but echo
+// cannot be disabled, or when the user enters an empty value.
+func promptFor(info varInfo) (string, bool) {
+	promptState.Lock()
+	defer promptState.Unlock()
+
+	if !promptState.isTerminal() {
+		return "", false
+	}
+
+	label := info.Key
+	if desc := info.Tags.Get("desc"); desc != "" {
+		label = fmt.Sprintf("%s (%s)", label, desc)
+	}
+	fmt.Fprintf(promptState.out, "%s: ", label)
+
+	var (
+		value string
+		err   error
+	)
+	if info.isSensitive() {
+		value, err = promptState.readHidden()
+		fmt.Fprintln(promptState.out)
+	} else {
+		value, err = readLine(promptState.in)
+	}
+	if err != nil || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// readLine reads a single line from r one byte at a time so that nothing
+// beyond the newline is consumed.
+func readLine(r io.Reader) (string, error) {
+	var (
+		line []byte
+		b    [1]byte
+	)
+	for {
+		n, err := r.Read(b[:])
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
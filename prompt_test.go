@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+type promptSpecification struct {
+	Host     string `required:"true" desc:"database host"`
+	Password string `required:"true" sensitive:"true"`
+	Optional string
+}
+
+func TestPromptForMissingRequired(t *testing.T) {
+	var s promptSpecification
+	os.Clearenv()
+	out := setPrompt(t, true, "db.local\n", "hunter2")
+
+	if err := ProcessWithOptions("env_config", &s, Options{Prompt: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Host != "db.local" {
+		t.Errorf("expected %q, got %q", "db.local", s.Host)
+	}
+	if s.Password != "hunter2" {
+		t.Errorf("expected %q, got %q", "hunter2", s.Password)
+	}
+	if !strings.Contains(out.String(), "ENV_CONFIG_HOST (database host): ") {
+		t.Errorf("expected prompt with description, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("sensitive value was echoed: %q", out.String())
+	}
+}
+
+func TestPromptSkippedWithoutTerminal(t *testing.T) {
+	var s promptSpecification
+	os.Clearenv()
+	out := setPrompt(t, false, "db.local\n", "hunter2")
+
+	err := ProcessWithOptions("env_config", &s, Options{Prompt: true})
+	if err == nil || err.Error() != "required key ENV_CONFIG_HOST (database host) missing value" {
+		t.Errorf("expected required key error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no prompt, got %q", out.String())
+	}
+}
+
+func TestPromptEmptyInput(t *testing.T) {
+	var s promptSpecification
+	os.Clearenv()
+	setPrompt(t, true, "\n", "")
+
+	err := ProcessWithOptions("env_config", &s, Options{Prompt: true})
+	if err == nil || err.Error() != "required key ENV_CONFIG_HOST (database host) missing value" {
+		t.Errorf("expected required key error, got %v", err)
+	}
+}
+
+func TestPromptDisabledByDefault(t *testing.T) {
+	var s promptSpecification
+	os.Clearenv()
+	out := setPrompt(t, true, "db.local\n", "hunter2")
+
+	if err := Process("env_config", &s); err == nil {
+		t.Error("expected required key error, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no prompt, got %q", out.String())
+	}
+}
+
+// setPrompt replaces the prompt terminal for the duration of the test.
+func setPrompt(t *testing.T, terminal bool, input, hidden string) *bytes.Buffer {
+	out := new(bytes.Buffer)
+	in, w, isTerm, readHidden := promptState.in, promptState.out, promptState.isTerminal, promptState.readHidden
+	promptState.in = strings.NewReader(input)
+	promptState.out = out
+	promptState.isTerminal = func() bool { return terminal }
+	promptState.readHidden = func() (string, error) { return hidden, nil }
+	t.Cleanup(func() {
+		promptState.in, promptState.out, promptState.isTerminal, promptState.readHidden = in, w, isTerm, readHidden
+	})
+	return out
+}
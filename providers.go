@@ -0,0 +1,136 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// A Provider computes a value that can be referenced from defaults as
+// {{name}}, such as the host name of the machine.
+type Provider func() (string, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		"hostname":   os.Hostname,
+		"fqdn":       fqdn,
+		"primary_ip": primaryIP,
+	}
+	providerRegexp = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+)
+
+// RegisterProvider makes p available to defaults as {{name}}. The built-in
+// providers are hostname, fqdn and primary_ip.
+func RegisterProvider(name string, p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = p
+}
+
+// expandProviders replaces every {{name}} in s with the value of the named
+// provider. Text that names no registered provider is left as it is, so
+// defaults written before providers existed keep their meaning.
+func expandProviders(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	var err error
+	out := providerRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := providerRegexp.FindStringSubmatch(m)[1]
+		providersMu.RLock()
+		p, ok := providers[name]
+		providersMu.RUnlock()
+		if !ok {
+			return m
+		}
+		v, perr := p()
+		if perr != nil && err == nil {
+			err = fmt.Errorf("provider %q: %v", name, perr)
+		}
+		return v
+	})
+	return out, err
+}
+
+// ProviderLookuper returns a Lookuper that answers the keys of templates with
+// their template expanded, e.g. {"MYAPP_ADVERTISE_ADDR": "{{primary_ip}}:8080"}.
+// Keys whose providers fail are reported as not set.
+func ProviderLookuper(templates map[string]string) Lookuper {
+	return LookuperFunc(func(key string) (string, bool) {
+		t, ok := templates[key]
+		if !ok {
+			return "", false
+		}
+		v, err := expandProviders(t)
+		return v, err == nil
+	})
+}
+
+// fqdn returns the fully qualified domain name of the host, falling back to
+// the host name when it cannot be resolved.
+func fqdn() (string, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		return host, nil
+	}
+	for _, addr := range addrs {
+		names, err := net.LookupAddr(addr)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if name = strings.TrimSuffix(name, "."); strings.Contains(name, ".") {
+				return name, nil
+			}
+		}
+	}
+	return host, nil
+}
+
+// primaryIP returns the first IPv4 address of an interface that is up and
+// not a loopback, or the first such IPv6 address when there is no IPv4 one.
+func primaryIP() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	var v6 string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if ip4 := ipnet.IP.To4(); ip4 != nil {
+				return ip4.String(), nil
+			}
+			if v6 == "" {
+				v6 = ipnet.IP.String()
+			}
+		}
+	}
+	if v6 != "" {
+		return v6, nil
+	}
+	return "", errors.New("no non-loopback address found")
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestProviderDefaults(t *testing.T) {
+	RegisterProvider("test_zone", func() (string, error) { return "zone-a", nil })
+	RegisterProvider("test_count", func() (string, error) { return "3", nil })
+	host, _ := os.Hostname()
+
+	var s struct {
+		Host      string `default:"{{hostname}}"`
+		Advertise string `default:"{{ hostname }}:8080"`
+		Zone      string `default:"{{test_zone}}"`
+		Workers   int    `default:"{{test_count}}*2"`
+	}
+	os.Clearenv()
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Host != host {
+		t.Errorf("expected %q, got %q", host, s.Host)
+	}
+	if s.Advertise != host+":8080" {
+		t.Errorf("expected %q, got %q", host+":8080", s.Advertise)
+	}
+	if s.Zone != "zone-a" {
+		t.Errorf("expected %q, got %q", "zone-a", s.Zone)
+	}
+	if s.Workers != 6 {
+		t.Errorf("expected %d, got %d", 6, s.Workers)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	RegisterProvider("test_broken", func() (string, error) { return "", errors.New("broken") })
+
+	if _, err := expandProviders("{{test_broken}}"); err == nil {
+		t.Error("expected error for a failing provider, got nil")
+	}
+	if v, err := expandProviders("{{no_such_provider}}-{{ name }}"); err != nil || v != "{{no_such_provider}}-{{ name }}" {
+		t.Errorf("expected unknown providers to be left as written, got %q (%v)", v, err)
+	}
+
+	var s struct {
+		Zone string `default:"{{test_broken}}"`
+	}
+	os.Clearenv()
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
+
+func TestProviderLookuper(t *testing.T) {
+	RegisterProvider("test_zone", func() (string, error) { return "zone-a", nil })
+	RegisterProvider("test_broken", func() (string, error) { return "", errors.New("broken") })
+
+	l := ProviderLookuper(map[string]string{
+		"APP_ZONE":   "{{test_zone}}/1",
+		"APP_BROKEN": "{{test_broken}}",
+	})
+	if v, ok := l.Lookup("APP_ZONE"); !ok || v != "zone-a/1" {
+		t.Errorf("expected %q, got %q (%v)", "zone-a/1", v, ok)
+	}
+	if _, ok := l.Lookup("APP_BROKEN"); ok {
+		t.Error("expected failing provider to be reported as not set")
+	}
+	if _, ok := l.Lookup("APP_OTHER"); ok {
+		t.Error("expected unknown key to be reported as not set")
+	}
+}
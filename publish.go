@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// A Publisher uploads manifests, as written by WriteManifestFile or
+// marshaled from NewManifest, to a schema registry so platform teams can
+// see what every deployed service expects from its environment. The
+// manifest is the body of a PUT request to URL, which would normally name
+// the service and build, e.g. https://registry.example/services/myapp/builds/v1.4.2.
+type Publisher struct {
+	URL string
+	// Header is added to each request, e.g. for an Authorization header.
+	Header http.Header
+	// Client is used for requests; http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// Publish uploads manifest. Any status other than 2xx is an error. Services
+// that publish at startup should bound ctx and, unless the registry is
+// essential, log the error rather than exit.
+func (p *Publisher) Publish(ctx context.Context, manifest []byte) error {
+	if !json.Valid(manifest) {
+		return errors.New("envconfig: publishing manifest: invalid JSON")
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.URL, bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+	for key, values := range p.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("envconfig: publishing manifest: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("envconfig: publishing manifest to %s: %s", p.URL, resp.Status)
+		if msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512)); len(bytes.TrimSpace(msg)) > 0 {
+			err = fmt.Errorf("%v: %s", err, bytes.TrimSpace(msg))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublisher(t *testing.T) {
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected %s request with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		got, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	var s struct {
+		Host string `required:"true"`
+	}
+	m, err := NewManifest("myapp", &s, Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	doc, _ := json.Marshal(m)
+
+	p := &Publisher{URL: srv.URL + "/services/myapp", Header: http.Header{"Authorization": {"Bearer token"}}}
+	if err := p.Publish(context.Background(), doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(got) != string(doc) {
+		t.Errorf("expected the manifest to be uploaded, got %s", got)
+	}
+
+	p.Header = nil
+	err = p.Publish(context.Background(), doc)
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized: unauthorized") {
+		t.Errorf("expected the registry's error, got %v", err)
+	}
+	if err := p.Publish(context.Background(), []byte("{")); err == nil {
+		t.Error("expected error for an invalid manifest, got nil")
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An EnvGenerator draws random environments for a specification, for
+// property-based tests. Valid environments respect the `required`, `min`,
+// `max`, `oneof` and `maxbytes` tags; Invalid breaks exactly one of them.
+// It works with any source of randomness, e.g. for rapid:
+//
+//	g, _ := envconfig.NewEnvGenerator("myapp", &Specification{}, envconfig.Options{})
+//	rapid.Check(t, func(t *rapid.T) {
+//		env := g.Valid(rand.New(rand.NewSource(rapid.Int64().Draw(t, "seed"))))
+//		...
+//	})
+//
+// Variables of types with decoding methods are left unset.
+type EnvGenerator struct {
+	infos    []varInfo
+	required map[string]bool
+}
+
+// NewEnvGenerator returns an EnvGenerator for spec. It fails if a required
+// variable without a default has a type it cannot generate.
+func NewEnvGenerator(prefix string, spec interface{}, options Options) (*EnvGenerator, error) {
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	infos, err := gatherInfo(prefix, spec, options)
+	if err != nil {
+		return nil, err
+	}
+	g := &EnvGenerator{required: make(map[string]bool)}
+	for _, info := range infos {
+		req := info.Tags.Get("required")
+		required := (isTrue(req) || options.Required && !isFalse(req)) && info.defaultValue() == ""
+		if _, ok := info.sample(); !ok {
+			if required {
+				return nil, fmt.Errorf("envconfig.NewEnvGenerator: cannot generate %s of type %s; give it a default", info.Key, info.Field.Type())
+			}
+			continue
+		}
+		g.infos = append(g.infos, info)
+		g.required[info.Key] = required
+	}
+	return g, nil
+}
+
+// Valid returns a random environment that processes without error, unless a
+// Validate method rejects it. Optional variables are set half of the time.
+func (g *EnvGenerator) Valid(r *rand.Rand) map[string]string {
+	env := make(map[string]string)
+	for _, info := range g.infos {
+		if !g.required[info.Key] && r.Intn(2) == 0 {
+			continue
+		}
+		env[info.Key] = randomValue(info, r)
+	}
+	return env
+}
+
+// Invalid returns a copy of env with one variable, whose key is returned,
+// changed to break the tags of the specification. It returns false if no
+// variable can be broken.
+func (g *EnvGenerator) Invalid(r *rand.Rand, env map[string]string) (map[string]string, string, bool) {
+	type mutation struct {
+		key   string
+		value string
+		unset bool
+	}
+	var mutations []mutation
+	for _, info := range g.infos {
+		if g.required[info.Key] {
+			mutations = append(mutations, mutation{key: info.Key, unset: true})
+		}
+		for _, v := range invalidValues(info) {
+			mutations = append(mutations, mutation{key: info.Key, value: v})
+		}
+	}
+	if len(mutations) == 0 {
+		return env, "", false
+	}
+
+	m := mutations[r.Intn(len(mutations))]
+	out := make(map[string]string, len(env)+1)
+	for k, v := range env {
+		out[k] = v
+	}
+	if m.unset {
+		delete(out, m.key)
+	} else {
+		out[m.key] = m.value
+	}
+	return out, m.key, true
+}
+
+// invalidValues returns values that the tags or the type of the variable
+// reject.
+func invalidValues(info varInfo) []string {
+	var values []string
+	if allowed := info.oneOf(); allowed != nil {
+		values = append(values, "not-"+allowed[0])
+	}
+	typ := info.Field.Type()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if info.isDotenv() || info.isJSON() || implementsInterface(typ) {
+		return values
+	}
+	if min := info.Tags.Get("min"); min != "" {
+		if v, ok := stepNumber(min, typ, -1); ok {
+			values = append(values, v)
+		}
+	}
+	if max := info.Tags.Get("max"); max != "" {
+		if v, ok := stepNumber(max, typ, 1); ok {
+			values = append(values, v)
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		values = append(values, "maybe")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		values = append(values, "x")
+		if typ.PkgPath() == "" {
+			for _, c := range typeBounds(typ) {
+				if !c.valid {
+					values = append(values, c.value)
+				}
+			}
+		}
+	case reflect.String:
+		if n, err := strconv.Atoi(info.Tags.Get("maxbytes")); err == nil && n > 0 && info.oneOf() == nil {
+			values = append(values, strings.Repeat("x", n+1))
+		}
+	}
+	return values
+}
+
+// randomValue returns a random value the variable accepts.
+func randomValue(info varInfo, r *rand.Rand) string {
+	if allowed := info.oneOf(); allowed != nil {
+		if k := info.Field.Kind(); k == reflect.Slice || k == reflect.Array {
+			items := make([]string, 1+r.Intn(3))
+			for i := range items {
+				items[i] = allowed[r.Intn(len(allowed))]
+			}
+			return strings.Join(items, info.Delimiter)
+		}
+		return allowed[r.Intn(len(allowed))]
+	}
+	typ := info.Field.Type()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if info.isDotenv() || info.isJSON() || implementsInterface(typ) {
+		v, _ := info.sample()
+		return v
+	}
+	maxLen := 16
+	if n, err := strconv.Atoi(info.Tags.Get("maxbytes")); err == nil && n > 0 {
+		maxLen = n
+	}
+	g := randomGen{r: r, maxLen: maxLen, reserved: info.Delimiter + info.MapDelimiter}
+	if v, ok := g.number(typ, info.Tags.Get("min"), info.Tags.Get("max")); ok {
+		return v
+	}
+	return g.value(typ, info.Delimiter, info.MapDelimiter)
+}
+
+type randomGen struct {
+	r        *rand.Rand
+	maxLen   int
+	reserved string
+}
+
+// randomChars are the characters of generated strings. $ is left out so
+// values survive Options.ExpandEnv.
+const randomChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_./:,;=@+"
+
+func (g randomGen) str(n int, inItem bool) string {
+	b := make([]byte, 0, n)
+	for len(b) < n {
+		c := randomChars[g.r.Intn(len(randomChars))]
+		if inItem && strings.IndexByte(g.reserved, c) >= 0 {
+			continue
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+func (g randomGen) value(typ reflect.Type, sep, kvSep string) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if v, ok := g.number(typ, "", ""); ok {
+		return v
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(g.r.Intn(2) == 0)
+	case reflect.String:
+		return g.str(g.r.Intn(g.maxLen+1), false)
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return g.str(g.r.Intn(g.maxLen+1), false)
+		}
+		items := make([]string, 1+g.r.Intn(3))
+		for i := range items {
+			items[i] = g.item(typ.Elem(), sep, kvSep)
+		}
+		return strings.Join(items, sep)
+	case reflect.Map:
+		n := 1 + g.r.Intn(3)
+		seen := make(map[string]bool)
+		var pairs []string
+		for len(pairs) < n {
+			k := g.item(typ.Key(), sep, kvSep)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			if isSetType(typ) {
+				pairs = append(pairs, k)
+			} else {
+				pairs = append(pairs, k+kvSep+g.item(typ.Elem(), sep, kvSep))
+			}
+		}
+		return strings.Join(pairs, sep)
+	}
+	return ""
+}
+
+// item returns a random item of a slice or map, which may not contain the
+// delimiters and, for strings, is never empty.
+func (g randomGen) item(typ reflect.Type, sep, kvSep string) string {
+	if typ.Kind() == reflect.String {
+		return g.str(1+g.r.Intn(8), true)
+	}
+	return g.value(typ, sep, kvSep)
+}
+
+// number returns a random number of type typ between min and max, when
+// given, and one of the bounds half of the time.
+func (g randomGen) number(typ reflect.Type, min, max string) (string, bool) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lo, hi := int64(-1)<<(typ.Bits()-1), int64(uint64(1)<<(typ.Bits()-1)-1)
+		if typ == durationType {
+			lo, hi = 0, int64(time.Hour)
+		}
+		v := reflect.New(typ).Elem()
+		if min != "" && processField(min, v) == nil {
+			lo = v.Int()
+		}
+		if max != "" && processField(max, v) == nil {
+			hi = v.Int()
+		}
+		n := lo + int64(g.between(uint64(hi-lo)))
+		if typ == durationType {
+			return time.Duration(n).String(), true
+		}
+		return strconv.FormatInt(n, 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lo, hi := uint64(0), ^uint64(0)>>(64-typ.Bits())
+		v := reflect.New(typ).Elem()
+		if min != "" && processField(min, v) == nil {
+			lo = v.Uint()
+		}
+		if max != "" && processField(max, v) == nil {
+			hi = v.Uint()
+		}
+		return strconv.FormatUint(lo+g.between(hi-lo), 10), true
+	case reflect.Float32, reflect.Float64:
+		lo, hi := -1e6, 1e6
+		v := reflect.New(typ).Elem()
+		if min != "" && processField(min, v) == nil {
+			lo = v.Float()
+		}
+		if max != "" && processField(max, v) == nil {
+			hi = v.Float()
+		}
+		f := lo + g.r.Float64()*(hi-lo)
+		if g.r.Intn(2) == 0 {
+			f = lo
+			if g.r.Intn(2) == 0 {
+				f = hi
+			}
+		}
+		return strconv.FormatFloat(f, 'g', -1, typ.Bits()), true
+	}
+	return "", false
+}
+
+// between returns a random offset from 0 to span, and either end of the span
+// half of the time.
+func (g randomGen) between(span uint64) uint64 {
+	switch g.r.Intn(4) {
+	case 0:
+		return 0
+	case 1:
+		return span
+	}
+	n := g.r.Uint64()
+	if span < math.MaxUint64 {
+		n %= span + 1
+	}
+	return n
+}
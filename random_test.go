@@ -0,0 +1,73 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+type randomSpecification struct {
+	Host     string `required:"true" maxbytes:"8"`
+	Port     uint16 `min:"1024"`
+	Level    string `oneof:"debug,info,warn"`
+	Retries  int8   `min:"-3" max:"3"`
+	Big      int64  `required:"true"`
+	Huge     uint64
+	Timeout  time.Duration `min:"1s" max:"1m"`
+	Ratio    float32       `min:"0" max:"1"`
+	Debug    bool
+	Tags     []string
+	Modes    []string       `oneof:"r,w"`
+	Weights  map[string]int `split_words:"true"`
+	Features map[string]struct{}
+	Secret   []byte
+	Share    Percent
+}
+
+func TestEnvGenerator(t *testing.T) {
+	g, err := NewEnvGenerator("app", &randomSpecification{}, Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		env := g.Valid(r)
+		if _, ok := env["APP_SHARE"]; ok {
+			t.Fatal("unexpected value for a type with a decoder")
+		}
+		var s randomSpecification
+		if err := ProcessWithOptions("app", &s, Options{Lookuper: MapLookuper(env)}); err != nil {
+			t.Fatalf("%v: %v", env, err)
+		}
+
+		bad, key, ok := g.Invalid(r, env)
+		if !ok {
+			t.Fatal("expected a mutation")
+		}
+		if err := ProcessWithOptions("app", &s, Options{Lookuper: MapLookuper(bad)}); err == nil {
+			t.Fatalf("%s=%q: expected error, got nil", key, bad[key])
+		}
+	}
+
+	var none struct {
+		Share Percent
+	}
+	g, err = NewEnvGenerator("app", &none, Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, _, ok := g.Invalid(r, g.Valid(r)); ok {
+		t.Error("expected no mutation")
+	}
+
+	var bad struct {
+		Share Percent `required:"true"`
+	}
+	if _, err := NewEnvGenerator("app", &bad, Options{}); err == nil {
+		t.Error("expected error for a required variable that cannot be generated, got nil")
+	}
+}
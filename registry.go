@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// KeyRegistry records the keys claimed by each specification processed with
+// it, and rejects a specification that claims a key already claimed by a
+// different specification type with a different field type.
+type KeyRegistry struct {
+	mu     sync.Mutex
+	claims map[string]keyClaim
+}
+
+type keyClaim struct {
+	spec  reflect.Type
+	field reflect.Type
+}
+
+// DefaultKeyRegistry is a process-wide registry. It is only consulted when
+// passed in Options.Registry.
+var DefaultKeyRegistry = NewKeyRegistry()
+
+// NewKeyRegistry returns an empty registry.
+func NewKeyRegistry() *KeyRegistry {
+	return &KeyRegistry{claims: make(map[string]keyClaim)}
+}
+
+// A KeyCollisionError occurs when two specifications claim the same key with
+// different types.
+type KeyCollisionError struct {
+	Key        string
+	Spec       string
+	Type       string
+	SecondSpec string
+	SecondType string
+}
+
+func (e *KeyCollisionError) Error() string {
+	return fmt.Sprintf("envconfig: key %s claimed as %s by %s and as %s by %s", e.Key, e.Type, e.Spec, e.SecondType, e.SecondSpec)
+}
+
+// Keys returns the sorted keys claimed so far.
+func (r *KeyRegistry) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.claims))
+	for key := range r.claims {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// claim records the keys of infos for spec. Nothing is recorded when any of
+// them collides.
+func (r *KeyRegistry) claim(spec interface{}, infos []varInfo) error {
+	specType := reflect.TypeOf(spec)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, info := range infos {
+		prev, ok := r.claims[info.Key]
+		if ok && prev.spec != specType && prev.field != info.Field.Type() {
+			return &KeyCollisionError{
+				Key:        info.Key,
+				Spec:       prev.spec.String(),
+				Type:       prev.field.String(),
+				SecondSpec: specType.String(),
+				SecondType: info.Field.Type().String(),
+			}
+		}
+	}
+	for _, info := range infos {
+		if _, ok := r.claims[info.Key]; !ok {
+			r.claims[info.Key] = keyClaim{spec: specType, field: info.Field.Type()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+type registryPluginA struct {
+	Port    int
+	Verbose bool
+}
+
+type registryPluginB struct {
+	Port    string
+	Verbose bool
+}
+
+func TestKeyRegistry(t *testing.T) {
+	r := NewKeyRegistry()
+	os.Clearenv()
+	options := Options{Registry: r}
+
+	var a registryPluginA
+	if err := ProcessWithOptions("app", &a, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	// processing the same type again is not a collision
+	if err := ProcessWithOptions("app", &a, options); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var b registryPluginB
+	err := ProcessWithOptions("app", &b, options)
+	v, ok := err.(*KeyCollisionError)
+	if !ok {
+		t.Fatalf("expected KeyCollisionError, got %T %v", err, err)
+	}
+	if v.Key != "APP_PORT" || v.Type != "int" || v.SecondType != "string" {
+		t.Errorf("unexpected collision %+v", v)
+	}
+
+	// a different prefix does not collide
+	if err := ProcessWithOptions("plugin", &b, options); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := []string{"APP_PORT", "APP_VERBOSE", "PLUGIN_PORT", "PLUGIN_VERBOSE"}
+	if !reflect.DeepEqual(r.Keys(), want) {
+		t.Errorf("expected %v, got %v", want, r.Keys())
+	}
+}
+
+func TestKeyRegistryOptIn(t *testing.T) {
+	var a registryPluginA
+	var b registryPluginB
+	os.Clearenv()
+	if err := Process("app", &a); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := Process("app", &b); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(DefaultKeyRegistry.Keys()) != 0 {
+		t.Errorf("expected empty default registry, got %v", DefaultKeyRegistry.Keys())
+	}
+}
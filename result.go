@@ -0,0 +1,75 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Result summarizes a call to ProcessWithOptions. Pass a pointer in
+// Options.Result to have it filled in.
+type Result struct {
+	// Set counts variables whose value came from the environment, including
+	// those found under their alternate name or read from a secret store or
+	// a _FILE secret file.
+	Set int
+	// FromAlt counts the variables in Set found under their alternate name or
+	// an `alt` alias.
+	FromAlt int
+	// FromSecretStore counts the variables in Set read from a secret store or
+	// a _FILE secret file.
+	FromSecretStore int
+	// Defaulted counts variables that were not set and took their default.
+	Defaulted int
+	// Skipped counts variables that were not set and have no default.
+	Skipped int
+	// Duration is the time spent processing the specification.
+	Duration time.Duration
+}
+
+// String returns a one-line summary suitable for a startup log.
+func (r *Result) String() string {
+	return fmt.Sprintf("%d set (%d from alternate names, %d from secret stores), %d defaulted, %d skipped in %s",
+		r.Set, r.FromAlt, r.FromSecretStore, r.Defaulted, r.Skipped, r.Duration)
+}
+
+// valueSource tells where the value of a variable came from.
+type valueSource int
+
+const (
+	sourceEnv valueSource = iota
+	sourceAlt
+	sourceSecretStore
+	sourceDefault
+	sourceSkipped
+)
+
+// resultMu guards every Result, as variables may be processed in parallel.
+var resultMu sync.Mutex
+
+// record counts a processed variable. It is a no-op on a nil Result.
+func (r *Result) record(source valueSource) {
+	if r == nil {
+		return
+	}
+	resultMu.Lock()
+	defer resultMu.Unlock()
+	switch source {
+	case sourceEnv:
+		r.Set++
+	case sourceAlt:
+		r.Set++
+		r.FromAlt++
+	case sourceSecretStore:
+		r.Set++
+		r.FromSecretStore++
+	case sourceDefault:
+		r.Defaulted++
+	case sourceSkipped:
+		r.Skipped++
+	}
+}
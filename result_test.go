@@ -0,0 +1,56 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		var s Specification
+		var res Result
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_REQUIREDVAR", "foo")
+		os.Setenv("ENV_CONFIG_PORT", "8080")
+		os.Setenv("SERVICE_HOST", "127.0.0.1")
+
+		if err := ProcessWithOptions("env_config", &s, Options{Result: &res, ParallelExcecution: parallel}); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		infos, _ := gatherInfo("env_config", &s, Options{})
+		if res.Set != 3 {
+			t.Errorf("expected %d set, got %d", 3, res.Set)
+		}
+		if res.FromAlt != 1 {
+			t.Errorf("expected %d from alt, got %d", 1, res.FromAlt)
+		}
+		if res.Defaulted != 6 {
+			t.Errorf("expected %d defaulted, got %d", 6, res.Defaulted)
+		}
+		if total := res.Set + res.Defaulted + res.Skipped; total != len(infos) {
+			t.Errorf("expected %d variables, got %d", len(infos), total)
+		}
+		if res.Duration <= 0 {
+			t.Errorf("expected a duration, got %s", res.Duration)
+		}
+		if !strings.HasPrefix(res.String(), "3 set (1 from alternate names, 0 from secret stores), 6 defaulted") {
+			t.Errorf("unexpected summary %q", res.String())
+		}
+	}
+}
+
+func TestResultReset(t *testing.T) {
+	var s Specification
+	res := Result{Set: 100, Skipped: 100}
+	os.Clearenv()
+	ProcessWithOptions("env_config", &s, Options{Result: &res})
+	if res.Set != 0 {
+		t.Errorf("expected %d set, got %d", 0, res.Set)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A RoundTripError lists the fields of a specification that do not survive
+// being written out as variables and processed again.
+type RoundTripError struct {
+	Fields []RoundTripField
+}
+
+// A RoundTripField is a field reported by VerifyRoundTrip.
+type RoundTripField struct {
+	// Path is the field's path from the top of the specification, e.g.
+	// "DB.Port" or "Endpoints[1].Host".
+	Path string
+	Key  string
+
+	// Reason says why the field does not round-trip.
+	Reason string
+}
+
+func (e *RoundTripError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s (%s): %s", f.Path, f.Key, f.Reason)
+	}
+	return "envconfig: fields do not round-trip:\n\t" + strings.Join(msgs, "\n\t")
+}
+
+// VerifyRoundTrip writes the fields of the populated spec out as variables,
+// processes them into a new value of its type and reports, as a
+// RoundTripError, every field that comes back different or cannot be written
+// out: decoders without a String or MarshalText method, lossy decoders and
+// transformers, and slice items that contain the delimiter. spec is not
+// modified. Validate methods are not called.
+func VerifyRoundTrip(spec interface{}) error {
+	return VerifyRoundTripWithOptions(spec, Options{})
+}
+
+// VerifyRoundTripWithOptions is like VerifyRoundTrip() but with specified
+// options.
+func VerifyRoundTripWithOptions(spec interface{}, options Options) error {
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidSpecification
+	}
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	options.Registry, options.Result, options.Prompt = nil, nil, false
+	options.fromValues = true
+	original := deepCopy(v)
+	infos, err := gatherInfo("", original.Interface(), options)
+	if err != nil {
+		return err
+	}
+
+	var failed []RoundTripField
+	env := make(MapLookuper)
+	unmarshaled := make(map[string]bool)
+	for _, info := range infos {
+		value, ok, err := info.marshal(options)
+		if err != nil {
+			failed = append(failed, RoundTripField{Path: info.Path, Key: info.Key, Reason: err.Error()})
+			unmarshaled[info.Path] = true
+			continue
+		}
+		if ok {
+			env[info.Key] = value
+		}
+	}
+
+	copied := reflect.New(v.Elem().Type())
+	processOptions := options
+	processOptions.Lookuper, processOptions.fromValues = env, false
+	processOptions.CollectErrors, processOptions.Validate, processOptions.Policies = true, false, nil
+	err = ProcessWithOptions("", copied.Interface(), processOptions)
+	var errs MultiError
+	if err != nil && !errors.As(err, &errs) {
+		return err
+	}
+	parseFailed := make(map[string]error)
+	for _, err := range errs {
+		var p *ParseError
+		if errors.As(err, &p) {
+			parseFailed[p.KeyName] = p
+		}
+	}
+
+	copiedInfos, err := gatherInfo("", copied.Interface(), options)
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]varInfo, len(copiedInfos))
+	for _, info := range copiedInfos {
+		byPath[info.Path] = info
+	}
+	for _, info := range infos {
+		if unmarshaled[info.Path] {
+			continue
+		}
+		reason := ""
+		if err, ok := parseFailed[info.Key]; ok {
+			reason = err.Error()
+		} else if c, ok := byPath[info.Path]; !ok {
+			reason = "not found after processing"
+		} else if !sameValue(info.Field, c.Field) {
+			reason = fmt.Sprintf("%q processes to a different value", env[info.Key])
+		}
+		if reason != "" {
+			failed = append(failed, RoundTripField{Path: info.Path, Key: info.Key, Reason: reason})
+		}
+	}
+	if len(failed) > 0 {
+		return &RoundTripError{Fields: failed}
+	}
+	return nil
+}
+
+// sameValue reports whether a and b are deeply equal, counting nil and empty
+// slices and maps as equal.
+func sameValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
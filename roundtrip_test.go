@@ -0,0 +1,102 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type opaqueID string
+
+func (id *opaqueID) Decode(value string) error {
+	*id = opaqueID("id-" + value)
+	return nil
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	type spec struct {
+		Name      string
+		Port      int           `default:"8080"`
+		Timeout   time.Duration `split_words:"true"`
+		Tags      []string
+		Weights   map[string]float64
+		Features  map[string]struct{}
+		Share     Percent
+		Endpoint  *url.URL
+		Bind      net.IP
+		Network   net.IPNet
+		From      mail.Address
+		Optional  *int
+		CacheSize uint64      `unit:"MiB"`
+		Key       []byte      `transform:"base64"`
+		Routes    []jsonRoute `format:"json"`
+		DB        struct {
+			Host string
+			Port int `default:"5432"`
+		}
+		Endpoints []endpoint
+		Upstreams map[string]upstream
+	}
+	var s spec
+	s.Name = "app"
+	s.Timeout = 90 * time.Second
+	s.Tags = []string{"a", "b"}
+	s.Weights = map[string]float64{"x": 0.5, "y": 2}
+	s.Features = map[string]struct{}{"beta": {}}
+	s.Share = 0.25
+	s.Endpoint, _ = url.Parse("https://example.com/v1?q=1")
+	s.Bind = net.ParseIP("10.0.0.1")
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	s.Network = *n
+	s.From = mail.Address{Name: "Gopher", Address: "gopher@example.com"}
+	s.CacheSize = 64 << 20
+	s.Key = []byte{0, 1, 2, 255}
+	s.Routes = []jsonRoute{{Path: "/api", Methods: []string{"GET"}}}
+	s.DB.Host = "db"
+	s.Endpoints = []endpoint{{Host: "a", Port: 1}, {Host: "b", Port: 2}}
+	s.Upstreams = map[string]upstream{"billing": {URL: "http://billing", Timeout: time.Second}}
+	before := s.Upstreams["billing"]
+	if err := VerifyRoundTrip(&s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Optional != nil || len(s.Upstreams) != 1 || s.Upstreams["billing"] != before {
+		t.Errorf("the spec was modified: %+v", s)
+	}
+
+	var lossy struct {
+		ID        opaqueID
+		Level     string `transform:"lower"`
+		Hosts     []string
+		Port      int  `default:"8080"`
+		Optional  *int `default:"1"`
+		Upstreams map[string]upstream
+	}
+	lossy.ID = "x"
+	lossy.Level = "WARN"
+	lossy.Hosts = []string{"a,b"}
+	lossy.Port = 8080
+	lossy.Upstreams = map[string]upstream{"Billing": {URL: "http://billing"}}
+	err := VerifyRoundTrip(&lossy)
+	var rt *RoundTripError
+	if !errors.As(err, &rt) {
+		t.Fatalf("expected a RoundTripError, got %v", err)
+	}
+	var paths []string
+	for _, f := range rt.Fields {
+		paths = append(paths, f.Path)
+	}
+	sort.Strings(paths)
+	want := []string{"Hosts", "ID", "Level", "Optional", "Upstreams[Billing].TLS.CertFile", "Upstreams[Billing].Timeout", "Upstreams[Billing].URL"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected %q, got %q\n%v", want, paths, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Sampling is a probability between 0 and 1, such as a trace sampling rate.
+// It decodes a ratio ("0.01"), a percentage ("1%") or a fraction ("1/100").
+//
+// Sampling fields tagged with the same `sampling_group` share one budget: if
+// their rates add up to more than 1, processing fails.
+//
+//	Errors  envconfig.Sampling `sampling_group:"traces" default:"100%"`
+//	Success envconfig.Sampling `sampling_group:"traces" default:"1/1000"`
+type Sampling float64
+
+// Decode implements Decoder.
+func (s *Sampling) Decode(value string) error {
+	num, den, ok := strings.Cut(value, "/")
+	if !ok {
+		v, err := parseRatio(value)
+		if err != nil {
+			return err
+		}
+		*s = Sampling(v)
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil {
+		return fmt.Errorf("invalid sampling fraction %q", value)
+	}
+	d, err := strconv.ParseFloat(strings.TrimSpace(den), 64)
+	if err != nil || d == 0 {
+		return fmt.Errorf("invalid sampling fraction %q", value)
+	}
+	if v := n / d; v >= 0 && v <= 1 {
+		*s = Sampling(v)
+		return nil
+	}
+	return fmt.Errorf("sampling fraction %q out of range [0, 1]", value)
+}
+
+// Float returns the probability as a float64.
+func (s Sampling) Float() float64 {
+	return float64(s)
+}
+
+// String formats the probability as a plain ratio.
+func (s Sampling) String() string {
+	return strconv.FormatFloat(float64(s), 'f', -1, 64)
+}
+
+// A SamplingGroupError occurs when the Sampling fields of a `sampling_group`
+// add up to more than 1.
+type SamplingGroupError struct {
+	Group string
+	Keys  []string
+	Sum   float64
+}
+
+func (e *SamplingGroupError) Error() string {
+	return fmt.Sprintf("envconfig.Process: sampling group %s (%s) adds up to %v, more than 1", e.Group, strings.Join(e.Keys, ", "), e.Sum)
+}
+
+var samplingType = reflect.TypeOf(Sampling(0))
+
+// checkSamplingGroups returns a SamplingGroupError for the first group, in
+// declaration order, whose rates add up to more than 1.
+func checkSamplingGroups(infos []varInfo) error {
+	var order []string
+	groups := make(map[string]*SamplingGroupError)
+	for _, info := range infos {
+		group := info.Tags.Get("sampling_group")
+		if group == "" || info.Field.Type() != samplingType {
+			continue
+		}
+		g, ok := groups[group]
+		if !ok {
+			g = &SamplingGroupError{Group: group}
+			groups[group] = g
+			order = append(order, group)
+		}
+		g.Keys = append(g.Keys, info.Key)
+		g.Sum += info.Field.Float()
+	}
+	for _, group := range order {
+		// allow for rounding in sums such as 0.1+0.2+0.7
+		if g := groups[group]; g.Sum > 1+1e-9 {
+			return g
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"0.01", 0.01},
+		{"1%", 0.01},
+		{"1/100", 0.01},
+		{" 1 / 4 ", 0.25},
+		{"0", 0},
+		{"1", 1},
+	}
+	for _, tt := range tests {
+		var s Sampling
+		if err := s.Decode(tt.value); err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if s.Float() != tt.want {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.want, s.Float())
+		}
+	}
+	for _, value := range []string{"", "1/0", "2/1", "-1/2", "a/b", "1.5", "150%"} {
+		var s Sampling
+		if err := s.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got %v", value, s)
+		}
+	}
+}
+
+func TestSamplingGroup(t *testing.T) {
+	var s struct {
+		Errors  Sampling `sampling_group:"traces" default:"0.5"`
+		Success Sampling `sampling_group:"traces" default:"30%"`
+		Debug   Sampling `sampling_group:"traces" default:"1/5"`
+		Other   Sampling `default:"1"`
+	}
+	os.Clearenv()
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	os.Setenv("APP_SUCCESS", "0.4")
+	err := Process("app", &s)
+	var groupErr *SamplingGroupError
+	if !errors.As(err, &groupErr) {
+		t.Fatalf("expected SamplingGroupError, got %v", err)
+	}
+	if groupErr.Group != "traces" || len(groupErr.Keys) != 3 || groupErr.Keys[1] != "APP_SUCCESS" {
+		t.Errorf("unexpected error %+v", groupErr)
+	}
+}
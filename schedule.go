@@ -0,0 +1,311 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a validated five field cron expression (minute, hour, day
+// of month, month, day of week), or one of the descriptors @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly. Fields accept
+// *, values, ranges (1-5), steps (*/15, 1-30/5), lists (1,15) and the names
+// JAN-DEC and SUN-SAT. As in cron, a day matches when either the day of month
+// or the day of week matches if both are restricted.
+type CronSchedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonths = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	cronDays = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// Decode implements Decoder.
+func (c *CronSchedule) Decode(value string) error {
+	expr := strings.TrimSpace(value)
+	spec := expr
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if spec, ok = cronDescriptors[strings.ToLower(spec)]; !ok {
+			return fmt.Errorf("cron: unknown descriptor %q", expr)
+		}
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return fmt.Errorf("cron: expected 5 fields in %q, got %d", expr, len(fields))
+	}
+	var s CronSchedule
+	var err error
+	if s.minute, err = cronField(fields[0], "minute", 0, 59, nil); err != nil {
+		return err
+	}
+	if s.hour, err = cronField(fields[1], "hour", 0, 23, nil); err != nil {
+		return err
+	}
+	if s.dom, err = cronField(fields[2], "day of month", 1, 31, nil); err != nil {
+		return err
+	}
+	if s.month, err = cronField(fields[3], "month", 1, 12, cronMonths); err != nil {
+		return err
+	}
+	if s.dow, err = cronField(fields[4], "day of week", 0, 7, cronDays); err != nil {
+		return err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domRestricted = fields[2] != "*" && fields[2] != "?"
+	s.dowRestricted = fields[4] != "*" && fields[4] != "?"
+	s.expr = expr
+	*c = s
+	return nil
+}
+
+// cronField parses one field into a bit set of the values it matches.
+func cronField(field, name string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("cron: invalid step %q in %s field", part[i+1:], name)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rng != "*" && rng != "?" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], name, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = cronValue(bounds[1], name, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("cron: range %q in %s field is backwards", rng, name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s, name string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("cron: invalid value %q in %s field", s, name)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("cron: value %d out of range [%d, %d] in %s field", v, min, max, name)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's
+// location, or the zero time if there is none within five years.
+func (c CronSchedule) Next(t time.Time) time.Time {
+	if c.minute == 0 {
+		return time.Time{}
+	}
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			// Truncate works in UTC, which misses the hour in zones with a
+			// half-hour offset.
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// String returns the expression as written.
+func (c CronSchedule) String() string {
+	return c.expr
+}
+
+// RRule is a validated RFC 5545 recurrence rule such as
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20301231T000000Z". An "RRULE:"
+// prefix is accepted. The parts are kept as written; expanding them into
+// occurrences is left to a calendar library.
+type RRule struct {
+	Freq      string
+	Interval  int
+	Count     int
+	Until     time.Time
+	WeekStart string
+	// By holds the BYxxx parts, keyed by name (e.g. "BYDAY").
+	By map[string][]string
+
+	rule string
+}
+
+var rruleFreqs = map[string]bool{"SECONDLY": true, "MINUTELY": true, "HOURLY": true,
+	"DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true}
+
+var rruleDays = map[string]bool{"MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true, "SU": true}
+
+// rruleRanges gives the magnitude limits of the numeric BYxxx parts and
+// whether negative values are allowed.
+var rruleRanges = map[string]struct {
+	min, max int
+	signed   bool
+}{
+	"BYSECOND":   {0, 60, false},
+	"BYMINUTE":   {0, 59, false},
+	"BYHOUR":     {0, 23, false},
+	"BYMONTHDAY": {1, 31, true},
+	"BYYEARDAY":  {1, 366, true},
+	"BYWEEKNO":   {1, 53, true},
+	"BYMONTH":    {1, 12, false},
+	"BYSETPOS":   {1, 366, true},
+}
+
+// Decode implements Decoder.
+func (r *RRule) Decode(value string) error {
+	rule := strings.TrimSpace(value)
+	body := rule
+	if len(body) >= 6 && strings.EqualFold(body[:6], "RRULE:") {
+		body = body[6:]
+	}
+	if body == "" {
+		return fmt.Errorf("rrule: empty rule")
+	}
+
+	out := RRule{Interval: 1, By: make(map[string][]string), rule: rule}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(body, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return fmt.Errorf("rrule: part %q is not NAME=VALUE", part)
+		}
+		name, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[name] {
+			return fmt.Errorf("rrule: %s given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			if !rruleFreqs[val] {
+				return fmt.Errorf("rrule: unknown FREQ %q", kv[1])
+			}
+			out.Freq = val
+		case "INTERVAL", "COUNT":
+			n, cerr := strconv.Atoi(val)
+			if cerr != nil || n <= 0 {
+				return fmt.Errorf("rrule: %s must be a positive integer, got %q", name, kv[1])
+			}
+			if name == "INTERVAL" {
+				out.Interval = n
+			} else {
+				out.Count = n
+			}
+		case "UNTIL":
+			if out.Until, err = parseRRuleTime(val); err != nil {
+				return err
+			}
+		case "WKST":
+			if !rruleDays[val] {
+				return fmt.Errorf("rrule: invalid WKST %q", kv[1])
+			}
+			out.WeekStart = val
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				if !rruleDays[strings.TrimLeft(d, "+-0123456789")] {
+					return fmt.Errorf("rrule: invalid BYDAY value %q", d)
+				}
+			}
+			out.By[name] = strings.Split(val, ",")
+		default:
+			lim, ok := rruleRanges[name]
+			if !ok {
+				return fmt.Errorf("rrule: unknown part %q", kv[0])
+			}
+			for _, v := range strings.Split(val, ",") {
+				n, cerr := strconv.Atoi(v)
+				if cerr == nil && n < 0 && lim.signed {
+					n = -n
+				}
+				if cerr != nil || n < lim.min || n > lim.max {
+					return fmt.Errorf("rrule: invalid %s value %q", name, v)
+				}
+			}
+			out.By[name] = strings.Split(val, ",")
+		}
+	}
+	if out.Freq == "" {
+		return fmt.Errorf("rrule: FREQ is required")
+	}
+	if out.Count != 0 && !out.Until.IsZero() {
+		return fmt.Errorf("rrule: COUNT and UNTIL cannot both be given")
+	}
+	*r = out
+	return nil
+}
+
+func parseRRuleTime(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("rrule: invalid UNTIL %q", s)
+}
+
+// String returns the rule as written.
+func (r RRule) String() string {
+	return r.rule
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * MON-FRI", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * sun", time.Date(2024, time.February, 4, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		var c CronSchedule
+		if err := c.Decode(tt.expr); err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %s, got %s", tt.expr, tt.want, got)
+		}
+		if c.String() != tt.expr {
+			t.Errorf("expected %q, got %q", tt.expr, c.String())
+		}
+	}
+}
+
+func TestCronScheduleNextOffsetZones(t *testing.T) {
+	var c CronSchedule
+	if err := c.Decode("0 12 * * *"); err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, loc := range []*time.Location{
+		time.FixedZone("IST", 5*3600+30*60),
+		time.FixedZone("NST", -(3*3600 + 30*60)),
+	} {
+		from := time.Date(2024, time.January, 31, 10, 7, 0, 0, loc)
+		want := time.Date(2024, time.January, 31, 12, 0, 0, 0, loc)
+		if got := c.Next(from); !got.Equal(want) {
+			t.Errorf("%s: expected %s, got %s", loc, want, got)
+		}
+	}
+}
+
+func TestCronScheduleErrors(t *testing.T) {
+	tests := map[string]string{
+		"* * * *":        "expected 5 fields",
+		"60 * * * *":     "value 60 out of range [0, 59] in minute field",
+		"* * * 13 *":     "out of range [1, 12] in month field",
+		"*/0 * * * *":    `invalid step "0" in minute field`,
+		"5-1 * * * *":    "is backwards",
+		"* * * * funday": `invalid value "funday" in day of week field`,
+		"@fortnightly":   "unknown descriptor",
+	}
+	for expr, want := range tests {
+		var c CronSchedule
+		err := c.Decode(expr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error containing %q, got %v", expr, want, err)
+		}
+	}
+}
+
+func TestRRule(t *testing.T) {
+	var r RRule
+	rule := "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,-1FR;UNTIL=20301231T000000Z;WKST=SU"
+	if err := r.Decode(rule); err != nil {
+		t.Fatal(err.Error())
+	}
+	if r.Freq != "WEEKLY" || r.Interval != 2 || r.WeekStart != "SU" {
+		t.Errorf("unexpected rule %+v", r)
+	}
+	if !r.Until.Equal(time.Date(2030, time.December, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected UNTIL %s", r.Until)
+	}
+	if days := r.By["BYDAY"]; len(days) != 2 || days[1] != "-1FR" {
+		t.Errorf("unexpected BYDAY %q", days)
+	}
+	if r.String() != rule {
+		t.Errorf("expected %q, got %q", rule, r.String())
+	}
+
+	if err := r.Decode("FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if r.Interval != 1 || r.Count != 3 {
+		t.Errorf("unexpected rule %+v", r)
+	}
+}
+
+func TestRRuleErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                                  "empty rule",
+		"INTERVAL=2":                        "FREQ is required",
+		"FREQ=FORTNIGHTLY":                  `unknown FREQ "FORTNIGHTLY"`,
+		"FREQ=DAILY;INTERVAL=0":             "INTERVAL must be a positive integer",
+		"FREQ=DAILY;COUNT=2;UNTIL=20300101": "COUNT and UNTIL cannot both be given",
+		"FREQ=DAILY;BYDAY=XX":               `invalid BYDAY value "XX"`,
+		"FREQ=DAILY;BYHOUR=24":              `invalid BYHOUR value "24"`,
+		"FREQ=DAILY;BYMONTH=-1":             `invalid BYMONTH value "-1"`,
+		"FREQ=DAILY;FREQ=WEEKLY":            "FREQ given more than once",
+		"FREQ=DAILY;COLOR=RED":              `unknown part "COLOR"`,
+		"FREQ=DAILY;UNTIL=tomorrow":         "invalid UNTIL",
+		"FREQ":                              "is not NAME=VALUE",
+	}
+	for rule, want := range tests {
+		var r RRule
+		err := r.Decode(rule)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error containing %q, got %v", rule, want, err)
+		}
+	}
+}
+
+func TestScheduleFields(t *testing.T) {
+	var s struct {
+		Cleanup CronSchedule `default:"@daily"`
+		Report  RRule
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REPORT", "FREQ=DAILY;BYHOUR=6")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Cleanup.String() != "@daily" || s.Report.Freq != "DAILY" {
+		t.Errorf("unexpected specification %+v", s)
+	}
+
+	os.Setenv("ENV_CONFIG_CLEANUP", "0 25 * * *")
+	if _, ok := Process("env_config", &s).(*ParseError); !ok {
+		t.Error("expected ParseError for invalid cron expression")
+	}
+}
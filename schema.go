@@ -0,0 +1,171 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SchemaHash returns a hash of the keys, types and required-ness of the
+// variables of a specification, so deployment tooling can tell whether a
+// new build expects different variables than an environment was templated
+// for. It does not depend on the environment, on field order, or on
+// defaults and descriptions. Slices and maps of structs contribute the
+// variables of one element, e.g. APP_ENDPOINTS_{N}_HOST.
+func SchemaHash(prefix string, spec interface{}) (string, error) {
+	return SchemaHashWithOptions(prefix, spec, Options{})
+}
+
+// SchemaHashWithOptions is like SchemaHash() but with specified options.
+func SchemaHashWithOptions(prefix string, spec interface{}, options Options) (string, error) {
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	options.placeholders = true
+	infos, err := gatherInfo(prefix, deepCopy(reflect.ValueOf(spec)).Interface(), options)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(infos))
+	for i, info := range infos {
+		req := info.Tags.Get("required")
+		required := isTrue(req) || options.Required && !isFalse(req)
+		lines[i] = fmt.Sprintf("%s\t%s\t%t\n", info.Key, info.Field.Type(), required)
+	}
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SchemaChangeKind classifies a SchemaChange.
+type SchemaChangeKind int
+
+const (
+	// SchemaAdded is a new optional variable, or a required one with a
+	// default.
+	SchemaAdded SchemaChangeKind = iota
+	// SchemaAddedRequired is a new required variable without a default.
+	SchemaAddedRequired
+	// SchemaRemoved is a variable that is no longer read.
+	SchemaRemoved
+	// SchemaRetyped is a variable whose type changed.
+	SchemaRetyped
+	// SchemaRenamed is a variable whose key changed, with the old key kept
+	// as its alternate name or an alias.
+	SchemaRenamed
+	// SchemaMadeRequired is an existing variable that became required
+	// without a default.
+	SchemaMadeRequired
+)
+
+func (k SchemaChangeKind) String() string {
+	switch k {
+	case SchemaAdded:
+		return "added"
+	case SchemaAddedRequired:
+		return "added required"
+	case SchemaRemoved:
+		return "removed"
+	case SchemaRetyped:
+		return "retyped"
+	case SchemaRenamed:
+		return "renamed"
+	case SchemaMadeRequired:
+		return "made required"
+	}
+	return fmt.Sprintf("SchemaChangeKind(%d)", int(k))
+}
+
+// A SchemaChange is a difference between two versions of a specification.
+// OldKey is set for renamed variables, and OldType and Type for retyped ones.
+type SchemaChange struct {
+	Kind    SchemaChangeKind
+	Key     string
+	OldKey  string
+	OldType string
+	Type    string
+}
+
+// Breaking reports whether an environment set up for the old version may
+// not work with the new one: a variable was removed or retyped, or one that
+// must be set was added.
+func (c SchemaChange) Breaking() bool {
+	switch c.Kind {
+	case SchemaAddedRequired, SchemaRemoved, SchemaRetyped, SchemaMadeRequired:
+		return true
+	}
+	return false
+}
+
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case SchemaRenamed:
+		return fmt.Sprintf("%s: renamed from %s", c.Key, c.OldKey)
+	case SchemaRetyped:
+		return fmt.Sprintf("%s: retyped from %s to %s", c.Key, c.OldType, c.Type)
+	}
+	return fmt.Sprintf("%s: %s", c.Key, c.Kind)
+}
+
+// CompareSchemas returns the changes between the variables of two versions
+// of a specification, as returned by Describe, sorted by key. A variable
+// whose new key is different but which keeps the old key as its alternate
+// name or an alias is renamed rather than removed and added, and is also
+// compared for type and required-ness. CI can store the Describe output of
+// each release and fail on any change for which Breaking is true.
+func CompareSchemas(old, new []VarSpec) []SchemaChange {
+	oldByKey := make(map[string]VarSpec, len(old))
+	for _, v := range old {
+		oldByKey[v.Key] = v
+	}
+	newKeys := make(map[string]bool, len(new))
+	for _, v := range new {
+		newKeys[v.Key] = true
+	}
+
+	var changes []SchemaChange
+	matched := make(map[string]bool, len(old))
+	for _, v := range new {
+		prev, ok := oldByKey[v.Key]
+		if !ok {
+			for _, name := range append([]string{v.Alt}, v.Aliases...) {
+				if p, found := oldByKey[name]; found && name != "" && !newKeys[name] && !matched[name] {
+					prev, ok = p, true
+					changes = append(changes, SchemaChange{Kind: SchemaRenamed, Key: v.Key, OldKey: name})
+					break
+				}
+			}
+		}
+		if !ok {
+			kind := SchemaAdded
+			if v.Required && v.Default == "" {
+				kind = SchemaAddedRequired
+			}
+			changes = append(changes, SchemaChange{Kind: kind, Key: v.Key, Type: v.Type})
+			continue
+		}
+		matched[prev.Key] = true
+		if prev.Type != v.Type {
+			changes = append(changes, SchemaChange{Kind: SchemaRetyped, Key: v.Key, OldType: prev.Type, Type: v.Type})
+		}
+		if v.Required && v.Default == "" && !(prev.Required && prev.Default == "") {
+			changes = append(changes, SchemaChange{Kind: SchemaMadeRequired, Key: v.Key, Type: v.Type})
+		}
+	}
+	for _, v := range old {
+		if !matched[v.Key] {
+			changes = append(changes, SchemaChange{Kind: SchemaRemoved, Key: v.Key, Type: v.Type})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
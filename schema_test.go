@@ -0,0 +1,123 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSchemaHash(t *testing.T) {
+	type v1 struct {
+		Host      string `required:"true"`
+		Port      int    `default:"80"`
+		Endpoints []endpoint
+	}
+	type reordered struct {
+		Port      int `default:"8080" desc:"listen port"`
+		Endpoints []endpoint
+		Host      string `required:"true"`
+	}
+	type retyped struct {
+		Host      string `required:"true"`
+		Port      uint16 `default:"80"`
+		Endpoints []endpoint
+	}
+	type optional struct {
+		Host      string
+		Port      int `default:"80"`
+		Endpoints []endpoint
+	}
+	type nested struct {
+		Host      string `required:"true"`
+		Port      int    `default:"80"`
+		Endpoints []upstream
+	}
+
+	os.Clearenv()
+	base, err := SchemaHash("app", &v1{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.HasPrefix(base, "sha256:") {
+		t.Errorf("unexpected hash %q", base)
+	}
+
+	// the environment does not matter
+	os.Setenv("APP_ENDPOINTS_0_HOST", "a")
+	os.Setenv("APP_ENDPOINTS_1_HOST", "b")
+	if h, _ := SchemaHash("app", &v1{}); h != base {
+		t.Errorf("expected %s with elements set, got %s", base, h)
+	}
+	if h, _ := SchemaHash("app", &reordered{}); h != base {
+		t.Errorf("expected %s for reordered fields, got %s", base, h)
+	}
+	for name, spec := range map[string]interface{}{
+		"retyped":  &retyped{},
+		"optional": &optional{},
+		"nested":   &nested{},
+	} {
+		if h, _ := SchemaHash("app", spec); h == base {
+			t.Errorf("%s: expected a different hash", name)
+		}
+	}
+	if h, _ := SchemaHash("other", &v1{}); h == base {
+		t.Error("expected a different hash for another prefix")
+	}
+}
+
+func TestCompareSchemas(t *testing.T) {
+	type v1 struct {
+		Host    string `required:"true"`
+		Port    int    `default:"80"`
+		Timeout int
+		Debug   bool
+		Region  string
+	}
+	type v2 struct {
+		Hostname string  `required:"true" alt:"MYAPP_HOST"`
+		Port     int     `default:"80" required:"true"`
+		Timeout  float64 `default:"1.5"`
+		Region   string  `required:"true"`
+		Token    string  `required:"true"`
+		Verbose  bool
+	}
+
+	old, err := Describe("myapp", &v1{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	next, err := Describe("myapp", &v2{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var got []string
+	var breaking []string
+	for _, c := range CompareSchemas(old, next) {
+		got = append(got, c.String())
+		if c.Breaking() {
+			breaking = append(breaking, c.Key)
+		}
+	}
+	want := []string{
+		"MYAPP_DEBUG: removed",
+		"MYAPP_HOSTNAME: renamed from MYAPP_HOST",
+		"MYAPP_REGION: made required",
+		"MYAPP_TIMEOUT: retyped from int to float64",
+		"MYAPP_TOKEN: added required",
+		"MYAPP_VERBOSE: added",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if strings.Join(breaking, ",") != "MYAPP_DEBUG,MYAPP_REGION,MYAPP_TIMEOUT,MYAPP_TOKEN" {
+		t.Errorf("unexpected breaking changes %v", breaking)
+	}
+
+	if changes := CompareSchemas(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
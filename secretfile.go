@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxFileLen bounds the size of a file named by KEY_FILE when neither
+// Options.MaxValueLen nor a `maxbytes` tag sets a limit, so a path such as
+// /dev/zero cannot exhaust memory.
+const maxFileLen = 1 << 20
+
+// fileIndirection reports whether the variable may be given as the path of a
+// file in KEY_FILE, as enabled by the `file` tag or
+// Options.AllowFileIndirection.
+func (info varInfo) fileIndirection(options Options) bool {
+	tag := info.Tags.Get("file")
+	return isTrue(tag) || options.AllowFileIndirection && !isFalse(tag)
+}
+
+// fileKeys returns the variables that may name the file holding the value:
+// KEY_FILE, and ALT_FILE for a variable with an alternate name.
+func (info varInfo) fileKeys() []string {
+	keys := []string{info.Key + "_FILE"}
+	if info.Alt != "" && info.Alt != info.Key {
+		keys = append(keys, info.Alt+"_FILE")
+	}
+	return keys
+}
+
+// lookupFile reads the value of the variable from the file named by KEY_FILE,
+// or by ALT_FILE for a variable with an alternate name. A single trailing
+// newline, as left by most editors and `echo`, is removed. Reading stops at
+// the length limit of the variable, or maxFileLen.
+func (info varInfo) lookupFile(options Options) (string, string, bool, error) {
+	var fileKey, path string
+	var ok bool
+	for _, fileKey = range info.fileKeys() {
+		var err error
+		if path, ok, err = options.lookupErr(fileKey); err != nil {
+			return "", "", false, lookupError(info, fileKey, err)
+		}
+		if ok {
+			break
+		}
+	}
+	if !ok {
+		return "", "", false, nil
+	}
+	max, err := info.maxLen(options)
+	if err != nil {
+		return "", "", false, err
+	}
+	if max <= 0 || max > maxFileLen {
+		max = maxFileLen
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("envconfig.Process: reading %s for %s: %w", fileKey, info.Name, err)
+	}
+	defer f.Close()
+	// leave room for the trailing newline, and a byte more to detect a file
+	// that is too long
+	data, err := io.ReadAll(io.LimitReader(f, int64(max)+3))
+	if err != nil {
+		return "", "", false, fmt.Errorf("envconfig.Process: reading %s for %s: %w", fileKey, info.Name, err)
+	}
+	if len(data) > max+2 {
+		return "", "", false, fmt.Errorf("envconfig.Process: file named by %s for %s is longer than %d bytes", fileKey, info.Name, max)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	return value, fileKey, true, nil
+}
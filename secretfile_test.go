@@ -0,0 +1,97 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileIndirection(t *testing.T) {
+	var s struct {
+		Password string `required:"true" split_words:"true"`
+		APIKey   string `envconfig:"API_KEY" file:"true"`
+		User     string `file:"false"`
+		DBHost   string `split_words:"true" default:"localhost"`
+	}
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0600)
+		return path
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PASSWORD_FILE", write("password", "s3cr3t\n"))
+	os.Setenv("API_KEY_FILE", write("api_key", "key\r\n"))
+	os.Setenv("APP_USER_FILE", write("user", "nobody"))
+	os.Setenv("APP_DB_HOST_FILE", write("host", "db.internal"))
+	var res Result
+	if err := ProcessWithOptions("app", &s, Options{AllowFileIndirection: true, Result: &res}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Password != "s3cr3t" || s.APIKey != "key" || s.User != "" || s.DBHost != "db.internal" {
+		t.Errorf("unexpected values %+v", s)
+	}
+	if res.FromSecretStore != 3 {
+		t.Errorf("expected %d from secret files, got %d", 3, res.FromSecretStore)
+	}
+
+	// the tag alone enables indirection
+	s.APIKey = ""
+	os.Setenv("APP_PASSWORD", "direct")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.APIKey != "key" {
+		t.Errorf("expected %q, got %q", "key", s.APIKey)
+	}
+
+	if err := ProcessWithOptions("app", &s, Options{AllowFileIndirection: true}); err == nil || !strings.Contains(err.Error(), "APP_PASSWORD_FILE") {
+		t.Errorf("expected error for both APP_PASSWORD and APP_PASSWORD_FILE, got %v", err)
+	}
+
+	os.Unsetenv("APP_PASSWORD")
+	os.Setenv("APP_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	if err := ProcessWithOptions("app", &s, Options{AllowFileIndirection: true}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestFileIndirectionReporting(t *testing.T) {
+	var s struct {
+		Password string `split_words:"true" file:"true" maxbytes:"8"`
+	}
+	path := filepath.Join(t.TempDir(), "password")
+	os.WriteFile(path, []byte("s3cr3t\n"), 0600)
+
+	os.Clearenv()
+	os.Setenv("APP_PASSWORD_FILE", path)
+	if err := CheckDisallowed("app", &s); err != nil {
+		t.Errorf("expected APP_PASSWORD_FILE to be allowed, got %v", err)
+	}
+	env, err := Environ("app", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(env) != 1 || env[0] != "APP_PASSWORD_FILE="+path {
+		t.Errorf("expected APP_PASSWORD_FILE in the environment, got %v", env)
+	}
+	explained, err := Explain("app", &s, "APP_PASSWORD")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(explained, "result:   contents of "+path+" from APP_PASSWORD_FILE") {
+		t.Errorf("expected the file to be reported, got:\n%s", explained)
+	}
+
+	os.WriteFile(path, []byte("much too long\n"), 0600)
+	if err := Process("app", &s); err == nil || !strings.Contains(err.Error(), "longer than 8 bytes") {
+		t.Errorf("expected error for a file over the limit, got %v", err)
+	}
+}
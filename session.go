@@ -0,0 +1,239 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Session records the lookups made while processing a specification, so
+// a problem reported from a production environment can be reproduced
+// locally with ReplayLookuper. Values of sensitive variables are left out.
+type Session struct {
+	Prefix   string          `json:"prefix"`
+	Started  time.Time       `json:"started"`
+	Duration time.Duration   `json:"duration"`
+	Lookups  []SessionLookup `json:"lookups"`
+	Err      string          `json:"error,omitempty"`
+	// Keys lists the keys under the prefix that the source listed, when
+	// processing asked for them to find the entries of maps of structs.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// A SessionLookup is a lookup recorded in a Session, in the order made.
+type SessionLookup struct {
+	Key    string `json:"key"`
+	Found  bool   `json:"found"`
+	Source string `json:"source"`
+	Value  string `json:"value,omitempty"`
+	// Redacted is set for sensitive variables, whose Value is left out.
+	Redacted bool          `json:"redacted,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Err is the error of a lookup that failed.
+	Err string `json:"error,omitempty"`
+}
+
+// Record is like ProcessWithOptions() but also returns the session of
+// lookups it made. The session is returned when processing fails too, with
+// the error in Err.
+func Record(prefix string, spec interface{}, options Options) (*Session, error) {
+	l := options.Lookuper
+	if l == nil {
+		l = OSLookuper()
+	}
+	r := &recorder{next: l, session: &Session{Prefix: prefix, Started: time.Now()}}
+	options.Lookuper = r
+	err := ProcessWithOptions(prefix, spec, options)
+
+	s := r.session
+	s.Duration = time.Since(s.Started)
+	if err != nil {
+		s.Err = err.Error()
+	}
+	sensitive := sensitiveKeys(prefix, spec, options)
+	for i, lookup := range s.Lookups {
+		if sensitive[lookup.Key] && lookup.Found {
+			s.Lookups[i].Value, s.Lookups[i].Redacted = "", true
+		}
+	}
+	return s, err
+}
+
+// sensitiveKeys returns the keys, alternate names and aliases of the
+// sensitive variables of the processed spec.
+func sensitiveKeys(prefix string, spec interface{}, options Options) map[string]bool {
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	options.fromValues = true
+	keys := make(map[string]bool)
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return keys
+	}
+	infos, err := gatherInfo(prefix, deepCopy(v).Interface(), options)
+	if err != nil {
+		return keys
+	}
+	for _, info := range infos {
+		if !info.isSensitive() {
+			continue
+		}
+		keys[info.Key] = true
+		if info.Alt != "" {
+			keys[info.Alt] = true
+		}
+		for _, alias := range info.aliases() {
+			keys[alias] = true
+		}
+	}
+	return keys
+}
+
+// recorder is the Lookuper through which Record looks up variables.
+type recorder struct {
+	next    Lookuper
+	mu      sync.Mutex
+	session *Session
+}
+
+func (r *recorder) Lookup(key string) (string, bool) {
+	return r.LookupContext(context.Background(), key)
+}
+
+func (r *recorder) LookupContext(ctx context.Context, key string) (string, bool) {
+	value, ok, _ := r.LookupErr(ctx, key)
+	return value, ok
+}
+
+func (r *recorder) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	start := time.Now()
+	value, ok, err := lookupWith(ctx, r.next, key)
+	lookup := SessionLookup{
+		Key:      key,
+		Found:    ok,
+		Source:   sourceName(r.next),
+		Value:    value,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		lookup.Err = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Lookups = append(r.session.Lookups, lookup)
+	return value, ok, err
+}
+
+func (r *recorder) Keys() []string {
+	kl, ok := r.next.(KeyLister)
+	if !ok {
+		return nil
+	}
+	keys := kl.Keys()
+	prefix := envPrefix(r.session.Prefix)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Keys = r.session.Keys[:0]
+	for _, key := range keys {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			r.session.Keys = append(r.session.Keys, key)
+		}
+	}
+	return keys
+}
+
+func (r *recorder) String() string {
+	return sourceName(r.next)
+}
+
+// Save writes the session to path as JSON.
+func (s *Session) Save(path string) error {
+	doc, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(doc, '\n'), 0600)
+}
+
+// LoadSession reads a session written by Save.
+func LoadSession(path string) (*Session, error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(doc, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ReplayLookuper returns a Lookuper that answers each key as it was first
+// answered in the session, and lists the keys listed then. Keys that were
+// not looked up are unset, and lookups that failed fail again. Redacted values are replayed as "<sensitive>";
+// put a Lookuper with stand-ins for them first in a MultiLookuper. With
+// timings, each lookup takes as long as it did when recorded, to reproduce
+// timeouts.
+func ReplayLookuper(s *Session, timings bool) Lookuper {
+	r := &replayer{keys: s.Keys, values: make(map[string]SessionLookup), timings: timings}
+	for _, lookup := range s.Lookups {
+		if _, ok := r.values[lookup.Key]; !ok {
+			r.values[lookup.Key] = lookup
+		}
+	}
+	return r
+}
+
+type replayer struct {
+	keys    []string
+	values  map[string]SessionLookup
+	timings bool
+}
+
+func (r *replayer) Lookup(key string) (string, bool) {
+	return r.LookupContext(context.Background(), key)
+}
+
+func (r *replayer) LookupContext(ctx context.Context, key string) (string, bool) {
+	value, ok, _ := r.LookupErr(ctx, key)
+	return value, ok
+}
+
+func (r *replayer) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	lookup, ok := r.values[key]
+	if !ok {
+		return "", false, nil
+	}
+	if r.timings && lookup.Duration > 0 {
+		t := time.NewTimer(lookup.Duration)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return "", false, nil
+		}
+	}
+	switch {
+	case lookup.Err != "":
+		return "", false, errors.New(lookup.Err)
+	case lookup.Redacted:
+		return "<sensitive>", lookup.Found, nil
+	}
+	return lookup.Value, lookup.Found, nil
+}
+
+func (r *replayer) Keys() []string {
+	return r.keys
+}
+
+func (r *replayer) String() string {
+	return "replay"
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	type spec struct {
+		Host     string `required:"true"`
+		Port     int    `default:"80"`
+		Password string `sensitive:"true" envconfig:"DB_PASSWORD"`
+		Backends map[string]struct {
+			URL string
+		}
+	}
+	env := MapLookuper{
+		"MYAPP_HOST":              "db",
+		"MYAPP_PORT":              "eighty",
+		"MYAPP_DB_PASSWORD":       "hunter2",
+		"MYAPP_BACKENDS_MAIN_URL": "http://main",
+		"OTHER":                   "x",
+	}
+	var s spec
+	session, err := Record("myapp", &s, Options{Lookuper: env, CollectErrors: true})
+	if err == nil || session == nil || !strings.Contains(session.Err, "MYAPP_PORT") {
+		t.Fatalf("expected the session of a failed run, got %v and %+v", err, session)
+	}
+	if session.Prefix != "myapp" || len(session.Lookups) == 0 || session.Lookups[0].Source != "map" {
+		t.Errorf("unexpected session %+v", session)
+	}
+	for _, lookup := range session.Lookups {
+		if lookup.Key == "MYAPP_DB_PASSWORD" && (!lookup.Redacted || lookup.Value != "") {
+			t.Errorf("expected the password to be redacted, got %+v", lookup)
+		}
+		if lookup.Key == "MYAPP_HOST" && lookup.Value != "db" {
+			t.Errorf("expected the host to be recorded, got %+v", lookup)
+		}
+	}
+	for _, key := range session.Keys {
+		if key == "OTHER" {
+			t.Error("expected only keys under the prefix to be recorded")
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := session.Save(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	doc, _ := os.ReadFile(path)
+	if strings.Contains(string(doc), "hunter2") {
+		t.Error("expected the saved session not to contain the password")
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var replayed spec
+	err = ProcessWithOptions("myapp", &replayed, Options{Lookuper: ReplayLookuper(loaded, false), CollectErrors: true})
+	if err == nil || err.Error() != session.Err {
+		t.Errorf("expected the replay to fail as recorded with %q, got %v", session.Err, err)
+	}
+	if replayed.Host != "db" || replayed.Password != "<sensitive>" {
+		t.Errorf("unexpected replayed values %+v", replayed)
+	}
+
+	// the entries of maps of structs are replayed from the recorded keys
+	env["MYAPP_PORT"] = "80"
+	if session, err = Record("myapp", &s, Options{Lookuper: env}); err != nil {
+		t.Fatal(err.Error())
+	}
+	replayed = spec{}
+	if err := ProcessWithOptions("myapp", &replayed, Options{Lookuper: ReplayLookuper(session, false)}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if replayed.Port != 80 || replayed.Backends["main"].URL != "http://main" {
+		t.Errorf("unexpected replayed values %+v", replayed)
+	}
+}
+
+func TestReplayTimings(t *testing.T) {
+	session := &Session{Lookups: []SessionLookup{{Key: "MYAPP_HOST", Found: true, Value: "db", Duration: time.Hour}}}
+	var s struct {
+		Host string
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := ProcessContext(ctx, "myapp", &s, Options{Lookuper: ReplayLookuper(session, true)})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the recorded lookup time to exceed the deadline, got %v", err)
+	}
+	if err := ProcessWithOptions("myapp", &s, Options{Lookuper: ReplayLookuper(session, false)}); err != nil || s.Host != "db" {
+		t.Errorf("expected db without timings, got %q and %v", s.Host, err)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import "reflect"
+
+// Set is a collection of unique values decoded from a comma-separated list.
+// Any map with empty struct values, such as map[string]struct{}, is decoded
+// the same way.
+type Set[T comparable] map[T]struct{}
+
+// Has reports whether v is a member of the set.
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// isSetType reports whether t is a map whose values are empty structs.
+func isSetType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
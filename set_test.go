@@ -0,0 +1,53 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSetFields(t *testing.T) {
+	var s struct {
+		Allowed map[string]struct{}
+		Ports   Set[int]
+		Empty   Set[string]
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_ALLOWED", "alice,bob,alice")
+	os.Setenv("ENV_CONFIG_PORTS", "80,443")
+	os.Setenv("ENV_CONFIG_EMPTY", "")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]struct{}{"alice": {}, "bob": {}}; !reflect.DeepEqual(s.Allowed, want) {
+		t.Errorf("expected %v, got %v", want, s.Allowed)
+	}
+	if !s.Ports.Has(443) || s.Ports.Has(8080) || len(s.Ports) != 2 {
+		t.Errorf("expected {80, 443}, got %v", s.Ports)
+	}
+	if s.Empty == nil || len(s.Empty) != 0 {
+		t.Errorf("expected empty set, got %#v", s.Empty)
+	}
+}
+
+func TestSetParseError(t *testing.T) {
+	var s struct {
+		Ports Set[int]
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORTS", "80,http")
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
+
+func TestSetTypeDescription(t *testing.T) {
+	if got := toTypeDescription(reflect.TypeOf(Set[int]{}), ",", ":"); got != "Comma-separated set of Integer" {
+		t.Errorf("expected %q, got %q", "Comma-separated set of Integer", got)
+	}
+}
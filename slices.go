@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// normalizeSlice applies the `unique` and `sorted` tags to a decoded slice
+// field. Duplicates are removed keeping the first occurrence, and sorting is
+// stable and ascending.
+func normalizeSlice(field reflect.Value, unique, sorted bool) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Slice {
+		return errors.New("unique and sorted require a slice")
+	}
+
+	if unique {
+		if !field.Type().Elem().Comparable() {
+			return errors.New("unique requires comparable slice elements")
+		}
+		seen := make(map[interface{}]struct{}, field.Len())
+		out := reflect.MakeSlice(field.Type(), 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			v := field.Index(i)
+			if _, ok := seen[v.Interface()]; ok {
+				continue
+			}
+			seen[v.Interface()] = struct{}{}
+			out = reflect.Append(out, v)
+		}
+		field.Set(out)
+	}
+
+	if sorted {
+		less, err := lessFunc(field)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(field.Interface(), less)
+	}
+	return nil
+}
+
+// lessFunc returns an ascending comparison for the elements of slice.
+func lessFunc(slice reflect.Value) (func(i, j int) bool, error) {
+	switch slice.Type().Elem().Kind() {
+	case reflect.String:
+		return func(i, j int) bool { return slice.Index(i).String() < slice.Index(j).String() }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(i, j int) bool { return slice.Index(i).Int() < slice.Index(j).Int() }, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(i, j int) bool { return slice.Index(i).Uint() < slice.Index(j).Uint() }, nil
+	case reflect.Float32, reflect.Float64:
+		return func(i, j int) bool { return slice.Index(i).Float() < slice.Index(j).Float() }, nil
+	}
+	return nil, errors.New("sorted requires a slice of strings or numbers")
+}
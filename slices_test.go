@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUniqueSortedSlices(t *testing.T) {
+	var s struct {
+		Hosts   []string  `unique:"true"`
+		IDs     []int     `sorted:"true"`
+		Weights []float64 `unique:"true" sorted:"true"`
+		Raw     []string
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOSTS", "b,a,b,c,a")
+	os.Setenv("ENV_CONFIG_IDS", "3,1,2,1")
+	os.Setenv("ENV_CONFIG_WEIGHTS", "0.5,0.1,0.5")
+	os.Setenv("ENV_CONFIG_RAW", "b,a,b")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(s.Hosts, want) {
+		t.Errorf("expected %v, got %v", want, s.Hosts)
+	}
+	if want := []int{1, 1, 2, 3}; !reflect.DeepEqual(s.IDs, want) {
+		t.Errorf("expected %v, got %v", want, s.IDs)
+	}
+	if want := []float64{0.1, 0.5}; !reflect.DeepEqual(s.Weights, want) {
+		t.Errorf("expected %v, got %v", want, s.Weights)
+	}
+	if want := []string{"b", "a", "b"}; !reflect.DeepEqual(s.Raw, want) {
+		t.Errorf("expected %v, got %v", want, s.Raw)
+	}
+}
+
+func TestSortedUnsupportedElement(t *testing.T) {
+	var s struct {
+		Flags []bool `sorted:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_FLAGS", "true,false")
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
+
+func TestUniqueOnNonSlice(t *testing.T) {
+	var s struct {
+		Name string `unique:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "a")
+	err := Process("env_config", &s)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("expected ParseError, got %T %v", err, err)
+	}
+}
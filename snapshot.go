@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvSnapshot records the environment variables under a prefix.
+type EnvSnapshot struct {
+	prefix string
+	vars   map[string]string
+}
+
+// SnapshotEnv records the environment variables whose names start with the
+// prefix followed by an underscore, or every variable when prefix is empty.
+func SnapshotEnv(prefix string) *EnvSnapshot {
+	s := &EnvSnapshot{prefix: envPrefix(prefix), vars: make(map[string]string)}
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) == 2 && strings.HasPrefix(kv[0], s.prefix) {
+			s.vars[kv[0]] = kv[1]
+		}
+	}
+	return s
+}
+
+// RestoreEnv puts the variables under the snapshot's prefix back the way they
+// were when it was taken: variables set since are unset and changed or
+// removed ones are set again. Variables outside the prefix are untouched.
+func RestoreEnv(s *EnvSnapshot) error {
+	for _, env := range os.Environ() {
+		key := strings.SplitN(env, "=", 2)[0]
+		if !strings.HasPrefix(key, s.prefix) {
+			continue
+		}
+		if _, ok := s.vars[key]; !ok {
+			if err := os.Unsetenv(key); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range s.vars {
+		if current, ok := lookupEnv(key); ok && current == value {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envPrefix returns the variable name prefix used for prefix: upper cased
+// and followed by an underscore, or empty when prefix is empty.
+func envPrefix(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return strings.ToUpper(prefix) + "_"
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSnapshotRestoreEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("OTHER", "untouched")
+
+	snap := SnapshotEnv("app")
+
+	os.Setenv("APP_HOST", "changed")
+	os.Unsetenv("APP_PORT")
+	os.Setenv("APP_NEW", "added")
+	os.Setenv("OTHER", "changed")
+	os.Setenv("APPLICATION", "outside")
+
+	if err := RestoreEnv(snap); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := map[string]string{
+		"APP_HOST":    "localhost",
+		"APP_PORT":    "8080",
+		"OTHER":       "changed",
+		"APPLICATION": "outside",
+	}
+	for key, value := range want {
+		if got, ok := os.LookupEnv(key); !ok || got != value {
+			t.Errorf("%s: expected %q, got %q (%v)", key, value, got, ok)
+		}
+	}
+	if _, ok := os.LookupEnv("APP_NEW"); ok {
+		t.Error("expected APP_NEW to be unset")
+	}
+}
+
+func TestSnapshotRestoreEnvNoPrefix(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("A", "1")
+	snap := SnapshotEnv("")
+	os.Setenv("B", "2")
+	os.Setenv("A", "3")
+	if err := RestoreEnv(snap); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(os.Environ()) != 1 || os.Getenv("A") != "1" {
+		t.Errorf("expected only A=1, got %v", os.Environ())
+	}
+}
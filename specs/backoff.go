@@ -0,0 +1,128 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Stop is returned by Backoff.NextBackOff when no more retries should be made.
+const Stop time.Duration = -1
+
+// BackoffSpec holds an exponential retry policy. The delay starts at Initial
+// and grows by Multiplier up to Max, each delay varied randomly by up to
+// Jitter either way. Retries end after MaxAttempts attempts or MaxElapsed
+// since the first, when they are set.
+type BackoffSpec struct {
+	Initial     time.Duration     `default:"100ms"`
+	Max         time.Duration     `default:"30s"`
+	Multiplier  float64           `default:"2"`
+	Jitter      envconfig.Percent `default:"20%" desc:"random variation of each delay, e.g. 20%"`
+	MaxAttempts int               `split_words:"true" desc:"0 for no limit"`
+	MaxElapsed  time.Duration     `split_words:"true" desc:"0 for no limit"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *BackoffSpec) Validate() error {
+	if s.Initial <= 0 {
+		return errors.New("initial backoff must be positive")
+	}
+	if s.Initial > s.Max {
+		return fmt.Errorf("initial backoff %v exceeds max %v", s.Initial, s.Max)
+	}
+	if s.Multiplier < 1 {
+		return fmt.Errorf("backoff multiplier %v is less than 1", s.Multiplier)
+	}
+	if s.Jitter < 0 || s.Jitter > 1 {
+		return fmt.Errorf("backoff jitter %v out of range [0%%, 100%%]", s.Jitter)
+	}
+	if s.MaxAttempts < 0 || s.MaxElapsed < 0 {
+		return errors.New("negative backoff limit")
+	}
+	return nil
+}
+
+// New returns a Backoff following the policy, which is started by its first
+// NextBackOff.
+func (s *BackoffSpec) New() *Backoff {
+	return &Backoff{spec: *s, now: time.Now, rand: rand.Float64}
+}
+
+// Backoff yields the delays of a BackoffSpec. It has the NextBackOff and
+// Reset methods of the BackOff interface of github.com/cenkalti/backoff, so
+// it may be passed to that package's Retry. A Backoff is not safe for
+// concurrent use.
+type Backoff struct {
+	spec     BackoffSpec
+	now      func() time.Time
+	rand     func() float64
+	interval time.Duration
+	attempts int
+	start    time.Time
+}
+
+// Reset starts the sequence of delays again.
+func (b *Backoff) Reset() {
+	b.interval, b.attempts, b.start = 0, 0, time.Time{}
+}
+
+// NextBackOff returns the delay before the next retry, or Stop when the
+// limits of the policy are reached.
+func (b *Backoff) NextBackOff() time.Duration {
+	if b.start.IsZero() {
+		b.start = b.now()
+	}
+	b.attempts++
+	if b.spec.MaxAttempts > 0 && b.attempts >= b.spec.MaxAttempts {
+		return Stop
+	}
+
+	if b.interval == 0 {
+		b.interval = b.spec.Initial
+	} else if next := time.Duration(float64(b.interval) * b.spec.Multiplier); next < b.spec.Max && next > 0 {
+		b.interval = next
+	} else {
+		b.interval = b.spec.Max
+	}
+	delay := b.interval
+	if j := b.spec.Jitter.Float(); j > 0 {
+		delay = time.Duration(float64(delay) * (1 - j + 2*j*b.rand()))
+	}
+
+	if b.spec.MaxElapsed > 0 && b.now().Add(delay).Sub(b.start) > b.spec.MaxElapsed {
+		return Stop
+	}
+	return delay
+}
+
+// Retry calls fn until it succeeds, waiting the delays of the policy between
+// attempts. It returns the last error of fn when the policy stops, or the
+// context's error if it is canceled first.
+func (s *BackoffSpec) Retry(ctx context.Context, fn func(context.Context) error) error {
+	b := s.New()
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		delay := b.NextBackOff()
+		if delay == Stop {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestBackoffSpec(t *testing.T) {
+	var s struct {
+		Retry BackoffSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_RETRY_INITIAL", "1s")
+	os.Setenv("MYAPP_RETRY_MAX", "5s")
+	os.Setenv("MYAPP_RETRY_MULTIPLIER", "3")
+	os.Setenv("MYAPP_RETRY_JITTER", "0")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	b := s.Retry.New()
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		got = append(got, b.NextBackOff())
+	}
+	want := []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	b.Reset()
+	if d := b.NextBackOff(); d != time.Second {
+		t.Errorf("expected 1s after reset, got %v", d)
+	}
+
+	os.Setenv("MYAPP_RETRY_INITIAL", "10s")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for initial above max, got nil")
+	}
+}
+
+func TestBackoffLimits(t *testing.T) {
+	s := BackoffSpec{Initial: time.Second, Max: time.Minute, Multiplier: 2, Jitter: 0.5, MaxAttempts: 3}
+	b := s.New()
+	b.rand = func() float64 { return 1 }
+	if d := b.NextBackOff(); d != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s with full jitter, got %v", d)
+	}
+	b.NextBackOff()
+	if d := b.NextBackOff(); d != Stop {
+		t.Errorf("expected Stop after 3 attempts, got %v", d)
+	}
+
+	now := time.Unix(0, 0)
+	s = BackoffSpec{Initial: time.Second, Max: time.Minute, Multiplier: 2, MaxElapsed: 5 * time.Second}
+	b = s.New()
+	b.now = func() time.Time { return now }
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, Stop} {
+		d := b.NextBackOff()
+		if d != want {
+			t.Errorf("expected %v, got %v", want, d)
+		}
+		now = now.Add(d)
+	}
+}
+
+func TestBackoffRetry(t *testing.T) {
+	s := BackoffSpec{Initial: time.Millisecond, Max: time.Millisecond, Multiplier: 1, MaxAttempts: 3}
+	calls := 0
+	errFail := errors.New("fail")
+	err := s.Retry(context.Background(), func(context.Context) error {
+		calls++
+		return errFail
+	})
+	if err != errFail || calls != 3 {
+		t.Errorf("expected 3 calls and the last error, got %d and %v", calls, err)
+	}
+
+	calls = 0
+	err = s.Retry(context.Background(), func(context.Context) error {
+		if calls++; calls < 2 {
+			return errFail
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected success on the second call, got %d and %v", calls, err)
+	}
+}
+
+func TestBackoffSpecValidate(t *testing.T) {
+	for _, s := range []BackoffSpec{
+		{Initial: 0, Max: time.Second, Multiplier: 2},
+		{Initial: time.Second, Max: time.Second, Multiplier: 0.5},
+		{Initial: time.Second, Max: time.Second, Multiplier: 2, Jitter: 2},
+		{Initial: time.Second, Max: time.Second, Multiplier: 2, MaxAttempts: -1},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+}
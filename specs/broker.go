@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// SASL mechanisms accepted by KafkaSpec.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+	SASLOAuthBearer = "OAUTHBEARER"
+)
+
+// KafkaSpec holds the settings of a Kafka client. A SASL mechanism other than
+// OAUTHBEARER requires a username and password.
+type KafkaSpec struct {
+	Brokers       []string `required:"true" desc:"comma separated host:port list"`
+	ClientID      string   `split_words:"true"`
+	ConsumerGroup string   `split_words:"true"`
+	TLS           TLSSpec
+	SASLMechanism string `split_words:"true" desc:"PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or OAUTHBEARER"`
+	SASLUsername  string `split_words:"true"`
+	SASLPassword  string `split_words:"true" sensitive:"true"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *KafkaSpec) Validate() error {
+	if len(s.Brokers) == 0 {
+		return errors.New("no brokers")
+	}
+	for _, broker := range s.Brokers {
+		if err := checkHostPort(broker); err != nil {
+			return err
+		}
+	}
+	switch s.SASLMechanism {
+	case "":
+		if s.SASLUsername != "" || s.SASLPassword != "" {
+			return errors.New("SASL credentials are given but no SASL mechanism")
+		}
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if s.SASLUsername == "" || s.SASLPassword == "" {
+			return fmt.Errorf("SASL mechanism %s requires a username and password", s.SASLMechanism)
+		}
+	case SASLOAuthBearer:
+		if s.SASLUsername != "" || s.SASLPassword != "" {
+			return fmt.Errorf("SASL mechanism %s does not take a username or password", s.SASLMechanism)
+		}
+	default:
+		return fmt.Errorf("unknown SASL mechanism %q", s.SASLMechanism)
+	}
+	return nil
+}
+
+// AMQPSpec holds the settings of an AMQP 0-9-1 client such as one for
+// RabbitMQ. An amqps URL requires TLS to be enabled and an amqp URL requires
+// it to be disabled, so the two cannot disagree.
+type AMQPSpec struct {
+	URL      string `required:"true" sensitive:"true" desc:"amqp:// or amqps:// URL, including credentials"`
+	Exchange string
+	Queue    string
+	Prefetch int `default:"10" desc:"unacknowledged messages per consumer"`
+	TLS      TLSSpec
+}
+
+// Validate implements envconfig.Validator.
+func (s *AMQPSpec) Validate() error {
+	// the URL may hold a password, so it is left out of the errors
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Host == "" {
+		return errors.New("invalid AMQP URL")
+	}
+	switch u.Scheme {
+	case "amqp":
+		if s.TLS.Enabled {
+			return errors.New("TLS is enabled but the AMQP URL is not amqps")
+		}
+	case "amqps":
+		if !s.TLS.Enabled {
+			return errors.New("the AMQP URL is amqps but TLS is not enabled")
+		}
+	default:
+		return fmt.Errorf("AMQP URL has scheme %q, expected amqp or amqps", u.Scheme)
+	}
+	if s.Prefetch < 0 {
+		return fmt.Errorf("negative prefetch %d", s.Prefetch)
+	}
+	return nil
+}
+
+// checkHostPort checks that addr is a host and a numeric port.
+func checkHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %v", addr, err)
+	}
+	if host == "" {
+		return fmt.Errorf("invalid address %q: missing host", addr)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("invalid address %q: bad port", addr)
+	}
+	return nil
+}
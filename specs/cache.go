@@ -0,0 +1,130 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RedisSpec holds the settings of a Redis client. One address connects to a
+// single server, several to a cluster, and several with MasterName to the
+// Sentinels of a failover group.
+type RedisSpec struct {
+	Addrs        []string `default:"localhost:6379" desc:"comma separated host:port list"`
+	MasterName   string   `split_words:"true" desc:"Sentinel master name"`
+	Username     string
+	Password     string `sensitive:"true"`
+	DB           int    `desc:"database number, not supported by clusters"`
+	TLS          TLSSpec
+	PoolSize     int           `split_words:"true" desc:"connections per server, 0 for the client default"`
+	MinIdleConns int           `split_words:"true"`
+	DialTimeout  time.Duration `split_words:"true" default:"5s"`
+	ReadTimeout  time.Duration `split_words:"true" default:"3s"`
+	WriteTimeout time.Duration `split_words:"true" default:"3s"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *RedisSpec) Validate() error {
+	if len(s.Addrs) == 0 {
+		return errors.New("no Redis addresses")
+	}
+	for _, addr := range s.Addrs {
+		if err := checkHostPort(addr); err != nil {
+			return err
+		}
+	}
+	if s.DB < 0 {
+		return fmt.Errorf("negative DB %d", s.DB)
+	}
+	if s.DB != 0 && len(s.Addrs) > 1 && s.MasterName == "" {
+		return errors.New("a Redis cluster only has DB 0")
+	}
+	if s.Username != "" && s.Password == "" {
+		return errors.New("Redis username is given without a password")
+	}
+	if s.PoolSize < 0 || s.MinIdleConns < 0 {
+		return errors.New("negative Redis pool size")
+	}
+	if s.PoolSize > 0 && s.MinIdleConns > s.PoolSize {
+		return fmt.Errorf("Redis min idle conns %d exceeds pool size %d", s.MinIdleConns, s.PoolSize)
+	}
+	if s.DialTimeout < 0 || s.ReadTimeout < 0 || s.WriteTimeout < 0 {
+		return errors.New("negative Redis timeout")
+	}
+	return nil
+}
+
+// RedisOptions has the fields of redis.UniversalOptions from
+// github.com/redis/go-redis, which this package does not import, and of the
+// same named fields in the Options of the single server, cluster and failover
+// clients. Convert it with a struct literal.
+type RedisOptions struct {
+	Addrs        []string
+	MasterName   string
+	Username     string
+	Password     string
+	DB           int
+	TLSConfig    *tls.Config
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// Options returns the client options, loading the TLS files when TLS is
+// enabled.
+func (s *RedisSpec) Options() (RedisOptions, error) {
+	tlsConfig, err := s.TLS.Config()
+	if err != nil {
+		return RedisOptions{}, err
+	}
+	return RedisOptions{
+		Addrs:        append([]string(nil), s.Addrs...),
+		MasterName:   s.MasterName,
+		Username:     s.Username,
+		Password:     s.Password,
+		DB:           s.DB,
+		TLSConfig:    tlsConfig,
+		PoolSize:     s.PoolSize,
+		MinIdleConns: s.MinIdleConns,
+		DialTimeout:  s.DialTimeout,
+		ReadTimeout:  s.ReadTimeout,
+		WriteTimeout: s.WriteTimeout,
+	}, nil
+}
+
+// MemcachedSpec holds the settings of a Memcached client. The fields match
+// those of memcache.Client from github.com/bradfitz/gomemcache:
+//
+//	mc := memcache.New(s.Cache.Servers...)
+//	mc.Timeout, mc.MaxIdleConns = s.Cache.Timeout, s.Cache.MaxIdleConns
+type MemcachedSpec struct {
+	Servers      []string      `default:"localhost:11211" desc:"comma separated host:port list"`
+	Timeout      time.Duration `default:"100ms"`
+	MaxIdleConns int           `split_words:"true" default:"2"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *MemcachedSpec) Validate() error {
+	if len(s.Servers) == 0 {
+		return errors.New("no Memcached servers")
+	}
+	for _, server := range s.Servers {
+		if err := checkHostPort(server); err != nil {
+			return err
+		}
+	}
+	if s.Timeout < 0 {
+		return errors.New("negative Memcached timeout")
+	}
+	if s.MaxIdleConns < 0 {
+		return errors.New("negative Memcached max idle conns")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestRedisSpec(t *testing.T) {
+	var s struct {
+		Cache RedisSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_CACHE_PASSWORD", "secret")
+	os.Setenv("MYAPP_CACHE_DB", "2")
+	os.Setenv("MYAPP_CACHE_POOL_SIZE", "20")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	opts, err := s.Cache.Options()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(opts.Addrs) != 1 || opts.Addrs[0] != "localhost:6379" || opts.DB != 2 || opts.PoolSize != 20 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if opts.DialTimeout != 5*time.Second || opts.TLSConfig != nil {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
+
+func TestRedisSpecValidate(t *testing.T) {
+	addrs := []string{"redis:6379"}
+	for _, s := range []RedisSpec{
+		{},
+		{Addrs: []string{"redis"}},
+		{Addrs: []string{"a:6379", "b:6379"}, DB: 1},
+		{Addrs: addrs, DB: -1},
+		{Addrs: addrs, Username: "u"},
+		{Addrs: addrs, PoolSize: 5, MinIdleConns: 10},
+		{Addrs: addrs, ReadTimeout: -time.Second},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	s := RedisSpec{Addrs: []string{"a:26379", "b:26379"}, MasterName: "main", DB: 1}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMemcachedSpec(t *testing.T) {
+	var s struct {
+		Sessions MemcachedSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_SESSIONS_SERVERS", "mc-1:11211,mc-2:11211")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(s.Sessions.Servers) != 2 || s.Sessions.Timeout != 100*time.Millisecond || s.Sessions.MaxIdleConns != 2 {
+		t.Errorf("unexpected spec %+v", s.Sessions)
+	}
+
+	os.Setenv("MYAPP_SESSIONS_SERVERS", "mc-1")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for a server without a port, got nil")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"fmt"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Bounds enforced by ConcurrencySpec.Validate, to catch a stray "100x" or a
+// queue depth given in bytes.
+const (
+	MaxWorkers    = 4096
+	MaxQueueDepth = 1 << 20
+)
+
+// ConcurrencySpec holds the size of a worker pool and of the queue feeding
+// it. Each value is a number or is relative to the CPUs of the host, so one
+// setting suits machines of any size:
+//
+//	MYAPP_POOL_WORKERS=2x           two workers per CPU
+//	MYAPP_POOL_WORKERS=numcpu-1     leave a CPU for everything else
+//	MYAPP_POOL_QUEUE_DEPTH=100      a fixed depth
+type ConcurrencySpec struct {
+	Workers    envconfig.Concurrency `default:"1x" desc:"worker count, e.g. 8, 2x or numcpu-1"`
+	QueueDepth envconfig.Concurrency `split_words:"true" default:"4x" desc:"queued items, 0 for none"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *ConcurrencySpec) Validate() error {
+	if s.Workers < 1 || s.Workers > MaxWorkers {
+		return fmt.Errorf("worker count %d out of range [1, %d]", s.Workers, MaxWorkers)
+	}
+	if s.QueueDepth < 0 || s.QueueDepth > MaxQueueDepth {
+		return fmt.Errorf("queue depth %d out of range [0, %d]", s.QueueDepth, MaxQueueDepth)
+	}
+	return nil
+}
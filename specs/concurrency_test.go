@@ -0,0 +1,54 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestConcurrencySpec(t *testing.T) {
+	var s struct {
+		Pool ConcurrencySpec
+	}
+	os.Clearenv()
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if want := runtime.NumCPU(); s.Pool.Workers.Int() != want {
+		t.Errorf("expected %d workers, got %d", want, s.Pool.Workers)
+	}
+	if want := 4 * runtime.NumCPU(); s.Pool.QueueDepth.Int() != want {
+		t.Errorf("expected queue depth %d, got %d", want, s.Pool.QueueDepth)
+	}
+
+	os.Setenv("MYAPP_POOL_WORKERS", "3")
+	os.Setenv("MYAPP_POOL_QUEUE_DEPTH", "0")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Pool.Workers != 3 || s.Pool.QueueDepth != 0 {
+		t.Errorf("expected 3 workers and no queue, got %d and %d", s.Pool.Workers, s.Pool.QueueDepth)
+	}
+}
+
+func TestConcurrencySpecValidate(t *testing.T) {
+	for _, s := range []ConcurrencySpec{
+		{Workers: 0, QueueDepth: 1},
+		{Workers: MaxWorkers + 1},
+		{Workers: 1, QueueDepth: MaxQueueDepth + 1},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	s := ConcurrencySpec{Workers: MaxWorkers, QueueDepth: MaxQueueDepth}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSSpec holds a cross-origin resource sharing policy. An allowed origin is
+// "*", a full origin such as "https://app.example.com", or one with a
+// wildcard subdomain such as "https://*.example.com".
+//
+//	http.Handle("/api/", s.CORS.Middleware(api))
+type CORSSpec struct {
+	AllowedOrigins   []string      `split_words:"true" desc:"origins, https://*.example.com or *"`
+	AllowedMethods   []string      `split_words:"true" default:"GET,HEAD,POST"`
+	AllowedHeaders   []string      `split_words:"true"`
+	ExposedHeaders   []string      `split_words:"true"`
+	MaxAge           time.Duration `split_words:"true" default:"10m" desc:"how long browsers cache a preflight"`
+	AllowCredentials bool          `split_words:"true"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *CORSSpec) Validate() error {
+	for _, origin := range s.AllowedOrigins {
+		if origin == "*" {
+			if s.AllowCredentials {
+				return errors.New("CORS credentials cannot be allowed for the wildcard origin")
+			}
+			continue
+		}
+		if err := checkOrigin(origin); err != nil {
+			return err
+		}
+	}
+	for _, m := range s.AllowedMethods {
+		if m == "" || strings.ToUpper(m) != m || strings.ContainsAny(m, " \t,") {
+			return fmt.Errorf("invalid CORS method %q", m)
+		}
+	}
+	if s.MaxAge < 0 {
+		return errors.New("negative CORS max age")
+	}
+	return nil
+}
+
+// checkOrigin checks an origin, which may start its host with "*.".
+func checkOrigin(origin string) error {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.User != nil || strings.Contains(u.Host, "*") {
+		return fmt.Errorf("invalid CORS origin %q, expected scheme://host[:port]", origin)
+	}
+	return nil
+}
+
+// allowOrigin reports whether a request from origin is allowed.
+func (s *CORSSpec) allowOrigin(origin string) bool {
+	for _, allowed := range s.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if i := strings.Index(allowed, "://*."); i >= 0 {
+			prefix, suffix := allowed[:i+3], allowed[i+4:]
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Middleware returns a handler that adds the CORS headers of the policy and
+// answers preflight requests itself, passing every other request to next.
+func (s *CORSSpec) Middleware(next http.Handler) http.Handler {
+	wildcard := false
+	for _, o := range s.AllowedOrigins {
+		wildcard = wildcard || o == "*"
+	}
+	methods := strings.Join(s.AllowedMethods, ", ")
+	headers := strings.Join(s.AllowedHeaders, ", ")
+	exposed := strings.Join(s.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(s.MaxAge / time.Second))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" || !s.allowOrigin(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wildcard && !s.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if s.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", methods)
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		if s.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestCORSSpecMiddleware(t *testing.T) {
+	var s struct {
+		CORS CORSSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_CORS_ALLOWED_ORIGINS", "https://app.example.com,https://*.preview.example.com")
+	os.Setenv("MYAPP_CORS_ALLOWED_HEADERS", "Authorization,Content-Type")
+	os.Setenv("MYAPP_CORS_ALLOW_CREDENTIALS", "true")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	called := false
+	h := s.CORS.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	r := httptest.NewRequest("OPTIONS", "/api", nil)
+	r.Header.Set("Origin", "https://pr-12.preview.example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || called {
+		t.Errorf("expected a preflight response, got %d", w.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://pr-12.preview.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, HEAD, POST",
+		"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+		"Access-Control-Max-Age":           "600",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s: expected %q, got %q", header, want, got)
+		}
+	}
+
+	r = httptest.NewRequest("GET", "/api", nil)
+	r.Header.Set("Origin", "https://evil.example.org")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected the request to pass without CORS headers")
+	}
+}
+
+func TestCORSSpecValidate(t *testing.T) {
+	for _, s := range []CORSSpec{
+		{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		{AllowedOrigins: []string{"example.com"}},
+		{AllowedOrigins: []string{"https://example.com/app"}},
+		{AllowedOrigins: []string{"https://api.*.example.com"}},
+		{AllowedOrigins: []string{"ftp://example.com"}},
+		{AllowedMethods: []string{"get"}},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	s := CORSSpec{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "PUT"}}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
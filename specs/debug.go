@@ -0,0 +1,91 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+)
+
+// DebugSpec holds the settings of a pprof debug endpoint. Validate refuses to
+// serve it without a token on an address other than loopback, as profiles
+// and goroutine dumps leak memory contents and the handlers can be used to
+// load the process; set AllowUnauthenticated to do so anyway.
+type DebugSpec struct {
+	Enabled              bool
+	Addr                 string `default:"127.0.0.1:6060"`
+	Token                string `sensitive:"true" desc:"bearer token required by the endpoint"`
+	AllowUnauthenticated bool   `split_words:"true" desc:"serve without a token on a non-loopback address"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *DebugSpec) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %q: %v", s.Addr, err)
+	}
+	if s.Token == "" && !s.AllowUnauthenticated && !isLoopback(host) {
+		return fmt.Errorf("debug endpoint on %s has no token; set a token, listen on loopback or allow unauthenticated access", s.Addr)
+	}
+	return nil
+}
+
+// isLoopback reports whether host only accepts local connections. An empty
+// host listens on every interface.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Handler returns the pprof handlers under /debug/pprof/, requiring the
+// token as "Authorization: Bearer <token>" when one is set.
+func (s *DebugSpec) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	if s.Token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + s.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Server returns a server for the endpoint, or nil when it is disabled.
+//
+//	if srv, err := s.Debug.Server(); err != nil {
+//		log.Fatal(err)
+//	} else if srv != nil {
+//		go srv.ListenAndServe()
+//	}
+func (s *DebugSpec) Server() (*http.Server, error) {
+	if !s.Enabled {
+		return nil, nil
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &http.Server{Addr: s.Addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestDebugSpec(t *testing.T) {
+	var s struct {
+		Debug DebugSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_DEBUG_ENABLED", "true")
+	os.Setenv("MYAPP_DEBUG_ADDR", ":6060")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an unauthenticated endpoint on every interface, got nil")
+	}
+
+	os.Setenv("MYAPP_DEBUG_TOKEN", "s3cret")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	srv, err := s.Debug.Server()
+	if err != nil || srv == nil {
+		t.Fatalf("expected a server, got %v", err)
+	}
+	for auth, want := range map[string]int{"": http.StatusUnauthorized, "Bearer wrong": http.StatusUnauthorized, "Bearer s3cret": http.StatusOK} {
+		r := httptest.NewRequest("GET", "/debug/pprof/cmdline", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("%q: expected status %d, got %d", auth, want, w.Code)
+		}
+	}
+}
+
+func TestDebugSpecValidate(t *testing.T) {
+	for _, s := range []DebugSpec{
+		{Enabled: false, Addr: "0.0.0.0:6060"},
+		{Enabled: true, Addr: "127.0.0.1:6060"},
+		{Enabled: true, Addr: "[::1]:6060"},
+		{Enabled: true, Addr: "localhost:6060"},
+		{Enabled: true, Addr: "0.0.0.0:6060", AllowUnauthenticated: true},
+	} {
+		if err := s.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error: %v", s, err)
+		}
+	}
+	for _, s := range []DebugSpec{
+		{Enabled: true, Addr: "0.0.0.0:6060"},
+		{Enabled: true, Addr: "10.0.0.5:6060"},
+		{Enabled: true, Addr: "6060"},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	var s DebugSpec
+	if srv, err := s.Server(); srv != nil || err != nil {
+		t.Errorf("expected no server when disabled, got %v and %v", srv, err)
+	}
+}
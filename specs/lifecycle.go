@@ -0,0 +1,123 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// LifecycleSpec holds the timing of a server's startup and shutdown. After a
+// termination signal the server has DrainTimeout to finish in-flight work,
+// within the ShutdownGrace the platform allows before it kills the process
+// (terminationGracePeriodSeconds on Kubernetes). StartupProbeDelay holds off
+// readiness after startup, e.g. while caches warm.
+//
+//	ctx, stop := s.Lifecycle.NotifyContext(context.Background())
+//	defer stop()
+//	go srv.ListenAndServe()
+//	<-ctx.Done()
+//	err := s.Lifecycle.Drain(srv.Shutdown)
+type LifecycleSpec struct {
+	ShutdownGrace     time.Duration `split_words:"true" default:"30s"`
+	DrainTimeout      time.Duration `split_words:"true" default:"20s"`
+	StartupProbeDelay time.Duration `split_words:"true"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *LifecycleSpec) Validate() error {
+	if s.ShutdownGrace < 0 || s.DrainTimeout < 0 || s.StartupProbeDelay < 0 {
+		return errors.New("negative lifecycle duration")
+	}
+	if s.DrainTimeout > s.ShutdownGrace {
+		return fmt.Errorf("drain timeout %v exceeds shutdown grace %v", s.DrainTimeout, s.ShutdownGrace)
+	}
+	return nil
+}
+
+// NotifyContext returns a context that is canceled when the process receives
+// one of signals, or SIGINT or SIGTERM when none are given. If the drain then
+// overruns the shutdown grace, the process exits with status 1 rather than
+// wait to be killed. Calling stop releases the signals.
+func (s *LifecycleSpec) NotifyContext(parent context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, release := signal.NotifyContext(parent, signals...)
+	if s.ShutdownGrace <= 0 {
+		return ctx, release
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		if parent.Err() != nil {
+			return
+		}
+		select {
+		case <-done:
+		case <-time.After(s.ShutdownGrace):
+			fmt.Fprintf(os.Stderr, "shutdown grace of %v exceeded\n", s.ShutdownGrace)
+			exit(1)
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		release()
+		once.Do(func() { close(done) })
+	}
+}
+
+// exit is replaced in tests.
+var exit = os.Exit
+
+// DrainContext returns a context that expires after DrainTimeout, to pass to
+// shutdown functions such as http.Server.Shutdown.
+func (s *LifecycleSpec) DrainContext() (context.Context, context.CancelFunc) {
+	if s.DrainTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.DrainTimeout)
+}
+
+// Drain calls each of the shutdown functions in order with a context that
+// expires after DrainTimeout, and returns the first error.
+func (s *LifecycleSpec) Drain(shutdown ...func(context.Context) error) error {
+	ctx, cancel := s.DrainContext()
+	defer cancel()
+	var first error
+	for _, fn := range shutdown {
+		if err := fn(ctx); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// WaitStartup waits StartupProbeDelay, returning early with the context's
+// error if it is canceled first.
+func (s *LifecycleSpec) WaitStartup(ctx context.Context) error {
+	if s.StartupProbeDelay <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(s.StartupProbeDelay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestLifecycleSpec(t *testing.T) {
+	var s struct {
+		Lifecycle LifecycleSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_LIFECYCLE_DRAIN_TIMEOUT", "10s")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Lifecycle.ShutdownGrace != 30*time.Second || s.Lifecycle.DrainTimeout != 10*time.Second {
+		t.Errorf("unexpected spec %+v", s.Lifecycle)
+	}
+
+	os.Setenv("MYAPP_LIFECYCLE_DRAIN_TIMEOUT", "45s")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for a drain timeout over the grace, got nil")
+	}
+}
+
+func TestLifecycleSpecDrain(t *testing.T) {
+	s := LifecycleSpec{ShutdownGrace: time.Second, DrainTimeout: 10 * time.Millisecond}
+	var calls int
+	err := s.Drain(
+		func(ctx context.Context) error {
+			calls++
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		func(ctx context.Context) error {
+			calls++
+			return nil
+		},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 2 {
+		t.Errorf("expected both functions to run and a deadline error, got %d calls and %v", calls, err)
+	}
+}
+
+func TestLifecycleSpecWaitStartup(t *testing.T) {
+	s := LifecycleSpec{StartupProbeDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.WaitStartup(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	s.StartupProbeDelay = time.Millisecond
+	if err := s.WaitStartup(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
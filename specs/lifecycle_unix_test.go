@@ -0,0 +1,40 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package specs
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLifecycleSpecNotifyContext(t *testing.T) {
+	exited := make(chan int, 1)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	s := LifecycleSpec{ShutdownGrace: 20 * time.Millisecond}
+	ctx, stop := s.NotifyContext(context.Background(), syscall.SIGUSR1)
+	defer stop()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not canceled by the signal")
+	}
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("expected exit status 1, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected exit after the shutdown grace")
+	}
+}
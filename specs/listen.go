@@ -0,0 +1,123 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ListenSpec holds the address a server listens on, which is one of
+//
+//	:8080, 127.0.0.1:8080, tcp://[::1]:8080  a TCP address
+//	unix:///run/app.sock                    a Unix socket, created with SocketMode
+//	fd://3                                  an inherited socket, as passed by
+//	                                        systemd or launchd socket activation
+//
+// so a binary supports each style of deployment from one variable.
+type ListenSpec struct {
+	Addr       string `default:":8080" desc:"host:port, unix:///path or fd://N"`
+	SocketMode uint32 `split_words:"true" default:"0660" desc:"permissions of a Unix socket"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *ListenSpec) Validate() error {
+	_, _, err := s.parse()
+	return err
+}
+
+// parse returns the network and address to listen on, or "fd" and the file
+// descriptor number.
+func (s *ListenSpec) parse() (network, addr string, err error) {
+	if !strings.Contains(s.Addr, "://") {
+		if _, _, err := net.SplitHostPort(s.Addr); err != nil {
+			return "", "", fmt.Errorf("invalid listen address %q: %v", s.Addr, err)
+		}
+		return "tcp", s.Addr, nil
+	}
+
+	u, err := url.Parse(s.Addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid listen address %q: %v", s.Addr, err)
+	}
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		if _, _, err := net.SplitHostPort(u.Host); err != nil || u.Path != "" {
+			return "", "", fmt.Errorf("invalid listen address %q: expected %s://host:port", s.Addr, u.Scheme)
+		}
+		return u.Scheme, u.Host, nil
+	case "unix":
+		if u.Host != "" || u.Path == "" {
+			return "", "", fmt.Errorf("invalid listen address %q: expected unix:///path", s.Addr)
+		}
+		return "unix", u.Path, nil
+	case "fd":
+		if n, err := strconv.Atoi(u.Host); err != nil || n < 0 || u.Path != "" {
+			return "", "", fmt.Errorf("invalid listen address %q: expected fd://N", s.Addr)
+		}
+		return "fd", u.Host, nil
+	}
+	return "", "", fmt.Errorf("invalid listen address %q: unknown scheme %q", s.Addr, u.Scheme)
+}
+
+// Listen returns a listener for the address. A stale Unix socket left by a
+// previous run is removed first.
+func (s *ListenSpec) Listen(ctx context.Context) (net.Listener, error) {
+	network, addr, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	switch network {
+	case "fd":
+		return listenFD(addr)
+	case "unix":
+		if fi, err := os.Lstat(addr); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			if conn, err := net.Dial("unix", addr); err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("specs: %s is in use", addr)
+			}
+			os.Remove(addr)
+		}
+	}
+
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" && s.SocketMode != 0 {
+		if err := os.Chmod(addr, os.FileMode(s.SocketMode)&os.ModePerm); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// listenFD wraps an inherited listening socket. With systemd socket
+// activation LISTEN_PID, when set, must name this process.
+func listenFD(fd string) (net.Listener, error) {
+	if pid := os.Getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, errors.New("specs: LISTEN_PID is set for another process")
+	}
+	n, _ := strconv.Atoi(fd)
+	f := os.NewFile(uintptr(n), "fd://"+fd)
+	if f == nil {
+		return nil, fmt.Errorf("specs: invalid file descriptor %s", fd)
+	}
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("specs: fd://%s: %v", fd, err)
+	}
+	return l, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestListenSpecTCP(t *testing.T) {
+	var s struct {
+		HTTP ListenSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_HTTP_ADDR", "127.0.0.1:0")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	l, err := s.HTTP.Listen(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer l.Close()
+	if l.Addr().Network() != "tcp" {
+		t.Errorf("expected a tcp listener, got %s", l.Addr().Network())
+	}
+}
+
+func TestListenSpecUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets")
+	}
+	path := filepath.Join(t.TempDir(), "app.sock")
+	s := ListenSpec{Addr: "unix://" + path, SocketMode: 0600}
+	l, err := s.Listen(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", fi.Mode().Perm())
+	}
+	if _, err := s.Listen(context.Background()); err == nil {
+		t.Error("expected error for a socket in use, got nil")
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	// a stale socket is replaced
+	l, err = s.Listen(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	l.Close()
+}
+
+func TestListenSpecFD(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file listeners")
+	}
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer inherited.Close()
+	f, err := inherited.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer f.Close()
+
+	s := ListenSpec{Addr: "fd://" + strconv.Itoa(int(f.Fd()))}
+	l, err := s.Listen(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer l.Close()
+	if l.Addr().String() != inherited.Addr().String() {
+		t.Errorf("expected %s, got %s", inherited.Addr(), l.Addr())
+	}
+}
+
+func TestListenSpecValidate(t *testing.T) {
+	for _, addr := range []string{":8080", "localhost:80", "tcp://[::1]:8080", "unix:///run/app.sock", "fd://3"} {
+		s := ListenSpec{Addr: addr}
+		if err := s.Validate(); err != nil {
+			t.Errorf("%q: unexpected error: %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"8080", "unix://run/app.sock", "fd://three", "fd://3/x", "udp://:53", "tcp://:80/x"} {
+		s := ListenSpec{Addr: addr}
+		if err := s.Validate(); err == nil {
+			t.Errorf("%q: expected error, got nil", addr)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/url"
+	"os"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// LogSpec holds the settings of an application's log output. Each of the
+// Destinations is one of
+//
+//	stdout, stderr
+//	file:///var/log/app.log      appended to, created if needed
+//	syslog://                    the local syslog daemon
+//	syslog://host:514            a remote daemon over UDP (syslog+tcp:// for TCP)
+//
+// SampleRate keeps that share of the records below warning level; warnings
+// and errors are always written.
+type LogSpec struct {
+	Level        slog.Level         `default:"info" desc:"debug, info, warn or error"`
+	Format       string             `default:"text" desc:"text or json"`
+	SampleRate   envconfig.Sampling `split_words:"true" default:"1"`
+	Destinations []string           `default:"stdout" desc:"stdout, stderr, file:///path or syslog://[host:port]"`
+	AddSource    bool               `split_words:"true" desc:"include the source file and line"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *LogSpec) Validate() error {
+	switch s.Format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", s.Format)
+	}
+	if s.SampleRate < 0 || s.SampleRate > 1 {
+		return fmt.Errorf("log sample rate %v out of range [0, 1]", float64(s.SampleRate))
+	}
+	if len(s.Destinations) == 0 {
+		return errors.New("no log destinations")
+	}
+	for _, dest := range s.Destinations {
+		if _, err := parseLogDestination(dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseLogDestination parses a destination, returning a URL whose scheme is
+// "stdout", "stderr", "file", "syslog" or "syslog+tcp".
+func parseLogDestination(dest string) (*url.URL, error) {
+	switch dest {
+	case "stdout", "stderr":
+		return &url.URL{Scheme: dest}, nil
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log destination %q: %v", dest, err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" && u.Opaque == "" {
+			return nil, fmt.Errorf("invalid log destination %q: expected file:///path", dest)
+		}
+		return u, nil
+	case "syslog", "syslog+tcp":
+		if u.Path != "" && u.Path != "/" {
+			return nil, fmt.Errorf("invalid log destination %q: expected %s://host:port", dest, u.Scheme)
+		}
+		return u, nil
+	}
+	return nil, fmt.Errorf("invalid log destination %q: expected stdout, stderr, file:// or syslog://", dest)
+}
+
+// Handler returns a slog.Handler writing to every destination. Calling
+// close closes the files and syslog connections it opened.
+//
+//	h, closeLog, err := s.Log.Handler()
+//	defer closeLog()
+//	slog.SetDefault(slog.New(h))
+func (s *LogSpec) Handler() (h slog.Handler, close func() error, err error) {
+	var (
+		writers []io.Writer
+		closers []io.Closer
+	)
+	close = func() error {
+		var first error
+		for _, c := range closers {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+	for _, dest := range s.Destinations {
+		u, err := parseLogDestination(dest)
+		if err != nil {
+			close()
+			return nil, nil, err
+		}
+		var w io.Writer
+		switch u.Scheme {
+		case "stdout":
+			w = os.Stdout
+		case "stderr":
+			w = os.Stderr
+		case "file":
+			path := u.Path
+			if path == "" {
+				path = u.Opaque
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+			if err != nil {
+				close()
+				return nil, nil, err
+			}
+			w, closers = f, append(closers, f)
+		default:
+			network := ""
+			if u.Host != "" {
+				network = "udp"
+				if u.Scheme == "syslog+tcp" {
+					network = "tcp"
+				}
+			}
+			sw, err := dialSyslog(network, u.Host)
+			if err != nil {
+				close()
+				return nil, nil, fmt.Errorf("specs: %s: %v", dest, err)
+			}
+			w, closers = sw, append(closers, sw)
+		}
+		writers = append(writers, w)
+	}
+
+	out := io.MultiWriter(writers...)
+	opts := &slog.HandlerOptions{Level: s.Level, AddSource: s.AddSource}
+	if s.Format == "json" {
+		h = slog.NewJSONHandler(out, opts)
+	} else {
+		h = slog.NewTextHandler(out, opts)
+	}
+	if s.SampleRate < 1 {
+		h = &samplingHandler{Handler: h, rate: float64(s.SampleRate), rand: rand.Float64}
+	}
+	return h, close, nil
+}
+
+// samplingHandler drops a share of the records below warning level.
+type samplingHandler struct {
+	slog.Handler
+	rate float64
+	rand func() float64
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && h.rand() >= h.rate {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), rate: h.rate, rand: h.rand}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), rate: h.rate, rand: h.rand}
+}
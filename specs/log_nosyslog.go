@@ -0,0 +1,18 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build go1.21 && (windows || plan9)
+// +build go1.21
+// +build windows plan9
+
+package specs
+
+import (
+	"errors"
+	"io"
+)
+
+func dialSyslog(network, addr string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
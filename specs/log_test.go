@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package specs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestLogSpecHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var s struct {
+		Log LogSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_LOG_LEVEL", "warn")
+	os.Setenv("MYAPP_LOG_FORMAT", "json")
+	os.Setenv("MYAPP_LOG_DESTINATIONS", "file://"+path)
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	h, closeLog, err := s.Log.Handler()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger := slog.New(h)
+	logger.Info("dropped")
+	logger.Warn("kept", "n", 1)
+	if err := closeLog(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one record, got %q", data)
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil || rec["msg"] != "kept" || rec["level"] != "WARN" {
+		t.Errorf("unexpected record %q", lines[0])
+	}
+}
+
+func TestLogSpecSampling(t *testing.T) {
+	var kept []string
+	h := &samplingHandler{Handler: recordHandler{&kept}, rate: 0.5, rand: func() float64 { return 0.7 }}
+	logger := slog.New(h.WithAttrs(nil))
+	logger.Info("sampled out")
+	logger.Error("always kept")
+	h.rand = func() float64 { return 0.2 }
+	slog.New(h).Info("sampled in")
+	if strings.Join(kept, ",") != "always kept,sampled in" {
+		t.Errorf("unexpected records %q", kept)
+	}
+}
+
+type recordHandler struct{ msgs *[]string }
+
+func (recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h recordHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.msgs = append(*h.msgs, r.Message)
+	return nil
+}
+func (h recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h recordHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestLogSpecValidate(t *testing.T) {
+	for _, s := range []LogSpec{
+		{Format: "xml", Destinations: []string{"stdout"}},
+		{Format: "text"},
+		{Format: "text", Destinations: []string{"/var/log/app.log"}},
+		{Format: "text", Destinations: []string{"syslog://host:514/x"}},
+		{Format: "text", Destinations: []string{"stdout"}, SampleRate: 2},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	s := LogSpec{Format: "json", SampleRate: 1, Destinations: []string{"stderr", "file:///tmp/app.log", "syslog://", "syslog+tcp://logs:601"}}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
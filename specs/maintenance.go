@@ -0,0 +1,197 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// MaintenanceWindow is a period in which maintenance may run, written as
+//
+//	0 2 * * SUN for 4h           a cron schedule and how long each window lasts
+//	SAT 22:00-SUN 04:00          a weekly range
+//	01:00-03:30                  a daily range, which may cross midnight
+//	2026-11-01T00:00/2026-11-01T06:00  a one-off range
+//
+// Times are read in the time zone of the MaintenanceSpec.
+type MaintenanceWindow struct {
+	expr string
+
+	// a cron schedule and duration
+	cron     envconfig.CronSchedule
+	duration time.Duration
+
+	// a one-off range, wall clock times reinterpreted in the time zone
+	from, to time.Time
+
+	// a weekly or daily range, in minutes from the start of the week or day
+	period     int
+	start, end int
+}
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+var weekdays = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+
+// Decode implements envconfig.Decoder.
+func (w *MaintenanceWindow) Decode(value string) error {
+	s := strings.TrimSpace(value)
+	*w = MaintenanceWindow{expr: s}
+
+	if i := strings.LastIndex(s, " for "); i >= 0 {
+		if err := w.cron.Decode(s[:i]); err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(s[i+len(" for "):]))
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid maintenance window duration in %q", value)
+		}
+		w.duration = d
+		return nil
+	}
+
+	if from, to, ok := strings.Cut(s, "/"); ok {
+		var err error
+		if w.from, err = time.Parse("2006-01-02T15:04", strings.TrimSpace(from)); err == nil {
+			w.to, err = time.Parse("2006-01-02T15:04", strings.TrimSpace(to))
+		}
+		if err != nil || !w.to.After(w.from) {
+			return fmt.Errorf("invalid maintenance window %q, expected 2006-01-02T15:04/2006-01-02T15:04", value)
+		}
+		return nil
+	}
+
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return fmt.Errorf("invalid maintenance window %q", value)
+	}
+	start, startWeekly, err := parseWindowTime(from)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance window %q: %v", value, err)
+	}
+	end, endWeekly, err := parseWindowTime(to)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance window %q: %v", value, err)
+	}
+	if startWeekly != endWeekly {
+		return fmt.Errorf("invalid maintenance window %q: give a weekday for both ends or neither", value)
+	}
+	if start == end {
+		return fmt.Errorf("maintenance window %q is empty", value)
+	}
+	w.period, w.start, w.end = minutesPerDay, start, end
+	if startWeekly {
+		w.period = minutesPerWeek
+	}
+	return nil
+}
+
+// parseWindowTime parses "HH:MM" or "DAY HH:MM" into minutes from the start
+// of the day or week.
+func parseWindowTime(s string) (minutes int, weekly bool, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 2 {
+		day, ok := weekdays[strings.ToUpper(fields[0])]
+		if !ok {
+			return 0, false, fmt.Errorf("unknown weekday %q", fields[0])
+		}
+		minutes, weekly = day*minutesPerDay, true
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return 0, false, fmt.Errorf("expected HH:MM or DAY HH:MM, got %q", s)
+	}
+	h, m, ok := strings.Cut(fields[0], ":")
+	hour, herr := strconv.Atoi(h)
+	minute, merr := strconv.Atoi(m)
+	if !ok || herr != nil || merr != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, false, fmt.Errorf("invalid time %q", fields[0])
+	}
+	return minutes + hour*60 + minute, weekly, nil
+}
+
+// String returns the window as written.
+func (w MaintenanceWindow) String() string {
+	return w.expr
+}
+
+// active reports whether t, already in the window's time zone, is within
+// the window.
+func (w MaintenanceWindow) active(t time.Time) bool {
+	switch {
+	case w.duration > 0:
+		start := w.cron.Next(t.Add(-w.duration))
+		return !start.IsZero() && !start.After(t)
+	case !w.to.IsZero():
+		from := time.Date(w.from.Year(), w.from.Month(), w.from.Day(), w.from.Hour(), w.from.Minute(), 0, 0, t.Location())
+		to := time.Date(w.to.Year(), w.to.Month(), w.to.Day(), w.to.Hour(), w.to.Minute(), 0, 0, t.Location())
+		return !t.Before(from) && t.Before(to)
+	case w.period > 0:
+		m := t.Hour()*60 + t.Minute()
+		if w.period == minutesPerWeek {
+			m += int(t.Weekday()) * minutesPerDay
+		}
+		if w.start < w.end {
+			return m >= w.start && m < w.end
+		}
+		return m >= w.start || m < w.end
+	}
+	return false
+}
+
+// MaintenanceSpec holds the windows in which batch jobs, migrations and
+// other disruptive work may run. The windows are separated by semicolons, as
+// cron expressions may contain commas:
+//
+//	MYAPP_MAINTENANCE_WINDOWS=0 2 * * SUN for 4h;01:00-01:30
+//	MYAPP_MAINTENANCE_TIME_ZONE=Europe/Berlin
+type MaintenanceSpec struct {
+	Windows  []MaintenanceWindow `delimiter:";" desc:"semicolon separated windows"`
+	TimeZone string              `split_words:"true" default:"UTC" desc:"IANA time zone of the windows"`
+
+	loc *time.Location
+}
+
+// Validate implements envconfig.Validator.
+func (s *MaintenanceSpec) Validate() error {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance time zone %q: %v", s.TimeZone, err)
+	}
+	s.loc = loc
+	return nil
+}
+
+// Location returns the time zone of the windows, or UTC if it cannot be
+// loaded.
+func (s *MaintenanceSpec) Location() *time.Location {
+	if s.loc == nil {
+		if loc, err := time.LoadLocation(s.TimeZone); err == nil {
+			s.loc = loc
+		} else {
+			return time.UTC
+		}
+	}
+	return s.loc
+}
+
+// Active reports whether t is within one of the windows.
+func (s *MaintenanceSpec) Active(t time.Time) bool {
+	t = t.In(s.Location())
+	for _, w := range s.Windows {
+		if w.active(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestMaintenanceSpec(t *testing.T) {
+	var s struct {
+		Maintenance MaintenanceSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_MAINTENANCE_WINDOWS", "0 2 * * SUN for 4h; SAT 22:00-SAT 23:00; 23:30-00:15; 2026-11-04T12:00/2026-11-04T13:00")
+	os.Setenv("MYAPP_MAINTENANCE_TIME_ZONE", "UTC")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	m := &s.Maintenance
+	for at, want := range map[string]bool{
+		"2026-11-01T02:00:00Z": true,  // Sunday, start of the cron window
+		"2026-11-01T05:59:00Z": true,  // end of the cron window
+		"2026-11-01T06:00:00Z": false, // after it
+		"2026-11-01T01:59:00Z": false,
+		"2026-11-07T22:30:00Z": true, // Saturday range
+		"2026-11-06T22:30:00Z": false,
+		"2026-11-03T23:45:00Z": true, // daily range across midnight
+		"2026-11-04T00:10:00Z": true,
+		"2026-11-04T00:15:00Z": false,
+		"2026-11-04T12:30:00Z": true, // one-off
+		"2026-11-05T12:30:00Z": false,
+	} {
+		tm, _ := time.Parse(time.RFC3339, at)
+		if got := m.Active(tm); got != want {
+			t.Errorf("%s: expected %v, got %v", at, want, got)
+		}
+	}
+
+	os.Setenv("MYAPP_MAINTENANCE_TIME_ZONE", "Mars/Olympus_Mons")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an unknown time zone, got nil")
+	}
+}
+
+func TestMaintenanceSpecTimeZone(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skip("no time zone database")
+	}
+	s := MaintenanceSpec{TimeZone: "America/New_York"}
+	var w MaintenanceWindow
+	if err := w.Decode("01:00-02:00"); err != nil {
+		t.Fatal(err.Error())
+	}
+	s.Windows = []MaintenanceWindow{w}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !s.Active(time.Date(2026, 7, 1, 5, 30, 0, 0, time.UTC)) {
+		t.Error("expected 01:30 in New York to be active")
+	}
+}
+
+func TestMaintenanceSpecOffsetZones(t *testing.T) {
+	var w MaintenanceWindow
+	if err := w.Decode("0 12 * * * for 1h"); err != nil {
+		t.Fatal(err.Error())
+	}
+	for zone, noon := range map[string]time.Time{
+		"Asia/Kolkata":     time.Date(2026, 7, 1, 6, 45, 0, 0, time.UTC),
+		"America/St_Johns": time.Date(2026, 7, 1, 14, 45, 0, 0, time.UTC),
+	} {
+		if _, err := time.LoadLocation(zone); err != nil {
+			t.Skip("no time zone database")
+		}
+		s := MaintenanceSpec{TimeZone: zone, Windows: []MaintenanceWindow{w}}
+		if err := s.Validate(); err != nil {
+			t.Fatal(err.Error())
+		}
+		if !s.Active(noon) {
+			t.Errorf("%s: expected 12:15 to be active", zone)
+		}
+		if s.Active(noon.Add(time.Hour)) {
+			t.Errorf("%s: expected 13:15 to be inactive", zone)
+		}
+	}
+}
+
+func TestMaintenanceWindowDecode(t *testing.T) {
+	for _, value := range []string{
+		"", "sometimes", "0 2 * * SUN for", "@daily for -1h", "25:00-01:00",
+		"MON 01:00-02:00", "FOO 01:00-BAR 02:00", "01:00-01:00",
+		"2026-11-04T13:00/2026-11-04T12:00",
+	} {
+		var w MaintenanceWindow
+		if err := w.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics exporters selectable in MetricsSpec.
+const (
+	MetricsNone       = "none"
+	MetricsPrometheus = "prometheus"
+	MetricsOTLP       = "otlp"
+	MetricsStatsD     = "statsd"
+)
+
+// MetricsSpec selects how metrics leave the process. Only the section of the
+// chosen Exporter is validated, so the others may be left unset:
+//
+//	MYAPP_METRICS_EXPORTER=statsd
+//	MYAPP_METRICS_STATSD_ADDR=127.0.0.1:8125
+type MetricsSpec struct {
+	Exporter   string `default:"prometheus" desc:"prometheus, otlp, statsd or none"`
+	Prometheus PrometheusExporterSpec
+	OTLP       OTLPExporterSpec
+	StatsD     StatsDExporterSpec
+}
+
+// PrometheusExporterSpec holds the address and path that serve metrics for
+// scraping.
+type PrometheusExporterSpec struct {
+	Addr string `default:":9090"`
+	Path string `default:"/metrics"`
+}
+
+// OTLPExporterSpec holds the collector that metrics are pushed to over the
+// OpenTelemetry protocol.
+type OTLPExporterSpec struct {
+	Endpoint string            `default:"localhost:4317" desc:"host:port, or a URL for http/protobuf"`
+	Protocol string            `default:"grpc" desc:"grpc or http/protobuf"`
+	Headers  map[string]string `map_delimiter:"=" sensitive:"true" desc:"key=value pairs, often API keys"`
+	Insecure bool              `desc:"connect without TLS"`
+	Interval time.Duration     `default:"60s" desc:"how often metrics are pushed"`
+	Timeout  time.Duration     `default:"10s"`
+}
+
+// StatsDExporterSpec holds the StatsD daemon that metrics are sent to.
+type StatsDExporterSpec struct {
+	Addr    string `default:"127.0.0.1:8125"`
+	Network string `default:"udp" desc:"udp or tcp"`
+	Prefix  string `desc:"prepended to every metric name, e.g. myapp."`
+}
+
+// Validate implements envconfig.Validator.
+func (s *MetricsSpec) Validate() error {
+	switch s.Exporter {
+	case MetricsNone:
+		return nil
+	case MetricsPrometheus:
+		return s.Prometheus.validate()
+	case MetricsOTLP:
+		return s.OTLP.validate()
+	case MetricsStatsD:
+		return s.StatsD.validate()
+	}
+	return fmt.Errorf("unknown metrics exporter %q, expected prometheus, otlp, statsd or none", s.Exporter)
+}
+
+func (s *PrometheusExporterSpec) validate() error {
+	if _, _, err := net.SplitHostPort(s.Addr); err != nil {
+		return fmt.Errorf("invalid Prometheus address %q: %v", s.Addr, err)
+	}
+	if !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("invalid Prometheus path %q", s.Path)
+	}
+	return nil
+}
+
+func (s *OTLPExporterSpec) validate() error {
+	if err := checkOTLPEndpoint(s.Protocol, s.Endpoint); err != nil {
+		return err
+	}
+	if s.Interval <= 0 || s.Timeout <= 0 {
+		return errors.New("OTLP interval and timeout must be positive")
+	}
+	if s.Timeout > s.Interval {
+		return fmt.Errorf("OTLP timeout %v exceeds the export interval %v", s.Timeout, s.Interval)
+	}
+	return nil
+}
+
+func (s *StatsDExporterSpec) validate() error {
+	if s.Network != "udp" && s.Network != "tcp" {
+		return fmt.Errorf("unknown StatsD network %q, expected udp or tcp", s.Network)
+	}
+	return checkHostPort(s.Addr)
+}
+
+// A MetricsExporter is returned by MetricsSpec.NewExporter: a
+// *PrometheusExporter, an *OTLPExporter or a *StatsDClient.
+type MetricsExporter interface {
+	Close() error
+}
+
+// NewExporter returns the chosen exporter, or nil for "none".
+func (s *MetricsSpec) NewExporter(ctx context.Context) (MetricsExporter, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	switch s.Exporter {
+	case MetricsPrometheus:
+		return &PrometheusExporter{Addr: s.Prometheus.Addr, Path: s.Prometheus.Path}, nil
+	case MetricsOTLP:
+		return &OTLPExporter{Options: s.OTLP.Options()}, nil
+	case MetricsStatsD:
+		return s.StatsD.Dial(ctx)
+	}
+	return nil, nil
+}
+
+// PrometheusExporter serves a metrics handler, such as promhttp.Handler(),
+// for scraping.
+type PrometheusExporter struct {
+	Addr string
+	Path string
+
+	mu  sync.Mutex
+	srv *http.Server
+}
+
+// Serve listens on Addr and serves h at Path until Close is called, when it
+// returns http.ErrServerClosed.
+func (e *PrometheusExporter) Serve(h http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle(e.Path, h)
+	e.mu.Lock()
+	e.srv = &http.Server{Addr: e.Addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	srv := e.srv
+	e.mu.Unlock()
+	return srv.ListenAndServe()
+}
+
+// Close stops the server.
+func (e *PrometheusExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.srv == nil {
+		return nil
+	}
+	return e.srv.Close()
+}
+
+// OTLPOptions has the settings of the OTLP metric exporters of
+// go.opentelemetry.io/otel, which this package does not import: pass them to
+// otlpmetricgrpc or otlpmetrichttp with WithEndpoint, WithURLPath,
+// WithHeaders, WithInsecure and WithTimeout, and Interval to
+// metric.WithInterval.
+type OTLPOptions struct {
+	Protocol string
+	Endpoint string // host:port
+	URLPath  string // for http/protobuf, when the endpoint is a URL
+	Headers  map[string]string
+	Insecure bool
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Options returns the exporter options. An http/protobuf endpoint given as a
+// URL is split into its host and path, and is insecure when it is http://.
+func (s *OTLPExporterSpec) Options() OTLPOptions {
+	o := OTLPOptions{
+		Protocol: s.Protocol,
+		Endpoint: s.Endpoint,
+		Headers:  s.Headers,
+		Insecure: s.Insecure,
+		Interval: s.Interval,
+		Timeout:  s.Timeout,
+	}
+	o.Endpoint, o.URLPath, o.Insecure = splitOTLPEndpoint(s.Endpoint, s.Insecure)
+	return o
+}
+
+// checkOTLPEndpoint checks an OTLP endpoint, which is host:port, or for
+// http/protobuf may also be a URL.
+func checkOTLPEndpoint(protocol, endpoint string) error {
+	switch protocol {
+	case "grpc":
+		if err := checkHostPort(endpoint); err != nil {
+			return fmt.Errorf("invalid OTLP endpoint: %v", err)
+		}
+	case "http/protobuf":
+		if strings.Contains(endpoint, "://") {
+			return checkURL("OTLP endpoint", endpoint, false)
+		}
+		if err := checkHostPort(endpoint); err != nil {
+			return fmt.Errorf("invalid OTLP endpoint: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown OTLP protocol %q, expected grpc or http/protobuf", protocol)
+	}
+	return nil
+}
+
+// splitOTLPEndpoint splits an endpoint given as a URL into its host and
+// path. It is insecure when the URL is http://.
+func splitOTLPEndpoint(endpoint string, insecure bool) (hostPort, path string, isInsecure bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || !strings.Contains(endpoint, "://") {
+		return endpoint, "", insecure
+	}
+	return u.Host, u.Path, insecure || u.Scheme == "http"
+}
+
+// OTLPExporter carries the options of an OTLP exporter, which the
+// application creates with the OpenTelemetry SDK.
+type OTLPExporter struct {
+	Options OTLPOptions
+}
+
+// Close implements MetricsExporter. It does nothing.
+func (e *OTLPExporter) Close() error {
+	return nil
+}
+
+// StatsDClient sends metrics in the StatsD line protocol. It is safe for
+// concurrent use. Send errors are dropped, as is usual for StatsD.
+type StatsDClient struct {
+	prefix string
+	mu     sync.Mutex
+	conn   net.Conn
+}
+
+// Dial connects to the StatsD daemon.
+func (s *StatsDExporterSpec) Dial(ctx context.Context) (*StatsDClient, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.Network, s.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDClient{prefix: s.Prefix, conn: conn}, nil
+}
+
+// Count adds n to a counter.
+func (c *StatsDClient) Count(name string, n int64) {
+	c.send(name, strconv.FormatInt(n, 10), "c")
+}
+
+// Gauge sets a gauge.
+func (c *StatsDClient) Gauge(name string, v float64) {
+	c.send(name, strconv.FormatFloat(v, 'f', -1, 64), "g")
+}
+
+// Timing records a duration in milliseconds.
+func (c *StatsDClient) Timing(name string, d time.Duration) {
+	c.send(name, strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64), "ms")
+}
+
+func (c *StatsDClient) send(name, value, kind string) {
+	line := c.prefix + name + ":" + value + "|" + kind + "\n"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.Write([]byte(line))
+}
+
+// Close closes the connection.
+func (c *StatsDClient) Close() error {
+	return c.conn.Close()
+}
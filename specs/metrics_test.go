@@ -0,0 +1,100 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestMetricsSpecStatsD(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer pc.Close()
+
+	var s struct {
+		Metrics MetricsSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_METRICS_EXPORTER", "statsd")
+	os.Setenv("MYAPP_METRICS_STATSD_ADDR", pc.LocalAddr().String())
+	os.Setenv("MYAPP_METRICS_STATSD_PREFIX", "myapp.")
+	// the unused Prometheus section is not validated
+	os.Setenv("MYAPP_METRICS_PROMETHEUS_ADDR", "invalid")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	e, err := s.Metrics.NewExporter(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer e.Close()
+	c, ok := e.(*StatsDClient)
+	if !ok {
+		t.Fatalf("expected a *StatsDClient, got %T", e)
+	}
+	c.Timing("latency", 1500*time.Microsecond)
+
+	buf := make([]byte, 512)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := string(buf[:n]); got != "myapp.latency:1.5|ms\n" {
+		t.Errorf("unexpected line %q", got)
+	}
+
+	os.Setenv("MYAPP_METRICS_EXPORTER", "prometheus")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an invalid Prometheus address, got nil")
+	}
+}
+
+func TestMetricsSpecOTLP(t *testing.T) {
+	s := MetricsSpec{
+		Exporter: MetricsOTLP,
+		OTLP: OTLPExporterSpec{
+			Endpoint: "http://collector:4318/custom/v1/metrics",
+			Protocol: "http/protobuf",
+			Headers:  map[string]string{"api-key": "secret"},
+			Interval: time.Minute,
+			Timeout:  10 * time.Second,
+		},
+	}
+	e, err := s.NewExporter(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	o := e.(*OTLPExporter).Options
+	if o.Endpoint != "collector:4318" || o.URLPath != "/custom/v1/metrics" || !o.Insecure {
+		t.Errorf("unexpected options %+v", o)
+	}
+
+	s.OTLP.Protocol = "grpc"
+	if err := s.Validate(); err == nil {
+		t.Error("expected error for a URL endpoint over grpc, got nil")
+	}
+	s.OTLP.Endpoint, s.OTLP.Timeout = "collector:4317", 2*time.Minute
+	if err := s.Validate(); err == nil {
+		t.Error("expected error for a timeout beyond the interval, got nil")
+	}
+	s = MetricsSpec{Exporter: "graphite"}
+	if err := s.Validate(); err == nil {
+		t.Error("expected error for an unknown exporter, got nil")
+	}
+	s = MetricsSpec{Exporter: MetricsNone}
+	if e, err := s.NewExporter(context.Background()); e != nil || err != nil {
+		t.Errorf("expected no exporter, got %v and %v", e, err)
+	}
+}
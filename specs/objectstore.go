@@ -0,0 +1,146 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Object storage providers accepted by ObjectStoreSpec.
+const (
+	S3        = "s3"
+	GCS       = "gcs"
+	AzureBlob = "azure"
+)
+
+// ObjectStoreSpec holds the settings of an S3, Google Cloud Storage or Azure
+// Blob Storage bucket. Bucket is the container name for Azure, and Endpoint
+// points S3 clients at a compatible service such as MinIO.
+//
+// Credentials are never given inline. CredentialsRef names where to read
+// them, as "env:NAME" or "file:/path", and is empty to use the ambient
+// credentials of the platform, such as an instance role.
+type ObjectStoreSpec struct {
+	Provider       string `required:"true" desc:"s3, gcs or azure"`
+	Bucket         string `required:"true"`
+	Prefix         string `desc:"key prefix for all objects"`
+	Region         string `desc:"S3 region"`
+	Endpoint       string `desc:"S3 compatible endpoint URL"`
+	Account        string `desc:"Azure storage account"`
+	CredentialsRef string `split_words:"true" desc:"env:NAME or file:/path holding the credentials"`
+	PathStyle      bool   `split_words:"true" desc:"use path style S3 URLs"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *ObjectStoreSpec) Validate() error {
+	switch s.Provider {
+	case S3:
+		if s.Region == "" && s.Endpoint == "" {
+			return errors.New("S3 requires a region or an endpoint")
+		}
+		if s.Account != "" {
+			return errors.New("account is only used by Azure")
+		}
+	case GCS:
+		if s.Region != "" || s.Endpoint != "" || s.Account != "" || s.PathStyle {
+			return errors.New("region, endpoint, account and path style are not used by GCS")
+		}
+	case AzureBlob:
+		if s.Account == "" {
+			return errors.New("Azure requires a storage account")
+		}
+		if s.Region != "" || s.PathStyle {
+			return errors.New("region and path style are not used by Azure")
+		}
+	default:
+		return fmt.Errorf("unknown object store provider %q, expected s3, gcs or azure", s.Provider)
+	}
+	if err := checkBucket(s.Provider, s.Bucket); err != nil {
+		return err
+	}
+	if s.Endpoint != "" {
+		if err := checkURL("endpoint", s.Endpoint, false); err != nil {
+			return err
+		}
+	}
+	if s.CredentialsRef != "" {
+		if _, _, err := parseRef(s.CredentialsRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Credentials reads the credentials named by CredentialsRef. It returns nil
+// when CredentialsRef is empty and the ambient credentials apply.
+func (s *ObjectStoreSpec) Credentials() ([]byte, error) {
+	if s.CredentialsRef == "" {
+		return nil, nil
+	}
+	kind, name, err := parseRef(s.CredentialsRef)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "env" {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("specs: credentials variable %s is not set", name)
+		}
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(name)
+}
+
+// URL returns the location of the bucket and prefix as s3://, gs:// or
+// azblob:// URL.
+func (s *ObjectStoreSpec) URL() *url.URL {
+	scheme := map[string]string{S3: "s3", GCS: "gs", AzureBlob: "azblob"}[s.Provider]
+	return &url.URL{Scheme: scheme, Host: s.Bucket, Path: "/" + strings.TrimPrefix(s.Prefix, "/")}
+}
+
+// parseRef splits a credentials reference into its kind and name.
+func parseRef(ref string) (kind, name string, err error) {
+	kind, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" || (kind != "env" && kind != "file") {
+		return "", "", fmt.Errorf("invalid credentials reference %q, expected env:NAME or file:/path", ref)
+	}
+	return kind, name, nil
+}
+
+// checkBucket applies the naming rules of the provider: 3 to 63 lower case
+// letters, digits and hyphens, plus dots for S3 and GCS and underscores for
+// GCS, starting and ending with a letter or digit.
+func checkBucket(provider, bucket string) error {
+	allowed := "-"
+	switch provider {
+	case S3:
+		allowed += "."
+	case GCS:
+		allowed += "._"
+	}
+	invalid := func(why string) error {
+		return fmt.Errorf("invalid %s bucket %q: %s", provider, bucket, why)
+	}
+	if len(bucket) < 3 || len(bucket) > 63 {
+		return invalid("must be 3 to 63 characters")
+	}
+	for _, r := range bucket {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && !strings.ContainsRune(allowed, r) {
+			return invalid(fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	if strings.ContainsAny(bucket[:1]+bucket[len(bucket)-1:], allowed) {
+		return invalid("must start and end with a letter or digit")
+	}
+	if provider == AzureBlob && strings.Contains(bucket, "--") {
+		return invalid("may not contain consecutive hyphens")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestObjectStoreSpec(t *testing.T) {
+	var s struct {
+		Uploads ObjectStoreSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_UPLOADS_PROVIDER", "s3")
+	os.Setenv("MYAPP_UPLOADS_BUCKET", "my-uploads")
+	os.Setenv("MYAPP_UPLOADS_PREFIX", "tenants/")
+	os.Setenv("MYAPP_UPLOADS_ENDPOINT", "http://minio:9000")
+	os.Setenv("MYAPP_UPLOADS_PATH_STYLE", "true")
+	os.Setenv("MYAPP_UPLOADS_CREDENTIALS_REF", "env:MINIO_SECRET")
+	os.Setenv("MINIO_SECRET", "s3cr3t")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	creds, err := s.Uploads.Credentials()
+	if err != nil || string(creds) != "s3cr3t" {
+		t.Errorf("expected credentials %q, got %q, %v", "s3cr3t", creds, err)
+	}
+	if u := s.Uploads.URL().String(); u != "s3://my-uploads/tenants/" {
+		t.Errorf("unexpected URL %s", u)
+	}
+}
+
+func TestObjectStoreSpecValidate(t *testing.T) {
+	valid := []ObjectStoreSpec{
+		{Provider: S3, Bucket: "logs.example.com", Region: "eu-west-1"},
+		{Provider: GCS, Bucket: "my_bucket", CredentialsRef: "file:/etc/gcs/key.json"},
+		{Provider: AzureBlob, Bucket: "uploads", Account: "myaccount"},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error: %v", s, err)
+		}
+	}
+	invalid := []ObjectStoreSpec{
+		{Provider: "minio", Bucket: "uploads"},
+		{Provider: S3, Bucket: "uploads"},
+		{Provider: S3, Bucket: "Uploads", Region: "us-east-1"},
+		{Provider: S3, Bucket: "-uploads", Region: "us-east-1"},
+		{Provider: S3, Bucket: "up", Region: "us-east-1"},
+		{Provider: S3, Bucket: "uploads", Endpoint: "minio:9000"},
+		{Provider: GCS, Bucket: "uploads", PathStyle: true},
+		{Provider: AzureBlob, Bucket: "uploads"},
+		{Provider: AzureBlob, Bucket: "up.loads", Account: "a"},
+		{Provider: GCS, Bucket: "uploads", CredentialsRef: "s3cr3t"},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package specs provides envconfig specifications for the connection settings
+// that services keep repeating, to be used as nested fields of a larger
+// specification so they share its prefix:
+//
+//	type Specification struct {
+//		Auth specs.OIDCSpec // MYAPP_AUTH_ISSUER, MYAPP_AUTH_CLIENT_ID, ...
+//	}
+//
+// Each specification implements envconfig.Validator, so processing fails when
+// its fields are inconsistent.
+package specs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OIDCSpec holds the settings of an OAuth2 or OpenID Connect client. The
+// authorization and token endpoints are discovered from the issuer unless
+// both are set.
+type OIDCSpec struct {
+	Issuer       string   `split_words:"true" required:"true" desc:"OpenID provider issuer URL"`
+	ClientID     string   `split_words:"true" required:"true"`
+	ClientSecret string   `split_words:"true" sensitive:"true"`
+	Scopes       []string `default:"openid" desc:"comma separated scopes to request"`
+	RedirectURL  string   `split_words:"true"`
+	AuthURL      string   `split_words:"true" desc:"overrides the discovered authorization endpoint"`
+	TokenURL     string   `split_words:"true" desc:"overrides the discovered token endpoint"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *OIDCSpec) Validate() error {
+	if err := checkURL("issuer", s.Issuer, true); err != nil {
+		return err
+	}
+	for _, u := range []struct{ name, value string }{
+		{"redirect URL", s.RedirectURL},
+		{"auth URL", s.AuthURL},
+		{"token URL", s.TokenURL},
+	} {
+		if u.value == "" {
+			continue
+		}
+		if err := checkURL(u.name, u.value, false); err != nil {
+			return err
+		}
+	}
+	if (s.AuthURL == "") != (s.TokenURL == "") {
+		return errors.New("auth URL and token URL must be set together")
+	}
+	for _, scope := range s.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\"\\") {
+			return fmt.Errorf("invalid scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// checkURL checks that value is an absolute http(s) URL. An issuer must use
+// https unless it is on the loopback interface, and may not have a query or
+// fragment.
+func checkURL(name, value string, issuer bool) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("%s %q is not an absolute http(s) URL", name, value)
+	}
+	if !issuer {
+		return nil
+	}
+	if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
+		return fmt.Errorf("%s %q must use https", name, value)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%s %q may not have a query or fragment", name, value)
+	}
+	return nil
+}
+
+// OAuth2Endpoint has the fields of oauth2.Endpoint.
+type OAuth2Endpoint struct {
+	AuthURL  string
+	TokenURL string
+}
+
+// OAuth2Config has the fields of oauth2.Config from golang.org/x/oauth2,
+// which this package does not import. Convert it with a struct literal:
+//
+//	c, err := s.Auth.OAuth2Config(ctx)
+//	conf := &oauth2.Config{
+//		ClientID:     c.ClientID,
+//		ClientSecret: c.ClientSecret,
+//		Endpoint:     oauth2.Endpoint{AuthURL: c.Endpoint.AuthURL, TokenURL: c.Endpoint.TokenURL},
+//		RedirectURL:  c.RedirectURL,
+//		Scopes:       c.Scopes,
+//	}
+type OAuth2Config struct {
+	ClientID     string
+	ClientSecret string
+	Endpoint     OAuth2Endpoint
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OAuth2Config returns the client configuration, fetching the provider's
+// discovery document from the issuer when AuthURL and TokenURL are not set.
+func (s *OIDCSpec) OAuth2Config(ctx context.Context) (OAuth2Config, error) {
+	return s.oauth2Config(ctx, http.DefaultClient)
+}
+
+func (s *OIDCSpec) oauth2Config(ctx context.Context, client *http.Client) (OAuth2Config, error) {
+	c := OAuth2Config{
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		Endpoint:     OAuth2Endpoint{AuthURL: s.AuthURL, TokenURL: s.TokenURL},
+		RedirectURL:  s.RedirectURL,
+		Scopes:       append([]string(nil), s.Scopes...),
+	}
+	if c.Endpoint.AuthURL != "" && c.Endpoint.TokenURL != "" {
+		return c, nil
+	}
+	endpoint, err := discover(ctx, client, s.Issuer)
+	if err != nil {
+		return OAuth2Config{}, err
+	}
+	c.Endpoint = endpoint
+	return c, nil
+}
+
+// discover reads the endpoints from the OpenID Connect discovery document of
+// issuer.
+func discover(ctx context.Context, client *http.Client, issuer string) (OAuth2Endpoint, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequest("GET", wellKnown, nil)
+	if err != nil {
+		return OAuth2Endpoint{}, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: %v", issuer, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: %s", issuer, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: %v", issuer, err)
+	}
+
+	var doc struct {
+		Issuer   string `json:"issuer"`
+		AuthURL  string `json:"authorization_endpoint"`
+		TokenURL string `json:"token_endpoint"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: %v", issuer, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: document is for issuer %q", issuer, doc.Issuer)
+	}
+	if doc.AuthURL == "" || doc.TokenURL == "" {
+		return OAuth2Endpoint{}, fmt.Errorf("specs: discovering %s: missing endpoints", issuer)
+	}
+	return OAuth2Endpoint{AuthURL: doc.AuthURL, TokenURL: doc.TokenURL}, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestOIDCSpec(t *testing.T) {
+	var s struct {
+		Auth OIDCSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_AUTH_ISSUER", "https://accounts.example.com")
+	os.Setenv("MYAPP_AUTH_CLIENT_ID", "myapp")
+	os.Setenv("MYAPP_AUTH_CLIENT_SECRET", "secret")
+	os.Setenv("MYAPP_AUTH_SCOPES", "openid,email")
+	os.Setenv("MYAPP_AUTH_AUTH_URL", "https://accounts.example.com/authorize")
+	os.Setenv("MYAPP_AUTH_TOKEN_URL", "https://accounts.example.com/token")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	c, err := s.Auth.OAuth2Config(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.ClientID != "myapp" || c.Endpoint.TokenURL != "https://accounts.example.com/token" || len(c.Scopes) != 2 {
+		t.Errorf("unexpected config %+v", c)
+	}
+
+	os.Unsetenv("MYAPP_AUTH_TOKEN_URL")
+	s.Auth = OIDCSpec{}
+	err = envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true})
+	var verr *envconfig.ValidationError
+	if !errors.As(err, &verr) || verr.FieldName != "Auth" {
+		t.Errorf("expected ValidationError for Auth, got %v", err)
+	}
+}
+
+func TestOIDCSpecValidate(t *testing.T) {
+	for _, s := range []OIDCSpec{
+		{Issuer: "accounts.example.com", ClientID: "x"},
+		{Issuer: "http://accounts.example.com", ClientID: "x"},
+		{Issuer: "https://accounts.example.com?x=1", ClientID: "x"},
+		{Issuer: "https://accounts.example.com", ClientID: "x", RedirectURL: "/callback"},
+		{Issuer: "https://accounts.example.com", ClientID: "x", Scopes: []string{"openid email"}},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+	s := OIDCSpec{Issuer: "http://localhost:8080/realms/dev", ClientID: "x", Scopes: []string{"openid"}}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOIDCSpecDiscovery(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"issuer":%q,"authorization_endpoint":"%[1]s/auth","token_endpoint":"%[1]s/token"}`, srv.URL)
+	}))
+	defer srv.Close()
+
+	s := OIDCSpec{Issuer: srv.URL + "/", ClientID: "x", Scopes: []string{"openid"}}
+	c, err := s.oauth2Config(context.Background(), srv.Client())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if c.Endpoint.AuthURL != srv.URL+"/auth" || c.Endpoint.TokenURL != srv.URL+"/token" {
+		t.Errorf("unexpected endpoint %+v", c.Endpoint)
+	}
+
+	s.Issuer = srv.URL + "/other"
+	if _, err := s.oauth2Config(context.Background(), srv.Client()); err == nil {
+		t.Error("expected error for a missing discovery document, got nil")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ProxySpec holds application specific proxy settings that override the
+// standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables. The standard
+// variables are read without a prefix by ProxyFunc, in upper or lower case
+// with upper case taking precedence, as net/http does.
+//
+//	type Specification struct {
+//		Proxy specs.ProxySpec // MYAPP_PROXY_HTTPS overrides HTTPS_PROXY
+//	}
+//	transport := &http.Transport{Proxy: s.Proxy.ProxyFunc()}
+type ProxySpec struct {
+	HTTP    string `desc:"proxy for http requests, overrides HTTP_PROXY"`
+	HTTPS   string `desc:"proxy for https requests, overrides HTTPS_PROXY"`
+	NoProxy string `split_words:"true" desc:"hosts to reach directly, overrides NO_PROXY"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *ProxySpec) Validate() error {
+	for _, p := range []struct{ name, value string }{{"HTTP", s.HTTP}, {"HTTPS", s.HTTPS}} {
+		if p.value == "" {
+			continue
+		}
+		if _, err := parseProxy(p.value); err != nil {
+			return fmt.Errorf("invalid %s proxy: %v", p.name, err)
+		}
+	}
+	return nil
+}
+
+// ProxyFunc returns a function for http.Transport.Proxy that picks the proxy
+// by the scheme of the request URL and skips it for the hosts in NO_PROXY.
+// Requests to localhost and loopback addresses always go direct.
+func (s *ProxySpec) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return s.proxyFunc(os.Getenv)
+}
+
+func (s *ProxySpec) proxyFunc(getenv func(string) string) func(*http.Request) (*url.URL, error) {
+	env := func(override, name string) string {
+		if override != "" {
+			return override
+		}
+		if v := getenv(name); v != "" {
+			return v
+		}
+		return getenv(strings.ToLower(name))
+	}
+	httpProxy := env(s.HTTP, "HTTP_PROXY")
+	if getenv("REQUEST_METHOD") != "" && s.HTTP == "" {
+		// under CGI, HTTP_PROXY may come from a request's Proxy header
+		httpProxy = ""
+	}
+	httpsProxy := env(s.HTTPS, "HTTPS_PROXY")
+	noProxy := parseNoProxy(env(s.NoProxy, "NO_PROXY"))
+
+	return func(req *http.Request) (*url.URL, error) {
+		var proxy string
+		switch req.URL.Scheme {
+		case "http":
+			proxy = httpProxy
+		case "https":
+			proxy = httpsProxy
+		}
+		if proxy == "" || !noProxy.useProxy(req.URL) {
+			return nil, nil
+		}
+		return parseProxy(proxy)
+	}
+}
+
+// parseProxy parses a proxy URL, assuming http:// when the scheme is absent.
+func parseProxy(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if u, err := url.Parse("http://" + proxy); err == nil && u.Host != "" {
+			return u, nil
+		}
+		return nil, fmt.Errorf("invalid proxy address %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+}
+
+// noProxy holds the parsed entries of NO_PROXY.
+type noProxy struct {
+	all      bool
+	networks []*net.IPNet
+	ips      []net.IP
+	domains  []hostMatch
+}
+
+type hostMatch struct {
+	domain     string
+	port       string
+	subdomains bool // entry started with "." or "*.", so the domain itself is excluded
+}
+
+// parseNoProxy parses a comma separated list of host names, domain suffixes
+// (".example.com" or "*.example.com"), IP addresses and CIDR ranges, each
+// optionally with a port. A bare name such as "example.com" also matches its
+// subdomains, and "*" matches every host.
+func parseNoProxy(list string) noProxy {
+	var np noProxy
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			np.all = true
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			np.networks = append(np.networks, network)
+			continue
+		}
+
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+			if port == "" {
+				np.ips = append(np.ips, ip)
+			} else {
+				np.domains = append(np.domains, hostMatch{domain: ip.String(), port: port})
+			}
+			continue
+		}
+		m := hostMatch{port: port}
+		switch {
+		case strings.HasPrefix(host, "*."):
+			m.domain, m.subdomains = host[1:], true
+		case strings.HasPrefix(host, "."):
+			m.domain, m.subdomains = host, true
+		default:
+			m.domain = host
+		}
+		np.domains = append(np.domains, m)
+	}
+	return np
+}
+
+// useProxy reports whether a request to u should go through the proxy.
+func (np noProxy) useProxy(u *url.URL) bool {
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	if host == "localhost" {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() {
+			return false
+		}
+		for _, n := range np.networks {
+			if n.Contains(ip) {
+				return false
+			}
+		}
+		for _, other := range np.ips {
+			if ip.Equal(other) {
+				return false
+			}
+		}
+		host = ip.String()
+	}
+	if np.all {
+		return false
+	}
+	for _, m := range np.domains {
+		if m.port != "" && m.port != port {
+			continue
+		}
+		if m.subdomains {
+			if strings.HasSuffix(host, m.domain) {
+				return false
+			}
+		} else if host == m.domain || strings.HasSuffix(host, "."+m.domain) {
+			return false
+		}
+	}
+	return true
+}
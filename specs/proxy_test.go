@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestProxySpec(t *testing.T) {
+	env := map[string]string{
+		"http_proxy":  "proxy.internal:3128",
+		"HTTPS_PROXY": "http://secure-proxy.internal:3128",
+		"no_proxy":    "*.svc.cluster.local,example.com,10.0.0.0/8,192.168.1.5,api.internal:8443",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	tests := map[string]string{
+		"http://example.org/":                       "http://proxy.internal:3128",
+		"https://example.org/":                      "http://secure-proxy.internal:3128",
+		"http://example.com/":                       "",
+		"https://www.example.com/":                  "",
+		"http://notexample.com/":                    "http://proxy.internal:3128",
+		"http://db.svc.cluster.local/":              "",
+		"http://svc.cluster.local/":                 "http://proxy.internal:3128",
+		"http://10.1.2.3/":                          "",
+		"http://192.168.1.5:8080/":                  "",
+		"http://192.168.1.6/":                       "http://proxy.internal:3128",
+		"https://api.internal:8443/":                "",
+		"https://api.internal/":                     "http://secure-proxy.internal:3128",
+		"http://localhost:8080/":                    "",
+		"http://127.0.0.1/":                         "",
+		"ftp://example.org/":                        "",
+		"http://[::1]:9000/":                        "",
+		"https://EXAMPLE.com/":                      "",
+		"http://deep.db.svc.cluster.local:5432/x/y": "",
+	}
+	var s ProxySpec
+	proxy := s.proxyFunc(getenv)
+	for target, want := range tests {
+		req, _ := http.NewRequest("GET", target, nil)
+		u, err := proxy(req)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", target, err)
+			continue
+		}
+		got := ""
+		if u != nil {
+			got = u.String()
+		}
+		if got != want {
+			t.Errorf("%s: expected proxy %q, got %q", target, want, got)
+		}
+	}
+
+	s = ProxySpec{HTTP: "http://override:8080", NoProxy: "*"}
+	req, _ := http.NewRequest("GET", "http://example.org/", nil)
+	if u, _ := s.proxyFunc(getenv)(req); u != nil {
+		t.Errorf("expected NO_PROXY=* override to disable the proxy, got %v", u)
+	}
+	s.NoProxy = "example.com"
+	if u, _ := s.proxyFunc(getenv)(req); u == nil || u.Host != "override:8080" {
+		t.Errorf("expected the override proxy, got %v", u)
+	}
+}
+
+func TestProxySpecProcess(t *testing.T) {
+	var s struct {
+		Proxy ProxySpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_PROXY_HTTPS", "ftp://proxy")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an ftp proxy, got nil")
+	}
+	os.Setenv("MYAPP_PROXY_HTTPS", "socks5://proxy:1080")
+	os.Setenv("MYAPP_PROXY_NO_PROXY", "localhost")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Proxy.NoProxy != "localhost" {
+		t.Errorf("expected %q, got %q", "localhost", s.Proxy.NoProxy)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a number of events per period, written as "100/s", "1000/m",
+// "5000/h" or with a duration as the period, "10/30s".
+type Rate struct {
+	Events int
+	Per    time.Duration
+}
+
+// Decode implements envconfig.Decoder.
+func (r *Rate) Decode(value string) error {
+	n, per, ok := strings.Cut(strings.TrimSpace(value), "/")
+	events, err := strconv.Atoi(strings.TrimSpace(n))
+	if !ok || err != nil || events <= 0 {
+		return fmt.Errorf("invalid rate %q, expected events/period such as 100/s", value)
+	}
+	per = strings.TrimSpace(per)
+	d, ok := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}[per]
+	if !ok {
+		if d, err = time.ParseDuration(per); err != nil || d <= 0 {
+			return fmt.Errorf("invalid rate period %q", per)
+		}
+	}
+	r.Events, r.Per = events, d
+	return nil
+}
+
+// String formats the rate as it is written.
+func (r Rate) String() string {
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Events)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Events)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Events)
+	}
+	return fmt.Sprintf("%d/%v", r.Events, r.Per)
+}
+
+// PerSecond returns the rate in events per second, the refill rate of a
+// token bucket such as rate.Limit of golang.org/x/time/rate.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Events) / r.Per.Seconds()
+}
+
+// Burst returns the size of the token bucket, which is the events allowed in
+// one period.
+func (r Rate) Burst() int {
+	return r.Events
+}
+
+// RateLimitSpec holds request rate limits keyed by route, client class or
+// any other name the application chooses. The "default" key applies to keys
+// without a limit of their own.
+//
+//	MYAPP_LIMITS=default:100/s,admin:1000/s,export:10/m
+//	limiter := rate.NewLimiter(rate.Limit(r.PerSecond()), r.Burst())
+type RateLimitSpec struct {
+	Limits map[string]Rate `default:"default:100/s" desc:"key:events/period pairs"`
+}
+
+// DefaultRateKey is the key of the limit applied to keys without their own.
+const DefaultRateKey = "default"
+
+// Validate implements envconfig.Validator.
+func (s *RateLimitSpec) Validate() error {
+	if len(s.Limits) == 0 {
+		return errors.New("no rate limits")
+	}
+	for key, r := range s.Limits {
+		if key == "" {
+			return errors.New("rate limit with an empty key")
+		}
+		if r.Events <= 0 || r.Per <= 0 {
+			return fmt.Errorf("invalid rate limit %q for %s", r, key)
+		}
+	}
+	return nil
+}
+
+// Limit returns the limit for key, or the default limit, and whether there
+// was one.
+func (s *RateLimitSpec) Limit(key string) (Rate, bool) {
+	if r, ok := s.Limits[key]; ok {
+		return r, true
+	}
+	r, ok := s.Limits[DefaultRateKey]
+	return r, ok
+}
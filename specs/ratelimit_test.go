@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestRateLimitSpec(t *testing.T) {
+	var s struct {
+		RateLimit RateLimitSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_RATELIMIT_LIMITS", "default:100/s,admin:1000/s,export:10/m,burst:5/30s")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	for key, want := range map[string]string{
+		"admin":   "1000/s",
+		"export":  "10/m",
+		"burst":   "5/30s",
+		"unknown": "100/s",
+	} {
+		r, ok := s.RateLimit.Limit(key)
+		if !ok || r.String() != want {
+			t.Errorf("%s: expected %s, got %s", key, want, r)
+		}
+	}
+	if r, _ := s.RateLimit.Limit("export"); r.PerSecond() != 10.0/60 || r.Burst() != 10 {
+		t.Errorf("unexpected token bucket %v and %d", r.PerSecond(), r.Burst())
+	}
+
+	os.Setenv("MYAPP_RATELIMIT_LIMITS", "default:fast")
+	if err := envconfig.Process("myapp", &s); err == nil {
+		t.Error("expected error for an invalid rate, got nil")
+	}
+}
+
+func TestRateDecode(t *testing.T) {
+	var r Rate
+	if err := r.Decode(" 3 / 2h "); err != nil || r.Events != 3 || r.Per != 2*time.Hour {
+		t.Errorf("unexpected rate %v, %v", r, err)
+	}
+	for _, value := range []string{"", "100", "0/s", "-1/s", "1/d", "1/-1s", "x/s"} {
+		if err := r.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Region is an entry of a region catalog. Regions of one partition, such
+// as AWS China or GovCloud, are isolated from those of other partitions.
+// Nearby lists the regions to prefer for failover; when it is empty, the
+// regions of the same partition and geography (the part of the code before
+// the first "-", e.g. "eu") are used.
+type Region struct {
+	Code      string
+	Partition string
+	Nearby    []string
+}
+
+// A RegionCatalog lists the regions a RegionSpec may name, by code.
+type RegionCatalog map[string]Region
+
+var (
+	regionCatalogsMu sync.RWMutex
+	regionCatalogs   = map[string]RegionCatalog{
+		"aws": newRegionCatalog(map[string][]string{
+			"aws": {
+				"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+				"ca-central-1", "sa-east-1",
+				"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-central-2",
+				"eu-north-1", "eu-south-1", "eu-south-2",
+				"ap-northeast-1", "ap-northeast-2", "ap-northeast-3", "ap-southeast-1",
+				"ap-southeast-2", "ap-southeast-3", "ap-south-1", "ap-south-2", "ap-east-1",
+				"me-south-1", "me-central-1", "af-south-1", "il-central-1",
+			},
+			"aws-cn":     {"cn-north-1", "cn-northwest-1"},
+			"aws-us-gov": {"us-gov-east-1", "us-gov-west-1"},
+		}),
+		"gcp": newRegionCatalog(map[string][]string{
+			"gcp": {
+				"us-central1", "us-east1", "us-east4", "us-east5", "us-south1",
+				"us-west1", "us-west2", "us-west3", "us-west4",
+				"northamerica-northeast1", "northamerica-northeast2", "southamerica-east1",
+				"europe-west1", "europe-west2", "europe-west3", "europe-west4",
+				"europe-west6", "europe-west8", "europe-west9", "europe-north1",
+				"europe-central2", "europe-southwest1",
+				"asia-east1", "asia-east2", "asia-northeast1", "asia-northeast2",
+				"asia-northeast3", "asia-south1", "asia-south2", "asia-southeast1",
+				"asia-southeast2", "australia-southeast1", "australia-southeast2",
+				"me-west1", "me-central1", "africa-south1",
+			},
+		}),
+	}
+)
+
+func newRegionCatalog(partitions map[string][]string) RegionCatalog {
+	c := make(RegionCatalog)
+	for partition, codes := range partitions {
+		for _, code := range codes {
+			c[code] = Region{Code: code, Partition: partition}
+		}
+	}
+	return c
+}
+
+// RegisterRegionCatalog makes c available to RegionSpec as name, replacing
+// any catalog of that name. The built-in catalogs are "aws" and "gcp".
+func RegisterRegionCatalog(name string, c RegionCatalog) {
+	regionCatalogsMu.Lock()
+	defer regionCatalogsMu.Unlock()
+	regionCatalogs[name] = c
+}
+
+func lookupRegionCatalog(name string) (RegionCatalog, bool) {
+	regionCatalogsMu.RLock()
+	defer regionCatalogsMu.RUnlock()
+	c, ok := regionCatalogs[name]
+	return c, ok
+}
+
+// nearby returns the failover candidates for code, nearest first.
+func (c RegionCatalog) nearby(code string) []string {
+	r := c[code]
+	if len(r.Nearby) > 0 {
+		return append([]string(nil), r.Nearby...)
+	}
+	geo := strings.SplitN(code, "-", 2)[0]
+	var codes []string
+	for other, o := range c {
+		if other != code && o.Partition == r.Partition && strings.SplitN(other, "-", 2)[0] == geo {
+			codes = append(codes, other)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// RegionSpec holds the region a service runs in, checked against a catalog
+// of known regions, and the regions it may fail over to. Failover defaults
+// to the nearby regions of the catalog.
+//
+//	MYAPP_REGION_REGION=eu-west-1
+//	MYAPP_REGION_FAILOVER=eu-central-1,eu-west-2
+type RegionSpec struct {
+	Region   string   `required:"true"`
+	Catalog  string   `default:"aws" desc:"aws, gcp or a registered catalog"`
+	Failover []string `desc:"regions to fail over to, defaults to the nearby regions"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *RegionSpec) Validate() error {
+	c, ok := lookupRegionCatalog(s.Catalog)
+	if !ok {
+		return fmt.Errorf("unknown region catalog %q", s.Catalog)
+	}
+	if s.Region == "" {
+		return errors.New("no region")
+	}
+	r, ok := c[s.Region]
+	if !ok {
+		return fmt.Errorf("unknown %s region %q", s.Catalog, s.Region)
+	}
+	for _, code := range s.Failover {
+		f, ok := c[code]
+		switch {
+		case !ok:
+			return fmt.Errorf("unknown %s failover region %q", s.Catalog, code)
+		case code == s.Region:
+			return fmt.Errorf("region %s cannot fail over to itself", code)
+		case f.Partition != r.Partition:
+			return fmt.Errorf("failover region %s is in partition %s, not %s", code, f.Partition, r.Partition)
+		}
+	}
+	return nil
+}
+
+// Partition returns the partition of the region, or "" if it is unknown.
+func (s *RegionSpec) Partition() string {
+	c, _ := lookupRegionCatalog(s.Catalog)
+	return c[s.Region].Partition
+}
+
+// Nearby returns the regions of the catalog near the region.
+func (s *RegionSpec) Nearby() []string {
+	c, _ := lookupRegionCatalog(s.Catalog)
+	if _, ok := c[s.Region]; !ok {
+		return nil
+	}
+	return c.nearby(s.Region)
+}
+
+// FailoverRegions returns Failover, or the nearby regions when it is empty.
+func (s *RegionSpec) FailoverRegions() []string {
+	if len(s.Failover) > 0 {
+		return append([]string(nil), s.Failover...)
+	}
+	return s.Nearby()
+}
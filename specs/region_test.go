@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestRegionSpec(t *testing.T) {
+	var s struct {
+		Placement RegionSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_PLACEMENT_REGION", "cn-north-1")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if p := s.Placement.Partition(); p != "aws-cn" {
+		t.Errorf("expected partition aws-cn, got %q", p)
+	}
+	if got := s.Placement.FailoverRegions(); !reflect.DeepEqual(got, []string{"cn-northwest-1"}) {
+		t.Errorf("unexpected failover regions %q", got)
+	}
+
+	os.Setenv("MYAPP_PLACEMENT_FAILOVER", "us-east-1")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for a failover region in another partition, got nil")
+	}
+	os.Setenv("MYAPP_PLACEMENT_REGION", "mars-north-1")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an unknown region, got nil")
+	}
+}
+
+func TestRegisterRegionCatalog(t *testing.T) {
+	RegisterRegionCatalog("onprem", RegionCatalog{
+		"fra": {Code: "fra", Partition: "dc", Nearby: []string{"ams", "lon"}},
+		"ams": {Code: "ams", Partition: "dc"},
+		"lon": {Code: "lon", Partition: "dc"},
+	})
+	s := RegionSpec{Region: "fra", Catalog: "onprem", Failover: []string{"lon"}}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := s.Nearby(); !reflect.DeepEqual(got, []string{"ams", "lon"}) {
+		t.Errorf("unexpected nearby regions %q", got)
+	}
+	if got := s.FailoverRegions(); !reflect.DeepEqual(got, []string{"lon"}) {
+		t.Errorf("unexpected failover regions %q", got)
+	}
+	s = RegionSpec{Region: "europe-west1", Catalog: "gcp"}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n := s.Nearby(); len(n) == 0 || n[0] != "europe-central2" {
+		t.Errorf("unexpected nearby regions %q", n)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ResilienceSpec holds the timeout, circuit breaker and hedging settings of
+// calls to a dependency.
+//
+// The breaker opens when at least BreakerMinRequests calls were made within
+// BreakerWindow and BreakerFailureRatio of them failed. After BreakerCooldown
+// it lets BreakerProbes calls through to decide whether to close again.
+// Hedging, when HedgeDelay is set, sends up to HedgeMax extra copies of a call
+// that has not answered after HedgeDelay; leave it off for calls that are not
+// idempotent.
+type ResilienceSpec struct {
+	Timeout time.Duration `default:"5s" desc:"deadline of each call"`
+
+	BreakerFailureRatio envconfig.Percent `split_words:"true" default:"50%"`
+	BreakerMinRequests  int               `split_words:"true" default:"20"`
+	BreakerWindow       time.Duration     `split_words:"true" default:"10s"`
+	BreakerCooldown     time.Duration     `split_words:"true" default:"30s"`
+	BreakerProbes       int               `split_words:"true" default:"1"`
+
+	HedgeDelay time.Duration `split_words:"true" desc:"0 disables hedging"`
+	HedgeMax   int           `split_words:"true" default:"1"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *ResilienceSpec) Validate() error {
+	if s.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if s.BreakerFailureRatio <= 0 || s.BreakerFailureRatio > 1 {
+		return fmt.Errorf("breaker failure ratio %v out of range (0%%, 100%%]", s.BreakerFailureRatio)
+	}
+	if s.BreakerMinRequests < 1 || s.BreakerProbes < 1 {
+		return errors.New("breaker minimum requests and probes must be at least 1")
+	}
+	if s.BreakerWindow <= 0 || s.BreakerCooldown <= 0 {
+		return errors.New("breaker window and cooldown must be positive")
+	}
+	if s.BreakerCooldown < s.Timeout {
+		return fmt.Errorf("breaker cooldown %v is shorter than the timeout %v", s.BreakerCooldown, s.Timeout)
+	}
+	if s.HedgeDelay < 0 || s.HedgeMax < 0 {
+		return errors.New("negative hedging setting")
+	}
+	if s.HedgeDelay > 0 {
+		if s.HedgeMax < 1 {
+			return errors.New("hedging is enabled but hedge max is 0")
+		}
+		if s.HedgeDelay >= s.Timeout {
+			return fmt.Errorf("hedge delay %v is not shorter than the timeout %v", s.HedgeDelay, s.Timeout)
+		}
+	}
+	return nil
+}
+
+// Hedging reports whether hedged calls are enabled.
+func (s *ResilienceSpec) Hedging() bool {
+	return s.HedgeDelay > 0 && s.HedgeMax > 0
+}
+
+// Context returns a context for one call that expires after Timeout, or at
+// the deadline of parent if that is sooner.
+func (s *ResilienceSpec) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, s.Timeout)
+}
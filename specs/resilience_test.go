@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestResilienceSpec(t *testing.T) {
+	var s struct {
+		Payments ResilienceSpec
+	}
+	os.Clearenv()
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Payments.Timeout != 5*time.Second || s.Payments.BreakerFailureRatio != 0.5 || s.Payments.Hedging() {
+		t.Errorf("unexpected defaults %+v", s.Payments)
+	}
+
+	os.Setenv("MYAPP_PAYMENTS_HEDGE_DELAY", "200ms")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !s.Payments.Hedging() {
+		t.Error("expected hedging to be enabled")
+	}
+
+	s.Payments = ResilienceSpec{}
+	os.Setenv("MYAPP_PAYMENTS_HEDGE_DELAY", "10s")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for a hedge delay beyond the timeout, got nil")
+	}
+}
+
+func TestResilienceSpecValidate(t *testing.T) {
+	valid := ResilienceSpec{
+		Timeout:             time.Second,
+		BreakerFailureRatio: 0.5,
+		BreakerMinRequests:  10,
+		BreakerWindow:       time.Second,
+		BreakerCooldown:     time.Second,
+		BreakerProbes:       1,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, change := range map[string]func(*ResilienceSpec){
+		"timeout":  func(s *ResilienceSpec) { s.Timeout = 0 },
+		"ratio":    func(s *ResilienceSpec) { s.BreakerFailureRatio = 1.5 },
+		"requests": func(s *ResilienceSpec) { s.BreakerMinRequests = 0 },
+		"window":   func(s *ResilienceSpec) { s.BreakerWindow = 0 },
+		"cooldown": func(s *ResilienceSpec) { s.BreakerCooldown = time.Millisecond },
+		"hedgemax": func(s *ResilienceSpec) { s.HedgeDelay, s.HedgeMax = time.Millisecond, 0 },
+	} {
+		s := valid
+		change(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
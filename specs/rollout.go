@@ -0,0 +1,137 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// RolloutSpec holds the deployment slot of an instance, e.g. blue or green,
+// and the share of traffic routed to its canary. While a canary takes part
+// but not all of the traffic, clients are pinned to one side by the
+// StickyCookie so they do not flap between versions.
+//
+//	MYAPP_ROLLOUT_SLOT=green
+//	MYAPP_ROLLOUT_CANARY_PERCENT=5%
+//	MYAPP_ROLLOUT_STICKY_COOKIE=canary
+type RolloutSpec struct {
+	Slot          string            `default:"blue" desc:"deployment slot, e.g. blue or green"`
+	CanaryPercent envconfig.Percent `split_words:"true" desc:"share of traffic routed to the canary, e.g. 5%"`
+	StickyCookie  string            `split_words:"true" desc:"cookie pinning a client to the canary or the stable version"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *RolloutSpec) Validate() error {
+	if s.Slot == "" || strings.IndexFunc(s.Slot, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-')
+	}) >= 0 {
+		return fmt.Errorf("invalid rollout slot %q: expected lowercase letters, digits and -", s.Slot)
+	}
+	if s.CanaryPercent < 0 || s.CanaryPercent > 1 {
+		return fmt.Errorf("canary percent %v out of range", s.CanaryPercent)
+	}
+	if s.StickyCookie != "" && strings.IndexFunc(s.StickyCookie, func(r rune) bool {
+		return r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r)
+	}) >= 0 {
+		return fmt.Errorf("invalid sticky cookie name %q", s.StickyCookie)
+	}
+	if s.StickyCookie == "" && s.CanaryPercent > 0 && s.CanaryPercent < 1 {
+		return fmt.Errorf("canary percent %v requires a sticky cookie", s.CanaryPercent)
+	}
+	return nil
+}
+
+// InCanary reports whether the client identified by key, such as the value
+// of the sticky cookie or a user ID, is routed to the canary. A key stays on
+// the canary as the percentage grows.
+func (s *RolloutSpec) InCanary(key string) bool {
+	if s.CanaryPercent <= 0 {
+		return false
+	}
+	if s.CanaryPercent >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) < float64(s.CanaryPercent)*10000
+}
+
+// A Rollout holds a RolloutSpec that is replaced on Reload, so the canary
+// percentage can be adjusted without a restart. It is safe for concurrent
+// use.
+type Rollout struct {
+	mu   sync.RWMutex
+	spec RolloutSpec
+}
+
+// NewRollout returns a Rollout holding s.
+func NewRollout(s RolloutSpec) *Rollout {
+	return &Rollout{spec: s}
+}
+
+// Spec returns the current RolloutSpec.
+func (r *Rollout) Spec() RolloutSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.spec
+}
+
+// Reload processes a RolloutSpec under prefix, e.g. "myapp_rollout", and
+// makes it current. If it does not process or validate, the current
+// RolloutSpec is kept and the error returned. Changing the slot is an error,
+// since an instance cannot move between slots while it runs.
+func (r *Rollout) Reload(prefix string, options envconfig.Options) (changed bool, err error) {
+	var next RolloutSpec
+	options.Validate = true
+	if err := envconfig.ProcessWithOptions(prefix, &next, options); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if next.Slot != r.spec.Slot {
+		return false, fmt.Errorf("rollout slot changed from %s to %s", r.spec.Slot, next.Slot)
+	}
+	changed = next != r.spec
+	r.spec = next
+	return changed, nil
+}
+
+// Watch calls Reload every interval, if it is positive, and whenever the
+// process receives SIGHUP (on platforms that have it), until ctx is
+// canceled. Errors are passed to onError, when it is not nil. Reloading is
+// useful with a Lookuper whose values change, such as one that reads a
+// mounted ConfigMap.
+func (r *Rollout) Watch(ctx context.Context, prefix string, options envconfig.Options, interval time.Duration, onError func(error)) error {
+	hup := make(chan os.Signal, 1)
+	notifyHUP(hup)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		case <-hup:
+		}
+		if _, err := r.Reload(prefix, options); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
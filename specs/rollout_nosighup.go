@@ -0,0 +1,13 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build js
+// +build js
+
+package specs
+
+import "os"
+
+// notifyHUP does nothing where there is no SIGHUP.
+func notifyHUP(c chan<- os.Signal) {}
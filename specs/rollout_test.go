@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestRolloutSpec(t *testing.T) {
+	var s struct {
+		Rollout RolloutSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_ROLLOUT_SLOT", "green")
+	os.Setenv("MYAPP_ROLLOUT_CANARY_PERCENT", "10%")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for a canary without a sticky cookie, got nil")
+	}
+	os.Setenv("MYAPP_ROLLOUT_STICKY_COOKIE", "canary")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	n := 0
+	for i := 0; i < 10000; i++ {
+		if s.Rollout.InCanary(strconv.Itoa(i)) {
+			n++
+		}
+	}
+	if n < 800 || n > 1200 {
+		t.Errorf("expected about 1000 keys in the canary, got %d", n)
+	}
+
+	for _, bad := range []RolloutSpec{
+		{Slot: "Blue"},
+		{Slot: "blue", StickyCookie: "a;b"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", bad)
+		}
+	}
+}
+
+func TestRolloutReload(t *testing.T) {
+	var mu sync.Mutex
+	env := envconfig.MapLookuper{"APP_SLOT": "blue"}
+	options := envconfig.Options{Lookuper: envconfig.LookuperFunc(func(key string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		v, ok := env[key]
+		return v, ok
+	})}
+	set := func(key, value string) {
+		mu.Lock()
+		env[key] = value
+		mu.Unlock()
+	}
+
+	r := NewRollout(RolloutSpec{Slot: "blue"})
+	set("APP_CANARY_PERCENT", "100%")
+	if changed, err := r.Reload("app", options); err != nil || !changed {
+		t.Fatalf("expected a change, got %v, %v", changed, err)
+	}
+	if p := r.Spec().CanaryPercent; p != 1 {
+		t.Errorf("expected 100%%, got %v", p)
+	}
+
+	// invalid settings keep the current ones
+	set("APP_CANARY_PERCENT", "20%")
+	if _, err := r.Reload("app", options); err == nil {
+		t.Error("expected error for a canary without a sticky cookie, got nil")
+	}
+	set("APP_SLOT", "green")
+	set("APP_CANARY_PERCENT", "0")
+	if _, err := r.Reload("app", options); err == nil {
+		t.Error("expected error for a changed slot, got nil")
+	}
+	if p := r.Spec().CanaryPercent; p != 1 {
+		t.Errorf("expected 100%%, got %v", p)
+	}
+
+	set("APP_SLOT", "blue")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Watch(ctx, "app", options, time.Millisecond, nil) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Spec().CanaryPercent != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if p := r.Spec().CanaryPercent; p != 0 {
+		t.Errorf("expected 0%%, got %v", p)
+	}
+}
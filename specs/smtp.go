@@ -0,0 +1,225 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP transport security modes accepted by SMTPSpec.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPImplicit = "implicit"
+	SMTPNone     = "none"
+)
+
+// SMTP authentication mechanisms accepted by SMTPSpec.
+const (
+	SMTPAuthNone    = "none"
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+)
+
+// SMTPSpec holds the settings for delivering email over SMTP. Security is
+// "starttls" to upgrade a plain connection (usually port 587), "implicit" for
+// TLS from the start (usually port 465), or "none".
+type SMTPSpec struct {
+	Host     string `required:"true"`
+	Port     int    `default:"587"`
+	Security string `default:"starttls" desc:"starttls, implicit or none"`
+	Auth     string `default:"plain" desc:"none, plain, login or cram-md5"`
+	Username string
+	Password string        `sensitive:"true"`
+	From     string        `required:"true" desc:"sender address, e.g. Alerts <alerts@example.com>"`
+	Timeout  time.Duration `default:"10s"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *SMTPSpec) Validate() error {
+	if s.Host == "" {
+		return errors.New("no SMTP host")
+	}
+	if s.Port <= 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid SMTP port %d", s.Port)
+	}
+	switch s.Security {
+	case SMTPStartTLS, SMTPImplicit, SMTPNone:
+	default:
+		return fmt.Errorf("unknown SMTP security %q, expected starttls, implicit or none", s.Security)
+	}
+	switch s.Auth {
+	case SMTPAuthNone:
+		if s.Username != "" || s.Password != "" {
+			return errors.New("SMTP credentials are given but auth is none")
+		}
+	case SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5:
+		if s.Username == "" || s.Password == "" {
+			return fmt.Errorf("SMTP auth %s requires a username and password", s.Auth)
+		}
+		if s.Auth != SMTPAuthCRAMMD5 && s.Security == SMTPNone {
+			return fmt.Errorf("SMTP auth %s would send the password in the clear", s.Auth)
+		}
+	default:
+		return fmt.Errorf("unknown SMTP auth %q, expected none, plain, login or cram-md5", s.Auth)
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %v", s.From, err)
+	}
+	if s.Timeout < 0 {
+		return errors.New("negative SMTP timeout")
+	}
+	return nil
+}
+
+// Addr returns the host and port to connect to.
+func (s *SMTPSpec) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Dial connects to the server, negotiates TLS and authenticates.
+func (s *SMTPSpec) Dial(ctx context.Context) (*smtp.Client, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr())
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: s.Host}
+	if s.Security == SMTPImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := s.negotiate(c, tlsConfig); err != nil {
+		c.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (s *SMTPSpec) negotiate(c *smtp.Client, tlsConfig *tls.Config) error {
+	if s.Security == SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("specs: SMTP server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	var auth smtp.Auth
+	switch s.Auth {
+	case SMTPAuthPlain:
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	case SMTPAuthLogin:
+		auth = &loginAuth{username: s.Username, password: s.Password, host: s.Host}
+	case SMTPAuthCRAMMD5:
+		auth = smtp.CRAMMD5Auth(s.Username, s.Password)
+	}
+	if auth == nil {
+		return nil
+	}
+	if ok, _ := c.Extension("AUTH"); !ok {
+		return errors.New("specs: SMTP server does not support AUTH")
+	}
+	return c.Auth(auth)
+}
+
+// SendTest delivers a short test message to the given address, to check the
+// settings at startup or from an admin endpoint.
+func (s *SMTPSpec) SendTest(ctx context.Context, to string) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return err
+	}
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("specs: invalid recipient %q: %v", to, err)
+	}
+
+	c, err := s.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(rcpt.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	msg := strings.Join([]string{
+		"From: " + from.String(),
+		"To: " + rcpt.String(),
+		"Subject: SMTP test",
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"",
+		"This is a test message sent to check the SMTP settings.",
+		"",
+	}, "\r\n")
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp lacks. Like
+// smtp.PlainAuth it refuses to send the password without TLS other than to
+// localhost.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && a.host != "localhost" && a.host != "127.0.0.1" && a.host != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
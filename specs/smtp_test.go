@@ -0,0 +1,120 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// fakeSMTP accepts one connection and records the message it receives.
+func fakeSMTP(t *testing.T) (addr string, msg chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	msg = make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var data []string
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if inData {
+				if line == "." {
+					inData = false
+					msg <- strings.Join(data, "\n")
+					reply("250 OK")
+				} else {
+					data = append(data, line)
+				}
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				reply("250 OK")
+			case "DATA":
+				inData = true
+				reply("354 go ahead")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unknown")
+			}
+		}
+	}()
+	return l.Addr().String(), msg
+}
+
+func TestSMTPSpecSendTest(t *testing.T) {
+	addr, msg := fakeSMTP(t)
+	host, port, _ := net.SplitHostPort(addr)
+
+	var s struct {
+		Mail SMTPSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_MAIL_HOST", host)
+	os.Setenv("MYAPP_MAIL_PORT", port)
+	os.Setenv("MYAPP_MAIL_SECURITY", "none")
+	os.Setenv("MYAPP_MAIL_AUTH", "none")
+	os.Setenv("MYAPP_MAIL_FROM", "Alerts <alerts@example.com>")
+	if err := envconfig.Process("myapp", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got, _ := strconv.Atoi(port); s.Mail.Port != got {
+		t.Errorf("expected port %d, got %d", got, s.Mail.Port)
+	}
+	if err := s.Mail.SendTest(context.Background(), "ops@example.com"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if m := <-msg; !strings.Contains(m, "To: <ops@example.com>") || !strings.Contains(m, "Subject: SMTP test") {
+		t.Errorf("unexpected message:\n%s", m)
+	}
+}
+
+func TestSMTPSpecValidate(t *testing.T) {
+	base := SMTPSpec{Host: "smtp.example.com", Port: 587, Security: SMTPStartTLS, Auth: SMTPAuthPlain, Username: "u", Password: "p", From: "alerts@example.com"}
+	if err := base.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, change := range []func(*SMTPSpec){
+		func(s *SMTPSpec) { s.Port = 0 },
+		func(s *SMTPSpec) { s.Security = "ssl" },
+		func(s *SMTPSpec) { s.Auth = "xoauth2" },
+		func(s *SMTPSpec) { s.Password = "" },
+		func(s *SMTPSpec) { s.Auth = SMTPAuthNone },
+		func(s *SMTPSpec) { s.Security = SMTPNone },
+		func(s *SMTPSpec) { s.From = "alerts" },
+		func(s *SMTPSpec) { s.From = "alerts@example.com, ops@example.com" },
+	} {
+		s := base
+		change(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", s)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSSpec holds client TLS settings. The CA file replaces the system roots,
+// and the certificate and key files, which must be given together, enable
+// client certificate authentication.
+type TLSSpec struct {
+	Enabled            bool
+	CAFile             string `split_words:"true" desc:"PEM file of trusted CA certificates"`
+	CertFile           string `split_words:"true" desc:"PEM file of the client certificate"`
+	KeyFile            string `split_words:"true" desc:"PEM file of the client key"`
+	ServerName         string `split_words:"true"`
+	InsecureSkipVerify bool   `split_words:"true"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *TLSSpec) Validate() error {
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return errors.New("TLS cert file and key file must be set together")
+	}
+	if !s.Enabled && (s.CAFile != "" || s.CertFile != "" || s.ServerName != "" || s.InsecureSkipVerify) {
+		return errors.New("TLS settings are given but TLS is not enabled")
+	}
+	return nil
+}
+
+// Config returns the TLS configuration, or nil when TLS is not enabled.
+func (s *TLSSpec) Config() (*tls.Config, error) {
+	if !s.Enabled {
+		return nil, nil
+	}
+	c := &tls.Config{
+		ServerName:         s.ServerName,
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+	if s.CAFile != "" {
+		pem, err := ioutil.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("specs: no certificates in %s", s.CAFile)
+		}
+	}
+	if s.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
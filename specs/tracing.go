@@ -0,0 +1,163 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// TraceSampler is a trace sampling rule, written as in OTEL_TRACES_SAMPLER:
+// "always_on", "always_off" or "traceidratio=0.1", each optionally prefixed
+// with "parentbased_" to follow the decision of a sampled or unsampled
+// parent. A bare ratio such as "10%" or "1/1000" is short for traceidratio.
+type TraceSampler struct {
+	ParentBased bool
+	Ratio       float64
+}
+
+// Decode implements envconfig.Decoder.
+func (t *TraceSampler) Decode(value string) error {
+	s := strings.ToLower(strings.TrimSpace(value))
+	parent := strings.HasPrefix(s, "parentbased_")
+	s = strings.TrimPrefix(s, "parentbased_")
+
+	var ratio envconfig.Sampling
+	switch {
+	case s == "always_on":
+		ratio = 1
+	case s == "always_off":
+		ratio = 0
+	default:
+		if err := ratio.Decode(strings.TrimPrefix(s, "traceidratio=")); err != nil {
+			return fmt.Errorf("invalid trace sampler %q, expected always_on, always_off or traceidratio=<ratio>", value)
+		}
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("trace sampler ratio %q out of range [0, 1]", value)
+		}
+	}
+	t.ParentBased, t.Ratio = parent, float64(ratio)
+	return nil
+}
+
+// String formats the sampler in the OTEL_TRACES_SAMPLER form.
+func (t TraceSampler) String() string {
+	var s string
+	switch t.Ratio {
+	case 1:
+		s = "always_on"
+	case 0:
+		s = "always_off"
+	default:
+		s = fmt.Sprintf("traceidratio=%g", t.Ratio)
+	}
+	if t.ParentBased {
+		s = "parentbased_" + s
+	}
+	return s
+}
+
+// Propagators accepted by TracingSpec, as in OTEL_PROPAGATORS.
+var tracePropagators = map[string]bool{
+	"tracecontext": true,
+	"baggage":      true,
+	"b3":           true,
+	"b3multi":      true,
+	"jaeger":       true,
+	"xray":         true,
+	"ottrace":      true,
+	"none":         true,
+}
+
+// TracingSpec holds the settings of OpenTelemetry tracing: the OTLP
+// collector spans are exported to, the sampler, and the propagators that
+// carry trace context across services.
+//
+//	MYAPP_TRACING_ENDPOINT=otel-collector:4317
+//	MYAPP_TRACING_SAMPLER=parentbased_traceidratio=0.05
+//	MYAPP_TRACING_PROPAGATORS=tracecontext,baggage,b3
+type TracingSpec struct {
+	Enabled     bool              `default:"true"`
+	ServiceName string            `split_words:"true" desc:"service.name resource attribute"`
+	Endpoint    string            `default:"localhost:4317" desc:"host:port, or a URL for http/protobuf"`
+	Protocol    string            `default:"grpc" desc:"grpc or http/protobuf"`
+	Headers     map[string]string `map_delimiter:"=" sensitive:"true" desc:"key=value pairs, often API keys"`
+	Insecure    bool              `desc:"connect without TLS"`
+	Sampler     TraceSampler      `default:"parentbased_always_on"`
+	Propagators []string          `default:"tracecontext,baggage"`
+	Timeout     time.Duration     `default:"10s" desc:"deadline of each export"`
+}
+
+// Validate implements envconfig.Validator.
+func (s *TracingSpec) Validate() error {
+	if len(s.Propagators) == 0 {
+		return errors.New("no trace propagators, use none to disable propagation")
+	}
+	for _, p := range s.Propagators {
+		if !tracePropagators[p] {
+			return fmt.Errorf("unknown trace propagator %q", p)
+		}
+		if p == "none" && len(s.Propagators) > 1 {
+			return errors.New("trace propagator none cannot be combined with others")
+		}
+	}
+	if !s.Enabled {
+		return nil
+	}
+	if err := checkOTLPEndpoint(s.Protocol, s.Endpoint); err != nil {
+		return err
+	}
+	if s.Timeout <= 0 {
+		return errors.New("tracing timeout must be positive")
+	}
+	return nil
+}
+
+// TracingOptions has the settings of an OpenTelemetry TracerProvider, which
+// this package does not import. Build one with
+//
+//	exp, err := otlptracegrpc.New(ctx,
+//		otlptracegrpc.WithEndpoint(o.Endpoint),
+//		otlptracegrpc.WithHeaders(o.Headers),
+//		otlptracegrpc.WithTimeout(o.Timeout))
+//	tp := sdktrace.NewTracerProvider(
+//		sdktrace.WithBatcher(exp),
+//		sdktrace.WithSampler(sampler(o.Sampler)))
+//
+// mapping the sampler to sdktrace.ParentBased and sdktrace.TraceIDRatioBased,
+// and the propagator names to those of autoprop.NewTextMapPropagator.
+type TracingOptions struct {
+	ServiceName string
+	Protocol    string
+	Endpoint    string // host:port
+	URLPath     string // for http/protobuf, when the endpoint is a URL
+	Headers     map[string]string
+	Insecure    bool
+	Sampler     TraceSampler
+	Propagators []string
+	Timeout     time.Duration
+}
+
+// Options returns the tracer provider options. When tracing is disabled the
+// sampler is always_off, so spans are still propagated but not recorded.
+func (s *TracingSpec) Options() TracingOptions {
+	o := TracingOptions{
+		ServiceName: s.ServiceName,
+		Protocol:    s.Protocol,
+		Headers:     s.Headers,
+		Sampler:     s.Sampler,
+		Propagators: s.Propagators,
+		Timeout:     s.Timeout,
+	}
+	o.Endpoint, o.URLPath, o.Insecure = splitOTLPEndpoint(s.Endpoint, s.Insecure)
+	if !s.Enabled {
+		o.Sampler = TraceSampler{}
+	}
+	return o
+}
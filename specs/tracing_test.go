@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package specs
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestTracingSpec(t *testing.T) {
+	var s struct {
+		Tracing TracingSpec
+	}
+	os.Clearenv()
+	os.Setenv("MYAPP_TRACING_ENDPOINT", "https://api.honeycomb.io/v1/traces")
+	os.Setenv("MYAPP_TRACING_PROTOCOL", "http/protobuf")
+	os.Setenv("MYAPP_TRACING_HEADERS", "x-honeycomb-team=key")
+	os.Setenv("MYAPP_TRACING_SAMPLER", "parentbased_traceidratio=0.05")
+	os.Setenv("MYAPP_TRACING_PROPAGATORS", "tracecontext,baggage,b3")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	o := s.Tracing.Options()
+	if o.Endpoint != "api.honeycomb.io" || o.URLPath != "/v1/traces" || o.Insecure {
+		t.Errorf("unexpected endpoint %q, %q, %v", o.Endpoint, o.URLPath, o.Insecure)
+	}
+	if o.Sampler != (TraceSampler{ParentBased: true, Ratio: 0.05}) || o.Headers["x-honeycomb-team"] != "key" {
+		t.Errorf("unexpected options %+v", o)
+	}
+	if !reflect.DeepEqual(o.Propagators, []string{"tracecontext", "baggage", "b3"}) {
+		t.Errorf("unexpected propagators %q", o.Propagators)
+	}
+
+	os.Setenv("MYAPP_TRACING_PROPAGATORS", "w3c")
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Validate: true}); err == nil {
+		t.Error("expected error for an unknown propagator, got nil")
+	}
+}
+
+func TestTraceSampler(t *testing.T) {
+	for value, want := range map[string]string{
+		"always_on":             "always_on",
+		"ALWAYS_OFF":            "always_off",
+		"parentbased_always_on": "parentbased_always_on",
+		"traceidratio=0.25":     "traceidratio=0.25",
+		"10%":                   "traceidratio=0.1",
+		"parentbased_1/1000":    "parentbased_traceidratio=0.001",
+	} {
+		var s TraceSampler
+		if err := s.Decode(value); err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+		} else if s.String() != want {
+			t.Errorf("%q: expected %s, got %s", value, want, s)
+		}
+	}
+	for _, value := range []string{"", "sometimes", "traceidratio=2", "parentbased_"} {
+		var s TraceSampler
+		if err := s.Decode(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+}
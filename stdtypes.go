@@ -0,0 +1,46 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"net"
+	"net/mail"
+	"reflect"
+)
+
+var (
+	ipNetType       = reflect.TypeOf(net.IPNet{})
+	mailAddressType = reflect.TypeOf(mail.Address{})
+)
+
+// isStdlibType reports whether t is one of the standard library structs that
+// are decoded as a whole although they have no decoding method of their own:
+// net.IPNet is parsed in CIDR notation like "10.0.0.0/8" and mail.Address as
+// an RFC 5322 address like "Gopher <gopher@example.com>". url.URL and net.IP
+// need nothing special, as they implement encoding.BinaryUnmarshaler and
+// encoding.TextUnmarshaler.
+func isStdlibType(t reflect.Type) bool {
+	return t == ipNetType || t == mailAddressType
+}
+
+// decodeStdlib decodes value into field, which must hold a type for which
+// isStdlibType is true.
+func decodeStdlib(value string, field reflect.Value) error {
+	switch field.Type() {
+	case ipNetType:
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*n))
+	case mailAddressType:
+		a, err := mail.ParseAddress(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(*a))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestStdlibTypes(t *testing.T) {
+	var s struct {
+		Endpoint  *url.URL
+		Proxy     url.URL
+		Bind      net.IP
+		Peer      *net.IP
+		Network   net.IPNet
+		Trusted   []*net.IPNet
+		From      mail.Address
+		ReplyTo   *mail.Address
+		Endpoints []*url.URL
+	}
+	os.Clearenv()
+	os.Setenv("APP_ENDPOINT", "https://api.example.com/v1")
+	os.Setenv("APP_PROXY", "http://proxy:3128")
+	os.Setenv("APP_BIND", "10.0.0.1")
+	os.Setenv("APP_PEER", "::1")
+	os.Setenv("APP_NETWORK", "10.1.2.3/8")
+	os.Setenv("APP_TRUSTED", "192.168.0.0/16,fd00::/8")
+	os.Setenv("APP_FROM", "Gopher <gopher@example.com>")
+	os.Setenv("APP_REPLYTO", "noreply@example.com")
+	os.Setenv("APP_ENDPOINTS", "http://a:80,http://b:80")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Endpoint == nil || s.Endpoint.Host != "api.example.com" {
+		t.Errorf("unexpected endpoint %v", s.Endpoint)
+	}
+	if s.Proxy.Host != "proxy:3128" {
+		t.Errorf("unexpected proxy %v", s.Proxy)
+	}
+	if !s.Bind.Equal(net.ParseIP("10.0.0.1")) || s.Peer == nil || !s.Peer.Equal(net.IPv6loopback) {
+		t.Errorf("unexpected addresses %v, %v", s.Bind, s.Peer)
+	}
+	if s.Network.String() != "10.0.0.0/8" {
+		t.Errorf("expected %s, got %s", "10.0.0.0/8", s.Network.String())
+	}
+	if len(s.Trusted) != 2 || !s.Trusted[1].Contains(net.ParseIP("fd00::1")) {
+		t.Errorf("unexpected trusted networks %v", s.Trusted)
+	}
+	if s.From.Name != "Gopher" || s.From.Address != "gopher@example.com" {
+		t.Errorf("unexpected from %v", s.From)
+	}
+	if s.ReplyTo == nil || s.ReplyTo.Address != "noreply@example.com" {
+		t.Errorf("unexpected reply to %v", s.ReplyTo)
+	}
+	if len(s.Endpoints) != 2 || s.Endpoints[1].Host != "b:80" {
+		t.Errorf("unexpected endpoints %v", s.Endpoints)
+	}
+
+	for key, value := range map[string]string{
+		"APP_NETWORK": "10.0.0.0",
+		"APP_FROM":    "not an address",
+		"APP_BIND":    "10.0.0.256",
+	} {
+		os.Setenv(key, value)
+		if err := Process("app", &s); err == nil {
+			t.Errorf("%s=%s: expected error, got nil", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestStdlibTypesUsage(t *testing.T) {
+	var s struct {
+		Network net.IPNet
+		From    *mail.Address
+	}
+	vars, err := Describe("app", &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(vars) != 2 || vars[0].Key != "APP_NETWORK" || vars[0].TypeDescription != "IPNet" || vars[1].TypeDescription != "Address" {
+		t.Errorf("unexpected variables %+v", vars)
+	}
+}
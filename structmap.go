@@ -0,0 +1,142 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// isStructMap reports whether t is a map from strings to structs, or to
+// pointers to structs, that are populated field by field rather than decoded.
+func isStructMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String || isSetType(t) || implementsInterface(t) {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && !implementsInterface(elem)
+}
+
+// gatherStructMap gathers the variables of a map of structs from keys with
+// the name of each entry in the middle: APP_UPSTREAMS_BILLING_URL and
+// APP_UPSTREAMS_BILLING_TIMEOUT fill the "billing" entry of Upstreams. The
+// names are found by listing the variables, so entries are only found in the
+// process environment and in Lookupers that implement KeyLister. Existing
+// entries keep the values of fields that are not set.
+//
+// Map values cannot be set in place, so each entry is decoded into a copy
+// that the commit function of its variables stores in the map.
+func gatherStructMap(info varInfo, options Options) ([]varInfo, error) {
+	typ := info.Field.Type()
+	elemType := typ.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	// the keys of an entry must not depend on its name
+	options.SquashPrefixes = false
+
+	probe, err := gatherInfo("", reflect.New(elemType).Interface(), options)
+	if err != nil {
+		return nil, err
+	}
+	suffixes := make([]string, len(probe))
+	for i, p := range probe {
+		suffixes[i] = "_" + p.Key
+	}
+	// longest first, so FOO_TLS_CERT is entry FOO rather than FOO_TLS
+	sort.Slice(suffixes, func(i, j int) bool { return len(suffixes[i]) > len(suffixes[j]) })
+
+	prefix := info.Key + "_"
+	if options.placeholders {
+		return gatherPlaceholder(reflect.New(elemType), prefix+"{NAME}", info.Path+"[{NAME}].", options)
+	}
+	seen := make(map[string]bool)
+	var names []string
+	mapKeys := make(map[string]string)
+	if options.fromValues {
+		iter := info.Field.MapRange()
+		for iter.Next() {
+			name := strings.ToUpper(iter.Key().String())
+			names = append(names, name)
+			mapKeys[name] = iter.Key().String()
+		}
+	}
+	for _, key := range options.keys() {
+		if options.fromValues || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		for _, suffix := range suffixes {
+			if len(rest) > len(suffix) && strings.HasSuffix(rest, suffix) {
+				if name := rest[:len(rest)-len(suffix)]; !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+					mapKeys[name] = strings.ToLower(name)
+				}
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	mp := info.Field
+	if mp.IsNil() {
+		mp.Set(reflect.MakeMap(typ))
+	}
+	var infos []varInfo
+	for _, name := range names {
+		k := reflect.New(typ.Key()).Elem()
+		k.SetString(mapKeys[name])
+
+		elem := reflect.New(elemType)
+		if existing := mp.MapIndex(k); existing.IsValid() {
+			if !isPtr {
+				elem.Elem().Set(existing)
+			} else if !existing.IsNil() {
+				elem = existing
+			}
+		}
+		commit := func() {
+			if isPtr {
+				mp.SetMapIndex(k, elem)
+			} else {
+				mp.SetMapIndex(k, elem.Elem())
+			}
+		}
+
+		elemInfos, err := gatherInfo(prefix+name, elem.Interface(), options)
+		if err != nil {
+			return nil, err
+		}
+		for j := range elemInfos {
+			elemInfos[j].Path = fmt.Sprintf("%s[%s].%s", info.Path, k.String(), elemInfos[j].Path)
+			if inner := elemInfos[j].commit; inner != nil {
+				elemInfos[j].commit = func() { inner(); commit() }
+			} else {
+				elemInfos[j].commit = commit
+			}
+		}
+		infos = append(infos, elemInfos...)
+	}
+	return infos, nil
+}
+
+// commitInfos stores the map entries decoded by the variables in infos.
+func commitInfos(infos []varInfo) {
+	for _, info := range infos {
+		if info.commit != nil {
+			info.commit()
+		}
+	}
+}
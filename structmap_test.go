@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type upstream struct {
+	URL     string        `required:"true"`
+	Timeout time.Duration `default:"5s"`
+	TLS     struct {
+		CertFile string `split_words:"true"`
+	}
+}
+
+func TestStructMap(t *testing.T) {
+	var s struct {
+		Upstreams map[string]upstream
+		Tenants   map[string]*endpoint
+	}
+	os.Clearenv()
+	os.Setenv("APP_UPSTREAMS_BILLING_URL", "http://billing")
+	os.Setenv("APP_UPSTREAMS_BILLING_TIMEOUT", "1s")
+	os.Setenv("APP_UPSTREAMS_EU_WEST_URL", "http://eu-west")
+	os.Setenv("APP_UPSTREAMS_EU_WEST_TLS_CERT_FILE", "eu.pem")
+	os.Setenv("APP_TENANTS_ACME_HOST", "acme.example.com")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(s.Upstreams) != 2 {
+		t.Fatalf("expected 2 upstreams, got %+v", s.Upstreams)
+	}
+	if u := s.Upstreams["billing"]; u.URL != "http://billing" || u.Timeout != time.Second {
+		t.Errorf("unexpected billing upstream %+v", u)
+	}
+	if u := s.Upstreams["eu_west"]; u.URL != "http://eu-west" || u.Timeout != 5*time.Second || u.TLS.CertFile != "eu.pem" {
+		t.Errorf("unexpected eu_west upstream %+v", u)
+	}
+	if e := s.Tenants["acme"]; e == nil || e.Host != "acme.example.com" || e.Port != 80 {
+		t.Errorf("unexpected tenant %+v", e)
+	}
+
+	os.Setenv("APP_UPSTREAMS_BROKEN_TIMEOUT", "1s")
+	err := Process("app", &s)
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || rerr.KeyName != "APP_UPSTREAMS_BROKEN_URL" {
+		t.Errorf("expected a RequiredError for APP_UPSTREAMS_BROKEN_URL, got %v", err)
+	}
+	os.Unsetenv("APP_UPSTREAMS_BROKEN_TIMEOUT")
+
+	os.Setenv("APP_TENANTS_ACME_PORT", "0")
+	err = ProcessWithOptions("app", &s, Options{Validate: true})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.FieldName != "Tenants[acme]" {
+		t.Errorf("expected a ValidationError for Tenants[acme], got %v", err)
+	}
+}
+
+func TestStructMapLookuper(t *testing.T) {
+	var s struct {
+		Upstreams map[string]upstream
+	}
+	os.Clearenv()
+	l := MapLookuper{"APP_UPSTREAMS_A_URL": "http://a"}
+	if err := ProcessWithLookuper("app", &s, MultiLookuper(OSLookuper(), l)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Upstreams["a"].URL != "http://a" {
+		t.Errorf("unexpected upstreams %+v", s.Upstreams)
+	}
+}
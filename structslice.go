@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isStructSlice reports whether t is a slice of structs, or of pointers to
+// structs, that are populated field by field rather than decoded.
+func isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || implementsInterface(t) {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && !implementsInterface(elem)
+}
+
+// gatherStructSlice gathers the variables of a slice of structs from indexed
+// keys: APP_ENDPOINTS_0_HOST, APP_ENDPOINTS_0_PORT, APP_ENDPOINTS_1_HOST and
+// so on, up to the first index under which no variable is set. The slice is
+// resized to that many elements, keeping the values of existing ones. When
+// no variable is set, the field is left unchanged and has no variables.
+func gatherStructSlice(info varInfo, options Options) ([]varInfo, error) {
+	typ := info.Field.Type()
+	elemType := typ.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if options.placeholders {
+		return gatherPlaceholder(reflect.New(elemType), info.Key+"_{N}", info.Path+"[{N}].", options)
+	}
+
+	// probing for the elements is not reported to OnLookup
+	probeOptions := options
+	probeOptions.OnLookup = nil
+	n := 0
+	for ; !options.fromValues; n++ {
+		probe, err := gatherInfo(fmt.Sprintf("%s_%d", info.Key, n), reflect.New(elemType).Interface(), probeOptions)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, p := range probe {
+			if _, found = probeOptions.lookup(p.Key); found {
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if options.fromValues {
+		n = info.Field.Len()
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	sl := reflect.MakeSlice(typ, n, n)
+	reflect.Copy(sl, info.Field)
+	info.Field.Set(sl)
+
+	var infos []varInfo
+	for i := 0; i < n; i++ {
+		elem := sl.Index(i)
+		if isPtr {
+			if elem.IsNil() {
+				elem.Set(reflect.New(elemType))
+			}
+			elem = elem.Elem()
+		}
+		elemInfos, err := gatherInfo(fmt.Sprintf("%s_%d", info.Key, i), elem.Addr().Interface(), options)
+		if err != nil {
+			return nil, err
+		}
+		for j := range elemInfos {
+			elemInfos[j].Path = fmt.Sprintf("%s[%d].%s", info.Path, i, elemInfos[j].Path)
+		}
+		infos = append(infos, elemInfos...)
+	}
+	return infos, nil
+}
+
+// gatherPlaceholder gathers the variables of elem, a new element of a slice
+// or map of structs, under a placeholder such as APP_ENDPOINTS_{N}, to list
+// the variables an element has without looking for elements.
+func gatherPlaceholder(elem reflect.Value, prefix, path string, options Options) ([]varInfo, error) {
+	infos, err := gatherInfo(prefix, elem.Interface(), options)
+	if err != nil {
+		return nil, err
+	}
+	for j := range infos {
+		infos[j].Path = path + infos[j].Path
+	}
+	return infos, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+type endpoint struct {
+	Host string `required:"true"`
+	Port int    `default:"80"`
+}
+
+func (e *endpoint) Validate() error {
+	if e.Port == 0 {
+		return errors.New("port 0")
+	}
+	return nil
+}
+
+func TestStructSlice(t *testing.T) {
+	var s struct {
+		Endpoints []endpoint
+		Listeners []*endpoint
+	}
+	os.Clearenv()
+	os.Setenv("APP_ENDPOINTS_0_HOST", "a.example.com")
+	os.Setenv("APP_ENDPOINTS_1_HOST", "b.example.com")
+	os.Setenv("APP_ENDPOINTS_1_PORT", "8080")
+	os.Setenv("APP_ENDPOINTS_3_HOST", "after a gap")
+	os.Setenv("APP_LISTENERS_0_PORT", "9090")
+	err := Process("app", &s)
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || rerr.KeyName != "APP_LISTENERS_0_HOST" {
+		t.Fatalf("expected a RequiredError for APP_LISTENERS_0_HOST, got %v", err)
+	}
+
+	os.Setenv("APP_LISTENERS_0_HOST", "localhost")
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(s.Endpoints) != 2 || s.Endpoints[0] != (endpoint{"a.example.com", 80}) || s.Endpoints[1] != (endpoint{"b.example.com", 8080}) {
+		t.Errorf("unexpected endpoints %+v", s.Endpoints)
+	}
+	if len(s.Listeners) != 1 || *s.Listeners[0] != (endpoint{"localhost", 9090}) {
+		t.Errorf("unexpected listeners %+v", s.Listeners)
+	}
+
+	os.Setenv("APP_ENDPOINTS_1_PORT", "0")
+	err = ProcessWithOptions("app", &s, Options{Validate: true})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.FieldName != "Endpoints[1]" {
+		t.Errorf("expected a ValidationError for Endpoints[1], got %v", err)
+	}
+
+	os.Clearenv()
+	s.Endpoints = nil
+	if err := Process("app", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Endpoints != nil {
+		t.Errorf("expected no endpoints, got %+v", s.Endpoints)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagNames maps the tag keys this package reads (such as "envconfig",
+// "default" and "required") to the keys used by another library, so structs
+// annotated for that library can be processed as they are. Keys that are not
+// mapped keep their usual name, and a mapped key takes precedence over the
+// usual one when a field has both.
+//
+// A value in the tag mapped to "envconfig" may carry a comma separated list of
+// flags after the name, e.g. `env:"PORT,required"`. Each flag is read as the
+// boolean tag of the same name set to "true". A name of "-" ignores the field.
+type TagNames map[string]string
+
+// CaarlosTagNames reads the tags used by github.com/caarlos0/env.
+var CaarlosTagNames = TagNames{
+	"envconfig": "env",
+	"default":   "envDefault",
+}
+
+// rewrite returns tag with the mapped keys translated to their usual names.
+// StructTag.Get returns the first match, so the translations are prepended.
+func (names TagNames) rewrite(tag reflect.StructTag) reflect.StructTag {
+	if len(names) == 0 {
+		return tag
+	}
+
+	var b strings.Builder
+	add := func(key, value string) {
+		b.WriteString(key)
+		b.WriteByte(':')
+		b.WriteString(strconv.Quote(value))
+		b.WriteByte(' ')
+	}
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		alt := names[key]
+		value, ok := tag.Lookup(alt)
+		if !ok || key == alt {
+			continue
+		}
+		if key == "envconfig" {
+			parts := strings.Split(value, ",")
+			value = strings.TrimSpace(parts[0])
+			if value == "-" {
+				add("ignored", "true")
+				value = ""
+			}
+			for _, flag := range parts[1:] {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					add(flag, "true")
+				}
+			}
+		}
+		add(key, value)
+	}
+	return reflect.StructTag(b.String() + string(tag))
+}
+
+// KeyTags returns, by key, the value of the tag called name of each variable
+// of the specification whose field has one, so a Lookuper can be configured
+// from tags of its own, such as `vault:"secret/myapp#db_password"`. The
+// environment is not consulted, and spec is not modified.
+func KeyTags(prefix string, spec interface{}, name string, options Options) (map[string]string, error) {
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+	options.Lookuper, options.OnLookup = MapLookuper{}, nil
+	infos, err := gatherInfo(prefix, deepCopy(v).Interface(), options)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	for _, info := range infos {
+		if value, ok := info.Tags.Lookup(name); ok {
+			tags[info.Key] = value
+		}
+	}
+	return tags, nil
+}
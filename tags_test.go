@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+)
+
+type caarlosSpecification struct {
+	Home     string `env:"HOME_DIR"`
+	Port     int    `env:"PORT" envDefault:"3000"`
+	Password string `env:"PASSWORD,required"`
+	Skipped  string `env:"-"`
+	Native   string `envconfig:"NATIVE_NAME" default:"native"`
+}
+
+func TestTagNames(t *testing.T) {
+	var s caarlosSpecification
+	os.Clearenv()
+	os.Setenv("HOME_DIR", "/home/gopher")
+	os.Setenv("PASSWORD", "hunter2")
+	os.Setenv("SKIPPED", "nope")
+	if err := ProcessWithOptions("", &s, Options{TagNames: CaarlosTagNames}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Home != "/home/gopher" {
+		t.Errorf("expected %q, got %q", "/home/gopher", s.Home)
+	}
+	if s.Port != 3000 {
+		t.Errorf("expected %d, got %d", 3000, s.Port)
+	}
+	if s.Password != "hunter2" {
+		t.Errorf("expected %q, got %q", "hunter2", s.Password)
+	}
+	if s.Skipped != "" {
+		t.Errorf("expected ignored field to be empty, got %q", s.Skipped)
+	}
+	if s.Native != "native" {
+		t.Errorf("expected %q, got %q", "native", s.Native)
+	}
+
+	os.Unsetenv("PASSWORD")
+	err := ProcessWithOptions("", &s, Options{TagNames: CaarlosTagNames})
+	if err == nil || err.Error() != "required key PASSWORD missing value" {
+		t.Errorf("expected required key error, got %v", err)
+	}
+}
+
+func TestTagNamesPrecedence(t *testing.T) {
+	var s struct {
+		Value string `default:"usual" dflt:"mapped"`
+	}
+	os.Clearenv()
+	if err := ProcessWithOptions("env_config", &s, Options{TagNames: TagNames{"default": "dflt"}}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Value != "mapped" {
+		t.Errorf("expected %q, got %q", "mapped", s.Value)
+	}
+
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Value != "usual" {
+		t.Errorf("expected %q, got %q", "usual", s.Value)
+	}
+}
+
+func TestKeyTags(t *testing.T) {
+	var s struct {
+		Host string
+		DB   struct {
+			Password string `vault:"secret/myapp#db_password"`
+		}
+		Token string `envconfig:"API_TOKEN" vault:"secret/api#token"`
+	}
+	tags, err := KeyTags("myapp", &s, "vault", Options{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tags) != 2 || tags["MYAPP_DB_PASSWORD"] != "secret/myapp#db_password" || tags["MYAPP_API_TOKEN"] != "secret/api#token" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+)
+
+// readPassword is not supported on this platform; sensitive values are never
+// read with echo enabled.
+func readPassword(f *os.File) (string, error) {
+	return "", errors.New("hidden input is not supported on this platform")
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Transformer rewrites a value before it is decoded. Fields list the
+// transformers to apply, in order, in a `transform` tag such as
+// `transform:"trim,lower"`.
+type Transformer func(value string) (string, error)
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = map[string]Transformer{
+		"trim":    plainTransformer(strings.TrimSpace),
+		"lower":   plainTransformer(strings.ToLower),
+		"upper":   plainTransformer(strings.ToUpper),
+		"unquote": unquote,
+		"base64":  decodeBase64,
+	}
+)
+
+// RegisterTransformer makes t available to `transform` tags as name. The
+// built-in transformers are trim, lower, upper, expand (which substitutes
+// $VAR and ${VAR} like the `expand` tag, through Options.Lookuper), unquote
+// (which strips one level of matching single or double quotes) and base64.
+// expand cannot be replaced.
+func RegisterTransformer(name string, t Transformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers[name] = t
+}
+
+func plainTransformer(f func(string) string) Transformer {
+	return func(value string) (string, error) { return f(value), nil }
+}
+
+// transform applies the comma separated transformers in names to value.
+func (info varInfo) transform(value, names string, options Options) (string, error) {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "expand" {
+			var err error
+			if value, err = expandRefs(value, options, true, []string{info.Key}); err != nil {
+				return "", fmt.Errorf("transformer %q: %v", name, err)
+			}
+			continue
+		}
+		transformersMu.RLock()
+		t, ok := transformers[name]
+		transformersMu.RUnlock()
+		if !ok {
+			return "", fmt.Errorf("unknown transformer %q", name)
+		}
+		var err error
+		if value, err = t(value); err != nil {
+			return "", fmt.Errorf("transformer %q: %v", name, err)
+		}
+	}
+	return value, nil
+}
+
+func unquote(value string) (string, error) {
+	if len(value) < 2 || value[0] != value[len(value)-1] {
+		return value, nil
+	}
+	switch value[0] {
+	case '"':
+		return strconv.Unquote(value)
+	case '\'':
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding.
+// Whitespace is ignored, so values wrapped across lines decode too.
+func decodeBase64(value string) (string, error) {
+	value = strings.Join(strings.Fields(value), "")
+	enc := base64.StdEncoding
+	if strings.ContainsAny(value, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(value, "=") && len(value)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	b, err := enc.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	var s struct {
+		Level  string   `transform:"trim,lower"`
+		Region string   `transform:"unquote,upper"`
+		Path   string   `transform:"expand"`
+		Tags   []string `transform:"trim"`
+	}
+	os.Clearenv()
+	os.Setenv("HOME", "/home/gopher")
+	os.Setenv("ENV_CONFIG_LEVEL", "  WARN\n")
+	os.Setenv("ENV_CONFIG_REGION", `"eu-west-1"`)
+	os.Setenv("ENV_CONFIG_PATH", "${HOME}/data")
+	os.Setenv("ENV_CONFIG_TAGS", " a,b ")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Level != "warn" {
+		t.Errorf("expected %q, got %q", "warn", s.Level)
+	}
+	if s.Region != "EU-WEST-1" {
+		t.Errorf("expected %q, got %q", "EU-WEST-1", s.Region)
+	}
+	if s.Path != "/home/gopher/data" {
+		t.Errorf("expected %q, got %q", "/home/gopher/data", s.Path)
+	}
+	if len(s.Tags) != 2 || s.Tags[0] != "a" || s.Tags[1] != "b" {
+		t.Errorf("expected [a b], got %q", s.Tags)
+	}
+}
+
+func TestExpandTransformLookuper(t *testing.T) {
+	var s struct {
+		Path string `transform:"expand"`
+	}
+	os.Clearenv()
+	os.Setenv("HOME", "/home/env")
+	env := map[string]string{"HOME": "/home/map", "ENV_CONFIG_PATH": "${HOME}/data"}
+	if err := ProcessMap("env_config", &s, env, Options{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Path != "/home/map/data" {
+		t.Errorf("expected %q, got %q", "/home/map/data", s.Path)
+	}
+}
+
+func TestBase64Transform(t *testing.T) {
+	var s struct {
+		Key   []byte         `transform:"base64"`
+		Token string         `transform:"trim,base64"`
+		Cert  PEMCertificate `transform:"base64"`
+	}
+	cert, _ := testPEM(t)
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_KEY", "AP8-_w")
+	os.Setenv("ENV_CONFIG_TOKEN", " c2Vj\ncmV0\n ")
+	os.Setenv("ENV_CONFIG_CERT", base64.StdEncoding.EncodeToString([]byte(cert)))
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(s.Key) != "\x00\xff\x3e\xff" {
+		t.Errorf("unexpected key %x", s.Key)
+	}
+	if s.Token != "secret" {
+		t.Errorf("expected %q, got %q", "secret", s.Token)
+	}
+	if s.Cert.Certificate == nil || s.Cert.Subject.CommonName != "envconfig" {
+		t.Errorf("unexpected certificate %v", s.Cert.Certificate)
+	}
+
+	os.Setenv("ENV_CONFIG_KEY", "not base64!")
+	if _, ok := Process("env_config", &s).(*ParseError); !ok {
+		t.Error("expected ParseError for invalid base64")
+	}
+}
+
+func TestRegisterTransformer(t *testing.T) {
+	RegisterTransformer("test_nonempty", func(v string) (string, error) {
+		if v == "" {
+			return "", errors.New("empty value")
+		}
+		return strings.Replace(v, "-", "_", -1), nil
+	})
+	var s struct {
+		Name string `transform:"trim,test_nonempty"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", " my-app ")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Name != "my_app" {
+		t.Errorf("expected %q, got %q", "my_app", s.Name)
+	}
+
+	os.Setenv("ENV_CONFIG_NAME", "  ")
+	if _, ok := Process("env_config", &s).(*ParseError); !ok {
+		t.Error("expected ParseError from failing transformer")
+	}
+}
+
+func TestUnknownTransformer(t *testing.T) {
+	var s struct {
+		Name string `transform:"reverse"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "x")
+	err := Process("env_config", &s)
+	if err == nil || !strings.Contains(err.Error(), `unknown transformer "reverse"`) {
+		t.Errorf("expected unknown transformer error, got %v", err)
+	}
+}
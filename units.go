@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "days": 24 * time.Hour,
+}
+
+var byteUnits = map[string]float64{
+	"b": 1, "bytes": 1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+}
+
+// applyUnit converts a plain number written in unit, as given by the `unit`
+// tag, into the form processField expects for field. time.Duration fields
+// take time units ("seconds", "ms", ...) and other numeric fields take byte
+// units ("MB", "MiB", ...). Values that are not plain numbers, such as "30s"
+// for a duration, are returned unchanged.
+func applyUnit(value, unit string, field reflect.Value) (string, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return value, nil
+	}
+
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.PkgPath() == "time" && typ.Name() == "Duration" {
+		d, ok := durationUnits[strings.ToLower(unit)]
+		if !ok {
+			return "", fmt.Errorf("unknown time unit %q", unit)
+		}
+		return time.Duration(n * float64(d)).String(), nil
+	}
+
+	m, ok := byteUnits[strings.ToLower(unit)]
+	if !ok {
+		return "", fmt.Errorf("unknown byte unit %q", unit)
+	}
+	n *= m
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n != math.Trunc(n) {
+			return "", fmt.Errorf("%s %s is not a whole number of bytes", value, unit)
+		}
+		return strconv.FormatFloat(n, 'f', 0, 64), nil
+	}
+	return "", fmt.Errorf("unit requires a numeric field, got %s", typ)
+}
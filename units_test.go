@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUnits(t *testing.T) {
+	var s struct {
+		Timeout    time.Duration  `unit:"seconds"`
+		Interval   time.Duration  `unit:"ms"`
+		Fallback   time.Duration  `unit:"seconds"`
+		Retention  *time.Duration `unit:"days" default:"7"`
+		CacheSize  int64          `unit:"MB"`
+		BufferSize uint32         `unit:"KiB"`
+		Ratio      float64        `unit:"GB"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_TIMEOUT", "30")
+	os.Setenv("ENV_CONFIG_INTERVAL", "1.5")
+	os.Setenv("ENV_CONFIG_FALLBACK", "2m")
+	os.Setenv("ENV_CONFIG_CACHESIZE", "512")
+	os.Setenv("ENV_CONFIG_BUFFERSIZE", "64")
+	os.Setenv("ENV_CONFIG_RATIO", "0.5")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Timeout != 30*time.Second {
+		t.Errorf("expected %s, got %s", 30*time.Second, s.Timeout)
+	}
+	if s.Interval != 1500*time.Microsecond {
+		t.Errorf("expected %s, got %s", 1500*time.Microsecond, s.Interval)
+	}
+	if s.Fallback != 2*time.Minute {
+		t.Errorf("expected %s, got %s", 2*time.Minute, s.Fallback)
+	}
+	if s.Retention == nil || *s.Retention != 7*24*time.Hour {
+		t.Errorf("expected %s, got %v", 7*24*time.Hour, s.Retention)
+	}
+	if s.CacheSize != 512e6 {
+		t.Errorf("expected %d, got %d", int64(512e6), s.CacheSize)
+	}
+	if s.BufferSize != 64<<10 {
+		t.Errorf("expected %d, got %d", 64<<10, s.BufferSize)
+	}
+	if s.Ratio != 0.5e9 {
+		t.Errorf("expected %v, got %v", 0.5e9, s.Ratio)
+	}
+}
+
+func TestUnitErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"unknown time unit": &struct {
+			Timeout time.Duration `unit:"fortnights"`
+		}{},
+		"unknown byte unit": &struct {
+			Size int `unit:"seconds"`
+		}{},
+		"fractional bytes": &struct {
+			Size int `unit:"B"`
+		}{},
+		"non-numeric field": &struct {
+			Size string `unit:"MB"`
+		}{},
+	}
+	for name, spec := range tests {
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_TIMEOUT", "1")
+		os.Setenv("ENV_CONFIG_SIZE", "1.5")
+		err := Process("env_config", spec)
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("%s: expected ParseError, got %T %v", name, err, err)
+		}
+	}
+}
@@ -44,7 +44,8 @@ var (
 )
 
 func implementsInterface(t reflect.Type) bool {
-	return t.Implements(decoderType) ||
+	return isStdlibType(t) || registeredDecoder(t) != nil ||
+		t.Implements(decoderType) ||
 		reflect.PtrTo(t).Implements(decoderType) ||
 		t.Implements(setterType) ||
 		reflect.PtrTo(t).Implements(setterType) ||
@@ -54,22 +55,28 @@ func implementsInterface(t reflect.Type) bool {
 		reflect.PtrTo(t).Implements(binaryUnmarshalerType)
 }
 
-// toTypeDescription converts Go types into a human readable description
-func toTypeDescription(t reflect.Type) string {
+// toTypeDescription converts Go types into a human readable description,
+// naming sep and kvSep, the delimiters of the variable, for slices and maps.
+func toTypeDescription(t reflect.Type, sep, kvSep string) string {
 	switch t.Kind() {
 	case reflect.Array, reflect.Slice:
 		if t.Elem().Kind() == reflect.Uint8 {
 			return "String"
 		}
-		return fmt.Sprintf("Comma-separated list of %s", toTypeDescription(t.Elem()))
+		return fmt.Sprintf("%s list of %s", separated(sep), toTypeDescription(t.Elem(), sep, kvSep))
 	case reflect.Map:
+		if isSetType(t) {
+			return fmt.Sprintf("%s set of %s", separated(sep), toTypeDescription(t.Key(), sep, kvSep))
+		}
 		return fmt.Sprintf(
-			"Comma-separated list of %s:%s pairs",
-			toTypeDescription(t.Key()),
-			toTypeDescription(t.Elem()),
+			"%s list of %s%s%s pairs",
+			separated(sep),
+			toTypeDescription(t.Key(), sep, kvSep),
+			kvSep,
+			toTypeDescription(t.Elem(), sep, kvSep),
 		)
 	case reflect.Ptr:
-		return toTypeDescription(t.Elem())
+		return toTypeDescription(t.Elem(), sep, kvSep)
 	case reflect.Struct:
 		if implementsInterface(t) && t.Name() != "" {
 			return t.Name()
@@ -109,6 +116,21 @@ func toTypeDescription(t reflect.Type) string {
 	return fmt.Sprintf("%+v", t)
 }
 
+// separated describes a list delimited by sep, e.g. "Comma-separated".
+func separated(sep string) string {
+	switch sep {
+	case ",":
+		return "Comma-separated"
+	case ";":
+		return "Semicolon-separated"
+	case " ":
+		return "Space-separated"
+	case "|":
+		return "Pipe-separated"
+	}
+	return fmt.Sprintf("%q-separated", sep)
+}
+
 // Usage writes usage information to stdout using the default header and table format
 func Usage(prefix string, spec interface{}) error {
 	return UsageWithOptions(prefix, spec, Options{})
@@ -116,12 +138,25 @@ func Usage(prefix string, spec interface{}) error {
 
 // UsageWithOptions is like Usage() but with specified options.
 func UsageWithOptions(prefix string, spec interface{}, options Options) error {
+	return UsageToWithOptions(prefix, spec, os.Stdout, options)
+}
+
+// UsageTo writes usage information to the specified io.Writer using the
+// default header and table format
+func UsageTo(prefix string, spec interface{}, out io.Writer) error {
+	return UsageToWithOptions(prefix, spec, out, Options{})
+}
+
+// UsageToWithOptions is like UsageTo() but with specified options.
+func UsageToWithOptions(prefix string, spec interface{}, out io.Writer, options Options) error {
 	// The default is to output the usage information as a table
 	// Create tabwriter instance to support table output
-	tabs := tabwriter.NewWriter(os.Stdout, 1, 0, 4, ' ', 0)
+	tabs := tabwriter.NewWriter(out, 1, 0, 4, ' ', 0)
 
 	err := UsagefWithOptions(prefix, spec, tabs, DefaultTableFormat, options)
-	tabs.Flush()
+	if flushErr := tabs.Flush(); err == nil {
+		err = flushErr
+	}
 	return err
 }
 
@@ -132,12 +167,21 @@ func Usagef(prefix string, spec interface{}, out io.Writer, format string) error
 
 // UsagefWithOptions is like Usagef() but with specified options.
 func UsagefWithOptions(prefix string, spec interface{}, out io.Writer, format string, options Options) error {
+	infos, err := gatherInfo(prefix, spec, options)
+	if err != nil {
+		return err
+	}
+	// defaults resolve against each other only, so usage never shows the
+	// values of variables that are set
+	defaults := options.withFields(infos)
+	defaults.Lookuper, defaults.OnLookup = MapLookuper{}, nil
+
 	// Specify the default usage template functions
 	functions := template.FuncMap{
 		"usage_key":         func(v varInfo) string { return v.Key },
 		"usage_description": func(v varInfo) string { return v.Tags.Get("desc") },
-		"usage_type":        func(v varInfo) string { return toTypeDescription(v.Field.Type()) },
-		"usage_default":     func(v varInfo) string { return v.Tags.Get("default") },
+		"usage_type":        func(v varInfo) string { return toTypeDescription(v.Field.Type(), v.Delimiter, v.MapDelimiter) },
+		"usage_default":     func(v varInfo) string { return usageDefault(v, defaults) },
 		"usage_required": func(v varInfo) (string, error) {
 			req := v.Tags.Get("required")
 			if req != "" {
@@ -161,6 +205,16 @@ func UsagefWithOptions(prefix string, spec interface{}, out io.Writer, format st
 	return UsagetWithOptions(prefix, spec, out, tmpl, options)
 }
 
+// usageDefault returns the default of v as written followed, when envconfig
+// computes it, by what it comes to now: "numcpu*2 (16)".
+func usageDefault(v varInfo, options Options) string {
+	def := v.defaultValue()
+	if value, computed := v.computedDefault(options); computed && value != "" {
+		return fmt.Sprintf("%s (%s)", def, value)
+	}
+	return def
+}
+
 // Usaget writes usage information to the specified io.Writer using the specified template
 func Usaget(prefix string, spec interface{}, out io.Writer, tmpl *template.Template) error {
 	return UsagetWithOptions(prefix, spec, out, tmpl, Options{})
@@ -169,7 +223,7 @@ func Usaget(prefix string, spec interface{}, out io.Writer, tmpl *template.Templ
 // UsagetWithOptions is like Usaget() but with specified options.
 func UsagetWithOptions(prefix string, spec interface{}, out io.Writer, tmpl *template.Template, options Options) error {
 	// gather first
-	infos, err := gatherInfo(prefix, spec, options)
+	infos, err := gatherOrdered(prefix, spec, options)
 	if err != nil {
 		return err
 	}
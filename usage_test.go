@@ -106,6 +106,16 @@ func TestUsageTable(t *testing.T) {
 	compareUsage(testUsageTableResult, buf.String(), t)
 }
 
+func TestUsageTo(t *testing.T) {
+	var s Specification
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	if err := UsageTo("env_config", &s, buf); err != nil {
+		t.Error(err.Error())
+	}
+	compareUsage(testUsageTableResult, buf.String(), t)
+}
+
 func TestUsageList(t *testing.T) {
 	var s Specification
 	os.Clearenv()
@@ -153,3 +163,50 @@ func TestUsageBadFormat(t *testing.T) {
 	}
 	compareUsage(testUsageBadFormatResult, buf.String(), t)
 }
+
+func TestUsageComputedDefault(t *testing.T) {
+	var s struct {
+		Size int    `default:"2*3"`
+		Port int    `default:"8080"`
+		Addr string `default:"${APP_HOST}:${APP_PORT}"`
+		Host string `default:"localhost"`
+	}
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	err := UsagefWithOptions("app", &s, buf, "{{range .}}{{usage_key .}}={{usage_default .}}\n{{end}}", Options{ExpandDefaults: true})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := "APP_SIZE=2*3 (6)\nAPP_PORT=8080\nAPP_ADDR=${APP_HOST}:${APP_PORT} (localhost:8080)\nAPP_HOST=localhost\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	// values that are set are not shown
+	os.Setenv("APP_HOST", "hunter2")
+	buf.Reset()
+	err = UsagefWithOptions("app", &s, buf, "{{range .}}{{usage_key .}}={{usage_default .}}\n{{end}}", Options{ExpandDefaults: true})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestUsageTypeDelimiters(t *testing.T) {
+	var s struct {
+		Hosts  []string          `delimiter:";"`
+		Labels map[string]string `delimiter:" " map_delimiter:"="`
+		Tags   []string
+	}
+	os.Clearenv()
+	buf := new(bytes.Buffer)
+	if err := Usagef("app", &s, buf, "{{range .}}{{usage_type .}}\n{{end}}"); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := "Semicolon-separated list of String\nSpace-separated list of String=String pairs\nComma-separated list of String\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
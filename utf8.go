@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// UTF8Policy controls the handling of values that are not valid UTF-8.
+// []byte fields are exempt, as they may legitimately hold binary data.
+type UTF8Policy int
+
+const (
+	// UTF8Allow passes values through unchanged.
+	UTF8Allow UTF8Policy = iota
+	// UTF8Reject fails processing with an InvalidUTF8Error.
+	UTF8Reject
+	// UTF8Replace replaces each run of invalid bytes with U+FFFD.
+	UTF8Replace
+)
+
+// An InvalidUTF8Error occurs when a value is not valid UTF-8 and
+// Options.UTF8 is UTF8Reject.
+type InvalidUTF8Error struct {
+	KeyName   string
+	FieldName string
+	Offset    int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("envconfig.Process: value of %s for %s is not valid UTF-8 at byte %d", e.KeyName, e.FieldName, e.Offset)
+}
+
+// cleanText applies options.UTF8 and then options.Normalize to value.
+func (info varInfo) cleanText(value string, options Options) (string, error) {
+	if isBytesField(info.Field) {
+		return value, nil
+	}
+	if !utf8.ValidString(value) {
+		switch options.UTF8 {
+		case UTF8Reject:
+			return "", &InvalidUTF8Error{KeyName: info.Key, FieldName: info.Name, Offset: invalidOffset(value)}
+		case UTF8Replace:
+			value = strings.ToValidUTF8(value, string(utf8.RuneError))
+		}
+	}
+	if options.Normalize != nil {
+		value = options.Normalize(value)
+	}
+	return value, nil
+}
+
+// isBytesField reports whether field is a (pointer to a) []byte.
+func isBytesField(field reflect.Value) bool {
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+// invalidOffset returns the offset of the first invalid byte in s.
+func invalidOffset(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
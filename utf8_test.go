@@ -0,0 +1,81 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+type utf8Specification struct {
+	Name  string
+	Names []string
+	Raw   []byte
+}
+
+func TestUTF8Allow(t *testing.T) {
+	var s utf8Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "caf\xe9")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Name != "caf\xe9" {
+		t.Errorf("expected %q, got %q", "caf\xe9", s.Name)
+	}
+}
+
+func TestUTF8Reject(t *testing.T) {
+	var s utf8Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAMES", "ok,caf\xe9")
+	err := ProcessWithOptions("env_config", &s, Options{UTF8: UTF8Reject})
+	uerr, ok := err.(*InvalidUTF8Error)
+	if !ok {
+		t.Fatalf("expected InvalidUTF8Error, got %T %v", err, err)
+	}
+	if uerr.KeyName != "ENV_CONFIG_NAMES" || uerr.Offset != 6 {
+		t.Errorf("unexpected error %+v", uerr)
+	}
+
+	// []byte fields may hold binary data.
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_RAW", "\xff\x00")
+	if err := ProcessWithOptions("env_config", &s, Options{UTF8: UTF8Reject}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUTF8Replace(t *testing.T) {
+	var s utf8Specification
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "caf\xe9\xe9!")
+	if err := ProcessWithOptions("env_config", &s, Options{UTF8: UTF8Replace}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Name != "caf\ufffd!" {
+		t.Errorf("expected %q, got %q", "caf\ufffd!", s.Name)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var s utf8Specification
+	os.Clearenv()
+	// "e" followed by a combining acute accent, composed by the stand-in
+	// normalizer below.
+	os.Setenv("ENV_CONFIG_NAME", "cafe\u0301")
+	os.Setenv("ENV_CONFIG_RAW", "e\u0301")
+	compose := func(v string) string { return strings.Replace(v, "e\u0301", "\u00e9", -1) }
+	if err := ProcessWithOptions("env_config", &s, Options{Normalize: compose}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.Name != "caf\u00e9" {
+		t.Errorf("expected %q, got %q", "caf\u00e9", s.Name)
+	}
+	if string(s.Raw) != "e\u0301" {
+		t.Errorf("expected []byte field to be left alone, got %q", s.Raw)
+	}
+}
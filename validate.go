@@ -0,0 +1,112 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Validator is implemented by specifications, and structs nested in them,
+// that check their fields once processing with Options.Validate is done: for
+// example that two fields are set together. Nested structs are validated before the structs
+// that hold them.
+type Validator interface {
+	Validate() error
+}
+
+// A ValidationError occurs when the Validate method of a specification or a
+// nested struct fails.
+type ValidationError struct {
+	// FieldName is the dotted path of the nested struct, or empty for the
+	// specification itself.
+	FieldName string
+	Err       error
+}
+
+func (e *ValidationError) Error() string {
+	if e.FieldName == "" {
+		return fmt.Sprintf("envconfig.Process: %v", e.Err)
+	}
+	return fmt.Sprintf("envconfig.Process: validating %s: %v", e.FieldName, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// validateSpec calls Validate on spec and the structs nested in it that
+// implement Validator. Fields are ignored as gatherInfo ignores them, with tags
+// read under names.
+func validateSpec(spec interface{}, names TagNames) error {
+	return validateStruct(reflect.ValueOf(spec).Elem(), "", names)
+}
+
+func validateStruct(s reflect.Value, path string, names TagNames) error {
+	typ := s.Type()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		ftype := typ.Field(i)
+		if !f.CanSet() || isTrue(names.rewrite(ftype.Tag).Get("ignored")) {
+			continue
+		}
+		for f.Kind() == reflect.Ptr && !f.IsNil() {
+			f = f.Elem()
+		}
+		if f.Kind() == reflect.Struct && !isDecodable(f) {
+			if err := validateStruct(f, path+ftype.Name+".", names); err != nil {
+				return err
+			}
+		}
+		if f.Kind() == reflect.Map && isStructMap(f.Type()) {
+			keys := f.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, k := range keys {
+				// map values are not addressable, so validate a copy
+				elem := f.MapIndex(k)
+				if elem.Kind() == reflect.Ptr {
+					if elem.IsNil() {
+						continue
+					}
+					elem = elem.Elem()
+				}
+				c := reflect.New(elem.Type()).Elem()
+				c.Set(elem)
+				if err := validateStruct(c, fmt.Sprintf("%s%s[%s].", path, ftype.Name, k.String()), names); err != nil {
+					return err
+				}
+			}
+		}
+		if f.Kind() == reflect.Slice && isStructSlice(f.Type()) {
+			for j := 0; j < f.Len(); j++ {
+				elem := f.Index(j)
+				if elem.Kind() == reflect.Ptr {
+					if elem.IsNil() {
+						continue
+					}
+					elem = elem.Elem()
+				}
+				if err := validateStruct(elem, fmt.Sprintf("%s%s[%d].", path, ftype.Name, j), names); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !s.CanAddr() || !s.Addr().Type().Implements(validatorType) {
+		return nil
+	}
+	if err := s.Addr().Interface().(Validator).Validate(); err != nil {
+		name := path
+		if name != "" {
+			name = name[:len(name)-1]
+		}
+		return &ValidationError{FieldName: name, Err: err}
+	}
+	return nil
+}
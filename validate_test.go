@@ -0,0 +1,81 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+type validatedTLS struct {
+	Cert string
+	Key  string
+}
+
+func (v validatedTLS) Validate() error {
+	if (v.Cert == "") != (v.Key == "") {
+		return errors.New("cert and key must be set together")
+	}
+	return nil
+}
+
+type validatedSpec struct {
+	TLS   validatedTLS
+	Debug bool
+	Level string
+}
+
+func (v *validatedSpec) Validate() error {
+	if v.Debug && v.Level != "" {
+		return errors.New("debug and level are exclusive")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	var s validatedSpec
+	options := Options{Validate: true}
+	os.Clearenv()
+	os.Setenv("APP_TLS_CERT", "cert.pem")
+	os.Setenv("APP_TLS_KEY", "key.pem")
+	if err := ProcessWithOptions("app", &s, options); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	os.Unsetenv("APP_TLS_KEY")
+	s = validatedSpec{}
+	err := ProcessWithOptions("app", &s, options)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.FieldName != "TLS" {
+		t.Fatalf("expected ValidationError for TLS, got %v", err)
+	}
+	if err.Error() != "envconfig.Process: validating TLS: cert and key must be set together" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	os.Setenv("APP_TLS_KEY", "key.pem")
+	os.Setenv("APP_DEBUG", "true")
+	os.Setenv("APP_LEVEL", "info")
+	if err := ProcessWithOptions("app", &s, options); !errors.As(err, &verr) || verr.FieldName != "" {
+		t.Errorf("expected ValidationError for the spec, got %v", err)
+	}
+
+	if err := Process("app", &s); err != nil {
+		t.Errorf("expected no validation without Options.Validate, got %v", err)
+	}
+}
+
+func TestValidateTagNames(t *testing.T) {
+	var s struct {
+		TLS   validatedTLS `skip:"true"`
+		Debug bool
+	}
+	s.TLS.Cert = "cert.pem"
+	options := Options{Validate: true, Lookuper: MapLookuper{}, TagNames: TagNames{"ignored": "skip"}}
+	if err := ProcessWithOptions("app", &s, options); err != nil {
+		t.Errorf("expected the renamed ignored tag to skip validation, got %v", err)
+	}
+}
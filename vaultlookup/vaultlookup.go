@@ -0,0 +1,382 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+// Package vaultlookup implements an envconfig.Lookuper backed by a HashiCorp
+// Vault KV version 2 secrets engine, so secrets reach a specification
+// without passing through environment variables.
+//
+// Variables are read as fields of the secret at Path, named by their key
+// without the prefix, unless their field is tagged with the secret and field
+// to read instead:
+//
+//	type Specification struct {
+//		DBPassword string `split_words:"true" vault:"secret/myapp/db#password"`
+//		APIToken   string `split_words:"true"` // field API_TOKEN of secret/myapp
+//	}
+//
+//	l := &vaultlookup.Lookuper{Prefix: "myapp", Path: "myapp"}
+//	if err := l.UseTags("myapp", &s, envconfig.Options{}); err != nil {
+//		log.Fatal(err)
+//	}
+//	go l.KeepRenewed(ctx, func(err error) { log.Print(err) })
+//	err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{
+//		Lookuper: envconfig.MultiLookuper(envconfig.OSLookuper(), l),
+//	})
+package vaultlookup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// DefaultTTL is how long secrets are cached when Lookuper.TTL is zero.
+const DefaultTTL = 5 * time.Minute
+
+// Lookuper answers variables from secrets of KV version 2 mounts. Each
+// secret is read once and cached for TTL. It implements
+// envconfig.ErrorLookuper, so processing fails when a secret cannot be read
+// rather than fall back to the default of the variable; Err returns the last
+// failure. A secret that does not exist leaves its variables unset.
+type Lookuper struct {
+	// Addr is the address of the Vault server; VAULT_ADDR is used when
+	// empty.
+	Addr string
+	// Token authenticates requests; VAULT_TOKEN is used when empty.
+	Token string
+	// Namespace, when set, is sent as the Vault Enterprise namespace;
+	// VAULT_NAMESPACE is used when empty.
+	Namespace string
+
+	// Mount is the path of the KV version 2 engine; "secret" is used when
+	// empty.
+	Mount string
+	// Prefix is the prefix of the specification, stripped from keys to
+	// name fields: MYAPP_API_TOKEN is field API_TOKEN.
+	Prefix string
+	// Path, when set, is the secret under Mount holding the variables
+	// without an entry in Fields.
+	Path string
+	// Fields maps keys to the secret and field to read them from, as
+	// "path#field" relative to Mount, or "mount/path#field" for Mount or
+	// one of Mounts. Without #field, the field is named by the key without
+	// the prefix. UseTags fills it from `vault` tags.
+	Fields map[string]string
+	// Mounts lists the other KV version 2 mounts that entries of Fields
+	// may name.
+	Mounts []string
+
+	// TTL is how long secrets are cached; DefaultTTL is used when zero.
+	TTL time.Duration
+	// Client is used for requests; http.DefaultClient is used when nil.
+	Client *http.Client
+
+	mu      sync.Mutex
+	secrets map[string]cachedSecret
+	err     error
+}
+
+type cachedSecret struct {
+	data    map[string]interface{}
+	found   bool
+	fetched time.Time
+}
+
+// UseTags adds the `vault` tags of the specification to Fields. options
+// should be those the specification is processed with, since they can
+// change its keys.
+func (l *Lookuper) UseTags(prefix string, spec interface{}, options envconfig.Options) error {
+	tags, err := envconfig.KeyTags(prefix, spec, "vault", options)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Fields == nil {
+		l.Fields = make(map[string]string, len(tags))
+	}
+	for key, ref := range tags {
+		l.Fields[key] = ref
+	}
+	return nil
+}
+
+// Lookup implements envconfig.Lookuper.
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	return l.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements envconfig.ContextLookuper. A secret that cannot
+// be read is reported as unset.
+func (l *Lookuper) LookupContext(ctx context.Context, key string) (string, bool) {
+	value, ok, _ := l.LookupErr(ctx, key)
+	return value, ok
+}
+
+// LookupErr implements envconfig.ErrorLookuper.
+func (l *Lookuper) LookupErr(ctx context.Context, key string) (string, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	mount, path, field := l.mount(), l.Path, l.field(key)
+	if ref, ok := l.Fields[key]; ok {
+		mount, path, field = l.ref(ref, field)
+	}
+	if path == "" {
+		return "", false, nil
+	}
+
+	data, ok, err := l.secret(ctx, mount, path)
+	if !ok || err != nil {
+		return "", false, err
+	}
+	v, ok := data[field]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	if s, isString := v.(string); isString {
+		return s, true, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), true, nil
+}
+
+// ref splits an entry of Fields into its mount, path and field, which is
+// field when the entry does not name one.
+func (l *Lookuper) ref(ref, field string) (string, string, string) {
+	path := ref
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		path, field = ref[:i], ref[i+1:]
+	}
+	path = strings.Trim(path, "/")
+	for _, mount := range append([]string{l.Mount}, l.Mounts...) {
+		if mount = strings.Trim(mount, "/"); mount == "" {
+			mount = "secret"
+		}
+		if rest := strings.TrimPrefix(path, mount+"/"); rest != path {
+			return mount, rest, field
+		}
+	}
+	return l.mount(), path, field
+}
+
+// Err returns the error of the last read that failed, or nil when the last
+// read of each secret succeeded.
+func (l *Lookuper) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Invalidate drops the cached secrets, so the next lookup reads them again.
+func (l *Lookuper) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.secrets = nil
+}
+
+// String names the Lookuper in envconfig.Options.OnLookup reports.
+func (l *Lookuper) String() string {
+	return "vault:" + l.mount()
+}
+
+func (l *Lookuper) mount() string {
+	if l.Mount == "" {
+		return "secret"
+	}
+	return strings.Trim(l.Mount, "/")
+}
+
+func (l *Lookuper) field(key string) string {
+	if l.Prefix == "" {
+		return key
+	}
+	p := strings.ToUpper(l.Prefix) + "_"
+	if len(key) > len(p) && strings.EqualFold(key[:len(p)], p) {
+		return key[len(p):]
+	}
+	return key
+}
+
+// secret returns the data of the latest version of the secret at path
+// under mount, reading it unless it is cached. A secret that does not exist
+// has no data. l.mu must be held.
+func (l *Lookuper) secret(ctx context.Context, mount, path string) (map[string]interface{}, bool, error) {
+	ttl := l.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	id := mount + "/" + path
+	if c, ok := l.secrets[id]; ok && time.Since(c.fetched) < ttl {
+		return c.data, c.found, nil
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	status, err := l.do(ctx, http.MethodGet, "/v1/"+escapePath(mount)+"/data/"+escapePath(path), nil, &resp)
+	if err != nil {
+		l.err = fmt.Errorf("vaultlookup: reading %s: %v", id, err)
+		return nil, false, l.err
+	}
+	c := cachedSecret{data: resp.Data.Data, found: status != http.StatusNotFound, fetched: time.Now()}
+	if l.secrets == nil {
+		l.secrets = make(map[string]cachedSecret)
+	}
+	l.secrets[id], l.err = c, nil
+	return c.data, c.found, nil
+}
+
+// Renew renews the token and returns its new time to live.
+func (l *Lookuper) Renew(ctx context.Context) (time.Duration, error) {
+	var resp struct {
+		Auth struct {
+			LeaseDuration int  `json:"lease_duration"`
+			Renewable     bool `json:"renewable"`
+		} `json:"auth"`
+	}
+	if _, err := l.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", struct{}{}, &resp); err != nil {
+		return 0, fmt.Errorf("vaultlookup: renewing token: %v", err)
+	}
+	return time.Duration(resp.Auth.LeaseDuration) * time.Second, nil
+}
+
+// KeepRenewed renews the token when two thirds of its time to live have
+// passed, until ctx is canceled. It returns nil at once for a token that is
+// not renewable, and an error when the token cannot be looked up. Failed
+// renewals are passed to onError, when it is not nil, and retried after a
+// tenth of the remaining time.
+func (l *Lookuper) KeepRenewed(ctx context.Context, onError func(error)) error {
+	var self struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if _, err := l.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil, &self); err != nil {
+		err = fmt.Errorf("vaultlookup: looking up token: %v", err)
+		if onError != nil {
+			onError(err)
+		}
+		return err
+	}
+	if !self.Data.Renewable || self.Data.TTL <= 0 {
+		return nil
+	}
+
+	ttl := time.Duration(self.Data.TTL) * time.Second
+	wait := ttl * 2 / 3
+	for {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		renewed, err := l.Renew(ctx)
+		switch {
+		case err != nil:
+			if onError != nil {
+				onError(err)
+			}
+			if ttl -= wait; ttl < 0 {
+				ttl = 0
+			}
+			wait = ttl / 10
+			if wait < time.Second {
+				wait = time.Second
+			}
+		case renewed <= 0:
+			return nil
+		default:
+			ttl, wait = renewed, renewed*2/3
+		}
+	}
+}
+
+// do sends a request to the Vault API and decodes the JSON response into
+// out. A 404 Not Found is not an error, and its status is returned.
+func (l *Lookuper) do(ctx context.Context, method, path string, in, out interface{}) (int, error) {
+	addr := l.Addr
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		return 0, errors.New("no Vault address")
+	}
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(addr, "/")+path, body)
+	if err != nil {
+		return 0, err
+	}
+	token := l.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	req.Header.Set("X-Vault-Token", token)
+	namespace := l.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &e) == nil && len(e.Errors) > 0 {
+			return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, strings.Join(e.Errors, "; "))
+		}
+		return resp.StatusCode, fmt.Errorf("%s", resp.Status)
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// escapePath escapes the segments of a secret path for a URL.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
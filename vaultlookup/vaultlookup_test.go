@@ -0,0 +1,127 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package vaultlookup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func fakeVault(t *testing.T, reads, renewals *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			atomic.AddInt32(reads, 1)
+			w.Write([]byte(`{"data":{"data":{"API_TOKEN":"tok","PORT":8080},"metadata":{"version":3}}}`))
+		case "/v1/kv/data/myapp/db":
+			atomic.AddInt32(reads, 1)
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2"}}}`))
+		case "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":1,"renewable":true}}`))
+		case "/v1/auth/token/renew-self":
+			if r.Method != http.MethodPost {
+				t.Errorf("expected POST, got %s", r.Method)
+			}
+			atomic.AddInt32(renewals, 1)
+			w.Write([]byte(`{"auth":{"lease_duration":0,"renewable":false}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+func TestLookuper(t *testing.T) {
+	var reads, renewals int32
+	srv := fakeVault(t, &reads, &renewals)
+	defer srv.Close()
+
+	var s struct {
+		APIToken   string `split_words:"true"`
+		Port       int
+		DBPassword string `split_words:"true" vault:"kv/myapp/db#password"`
+		Missing    string `vault:"secret/nope#x"`
+	}
+	l := &Lookuper{Addr: srv.URL, Token: "s.token", Prefix: "myapp", Path: "myapp"}
+	if err := l.UseTags("myapp", &s, envconfig.Options{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(l.Fields) != 2 || l.Fields["MYAPP_DB_PASSWORD"] != "kv/myapp/db#password" {
+		t.Errorf("unexpected fields %v", l.Fields)
+	}
+
+	if v, ok := l.Lookup("MYAPP_API_TOKEN"); !ok || v != "tok" {
+		t.Errorf("expected tok, got %q", v)
+	}
+	if v, ok := l.Lookup("MYAPP_PORT"); !ok || v != "8080" {
+		t.Errorf("expected 8080, got %q", v)
+	}
+	if _, ok := l.Lookup("MYAPP_MISSING"); ok || l.Err() != nil {
+		t.Errorf("expected a missing secret to be unset without error, got %v", l.Err())
+	}
+	if n := atomic.LoadInt32(&reads); n != 1 {
+		t.Errorf("expected the secret to be cached, got %d reads", n)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	var reads, renewals int32
+	srv := fakeVault(t, &reads, &renewals)
+	defer srv.Close()
+
+	var s struct {
+		APIToken   string `split_words:"true"`
+		DBPassword string `split_words:"true" vault:"kv/myapp/db#password"`
+	}
+	l := &Lookuper{Addr: srv.URL, Token: "s.token", Mount: "kv", Mounts: []string{"secret"}, Prefix: "myapp"}
+	if err := l.UseTags("myapp", &s, envconfig.Options{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	l.Fields["MYAPP_API_TOKEN"] = "/secret/myapp#API_TOKEN"
+	if err := envconfig.ProcessWithOptions("myapp", &s, envconfig.Options{Lookuper: l}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s.DBPassword != "hunter2" || s.APIToken != "tok" {
+		t.Errorf("expected hunter2 and tok from their mounts, got %q and %q", s.DBPassword, s.APIToken)
+	}
+
+	l.Token = "wrong"
+	l.Invalidate()
+	if _, ok := l.Lookup("MYAPP_DB_PASSWORD"); ok || l.Err() == nil || !strings.Contains(l.Err().Error(), "permission denied") {
+		t.Errorf("expected the Vault error, got %v", l.Err())
+	}
+	var withDefault struct {
+		DBPassword string `split_words:"true" default:"dev"`
+	}
+	err := envconfig.ProcessWithOptions("myapp", &withDefault, envconfig.Options{Lookuper: l})
+	if err == nil || !strings.Contains(err.Error(), "permission denied") || withDefault.DBPassword != "" {
+		t.Errorf("expected the failed read to fail processing, got %v and %q", err, withDefault.DBPassword)
+	}
+}
+
+func TestKeepRenewed(t *testing.T) {
+	var reads, renewals int32
+	srv := fakeVault(t, &reads, &renewals)
+	defer srv.Close()
+
+	l := &Lookuper{Addr: srv.URL, Token: "s.token"}
+	if err := l.KeepRenewed(context.Background(), func(err error) { t.Error(err) }); err != nil {
+		t.Fatal(err.Error())
+	}
+	if n := atomic.LoadInt32(&renewals); n != 1 {
+		t.Errorf("expected one renewal, got %d", n)
+	}
+}
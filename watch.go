@@ -0,0 +1,126 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"time"
+)
+
+// A Watcher processes a specification again every Interval, and whenever
+// the process receives SIGHUP (on platforms that have it), and calls
+// OnChange when the result differs from the last one. It is useful with a
+// Lookuper whose values change, such as one that reads a mounted ConfigMap,
+// to pick up log levels and feature flags without a restart.
+type Watcher struct {
+	Prefix   string
+	Options  Options
+	Interval time.Duration
+
+	// OnChange is called with pointers to the previous and the new value of
+	// the specification, which ChangedFields compares. The new value is not
+	// shared with the Watcher, so it can be published, for example with an
+	// atomic.Value, as it is.
+	OnChange func(old, new interface{})
+
+	// OnError, when it is not nil, is called when processing fails. The
+	// previous value is kept.
+	OnError func(error)
+}
+
+// Watch runs a Watcher with the zero Options until ctx is canceled. spec is
+// the current, already processed value; it is copied and not modified.
+func Watch(ctx context.Context, prefix string, spec interface{}, interval time.Duration, onChange func(old, new interface{})) error {
+	w := &Watcher{Prefix: prefix, Interval: interval, OnChange: onChange}
+	return w.Run(ctx, spec)
+}
+
+// Run watches for changes to spec, the current, already processed value,
+// until ctx is canceled, and returns the context's error. spec is copied and
+// not modified.
+func (w *Watcher) Run(ctx context.Context, spec interface{}) error {
+	v := reflect.ValueOf(spec)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidSpecification
+	}
+	current := deepCopy(v)
+
+	hup := make(chan os.Signal, 1)
+	notifyHUP(hup)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.Interval > 0 {
+		t := time.NewTicker(w.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		case <-hup:
+		}
+		next := reflect.New(v.Elem().Type())
+		if err := ProcessWithOptions(w.Prefix, next.Interface(), w.Options); err != nil {
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+			continue
+		}
+		if reflect.DeepEqual(current.Interface(), next.Interface()) {
+			continue
+		}
+		old := current
+		current = next
+		if w.OnChange != nil {
+			w.OnChange(old.Interface(), deepCopy(next).Interface())
+		}
+	}
+}
+
+// ChangedFields returns the paths of the fields, e.g. "DB.Port" or
+// "Endpoints[1].Host", that differ between old and new, two pointers to
+// values of the same specification type. Fields of slices and maps of
+// structs that exist in only one of them are listed too. It returns nil for
+// values of different types.
+func ChangedFields(old, new interface{}) []string {
+	a, b := reflect.ValueOf(old), reflect.ValueOf(new)
+	if a.Kind() != reflect.Ptr || a.Type() != b.Type() || a.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	options := Options{Lookuper: MapLookuper{}, fromValues: true}
+	oldInfos, err := gatherInfo("", deepCopy(a).Interface(), options)
+	if err != nil {
+		return nil
+	}
+	newInfos, err := gatherInfo("", deepCopy(b).Interface(), options)
+	if err != nil {
+		return nil
+	}
+
+	byPath := make(map[string]varInfo, len(newInfos))
+	for _, info := range newInfos {
+		byPath[info.Path] = info
+	}
+	var changed []string
+	for _, info := range oldInfos {
+		n, ok := byPath[info.Path]
+		delete(byPath, info.Path)
+		if !ok || !sameValue(info.Field, n.Field) {
+			changed = append(changed, info.Path)
+		}
+	}
+	for _, info := range newInfos {
+		if _, ok := byPath[info.Path]; ok {
+			changed = append(changed, info.Path)
+		}
+	}
+	return changed
+}
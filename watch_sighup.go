@@ -0,0 +1,19 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+//go:build !js
+// +build !js
+
+package envconfig
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyHUP relays SIGHUP to c.
+func notifyHUP(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGHUP)
+}
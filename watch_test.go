@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Kelsey Hightower and others. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in
+// the LICENSE file.
+
+package envconfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type watchSpec struct {
+	LogLevel string `split_words:"true" default:"info"`
+	Port     int    `required:"true"`
+	Backends []struct {
+		Host string
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	var mu sync.Mutex
+	env := map[string]string{"MYAPP_PORT": "80"}
+	set := func(key, value string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if value == "" {
+			delete(env, key)
+		} else {
+			env[key] = value
+		}
+	}
+	l := LookuperFunc(func(key string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		value, ok := env[key]
+		return value, ok
+	})
+
+	var s watchSpec
+	if err := ProcessWithLookuper("myapp", &s, l); err != nil {
+		t.Fatal(err.Error())
+	}
+	changes := make(chan [2]*watchSpec)
+	errs := make(chan error, 1)
+	w := &Watcher{
+		Prefix:   "myapp",
+		Options:  Options{Lookuper: l},
+		Interval: 5 * time.Millisecond,
+		OnChange: func(old, new interface{}) { changes <- [2]*watchSpec{old.(*watchSpec), new.(*watchSpec)} },
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx, &s) }()
+
+	set("MYAPP_LOG_LEVEL", "debug")
+	c := <-changes
+	if c[0].LogLevel != "info" || c[1].LogLevel != "debug" || c[1].Port != 80 {
+		t.Errorf("unexpected change from %+v to %+v", c[0], c[1])
+	}
+	if got := ChangedFields(c[0], c[1]); !reflect.DeepEqual(got, []string{"LogLevel"}) {
+		t.Errorf("expected LogLevel to change, got %v", got)
+	}
+
+	// a failed reload keeps the previous value
+	set("MYAPP_PORT", "")
+	if err := <-errs; err == nil {
+		t.Error("expected an error for the missing port")
+	}
+	set("MYAPP_PORT", "80")
+	set("MYAPP_BACKENDS_0_HOST", "one")
+	c = <-changes
+	if c[0].LogLevel != "debug" || len(c[1].Backends) != 1 {
+		t.Errorf("unexpected change from %+v to %+v", c[0], c[1])
+	}
+	if got := ChangedFields(c[0], c[1]); !reflect.DeepEqual(got, []string{"Backends[0].Host"}) {
+		t.Errorf("expected the new backend, got %v", got)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if s.LogLevel != "info" {
+		t.Error("expected the spec not to be modified")
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	if got := ChangedFields(&watchSpec{}, &struct{ Port int }{}); got != nil {
+		t.Errorf("expected nil for different types, got %v", got)
+	}
+	a := &watchSpec{Port: 80}
+	b := &watchSpec{Port: 80}
+	if got := ChangedFields(a, b); len(got) != 0 {
+		t.Errorf("expected no changes, got %v", got)
+	}
+}